@@ -10,6 +10,7 @@ require (
 	github.com/fatih/color v1.16.0
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/rs/zerolog v1.33.0
+	github.com/tetratelabs/wazero v1.8.2
 	golang.org/x/net v0.21.0 // indirect
 	golang.org/x/sys v0.22.0 // indirect
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
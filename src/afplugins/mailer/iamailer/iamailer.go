@@ -0,0 +1,279 @@
+// iamailer package defines the interface of the AgniOne mailer plugins
+//
+// Mailer plugin exports the symbol set as ifname in core.config (eg: IAMailMessage) that implements IAMailer.
+// Units get the mailer instance via Get_Mailer of the framework instance.
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   iamailer
+
+	Objective     :   Define the mailer plugin interface, mail message, attachments and templated bodies
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package iamailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	htemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	ttemplate "text/template"
+	"time"
+)
+
+// IAMailer is the interface of the mailer plugins
+type IAMailer interface {
+	New() interface{}                 /// creates a new mailer instance
+	Send(pMessage *MailMessage) error /// sends the given message. Templates are rendered by the framework
+	Close() error                     /// releases the connections of the mailer
+}
+
+// Attachment holds a file attached to the mail message
+type Attachment struct {
+	Name         string `json:"name"`
+	Content_Type string `json:"content_type"` /// detected from the name if empty
+	Inline       bool   `json:"inline"`       /// inline attachments can be referred in HTML body as cid:<Name>
+	Data         []byte `json:"data"`
+}
+
+// MailMessage holds the mail message.
+//
+// Text_Template & HTML_Template are rendered with Template_Data into Text_Body & HTML_Body by Render.
+type MailMessage struct {
+	From        string            `json:"from"`
+	To          []string          `json:"to"`
+	Cc          []string          `json:"cc"`
+	Bcc         []string          `json:"bcc"`
+	Reply_To    string            `json:"reply_to"`
+	Subject     string            `json:"subject"`
+	Headers     map[string]string `json:"headers"`
+	Text_Body   string            `json:"text_body"`
+	HTML_Body   string            `json:"html_body"`
+	Attachments []Attachment      `json:"attachments"`
+
+	Text_Template string `json:"text_template"` /// text/template body
+	HTML_Template string `json:"html_template"` /// html/template body. values are HTML escaped
+	Template_Data any    `json:"template_data"`
+}
+
+// Attach adds the given data as an attachment to the message
+func (mm *MailMessage) Attach(pName string, pData []byte) {
+	mm.Attachments = append(mm.Attachments, Attachment{Name: pName, Data: pData})
+}
+
+// Recipients returns all the recipients of the message (To, Cc & Bcc)
+func (mm *MailMessage) Recipients() []string {
+	_recipients := make([]string, 0, len(mm.To)+len(mm.Cc)+len(mm.Bcc))
+	_recipients = append(_recipients, mm.To...)
+	_recipients = append(_recipients, mm.Cc...)
+	return append(_recipients, mm.Bcc...)
+}
+
+// Validate checks the sender, the recipients and the addresses of the message
+func (mm *MailMessage) Validate() error {
+
+	if _, _err := mail.ParseAddress(mm.From); _err != nil {
+		return errors.New("invalid sender address " + mm.From)
+	}
+
+	_recipients := mm.Recipients()
+	if len(_recipients) == 0 {
+		return errors.New("no recipient is given")
+	}
+
+	for _, _address := range _recipients {
+		if _, _err := mail.ParseAddress(_address); _err != nil {
+			return errors.New("invalid recipient address " + _address)
+		}
+	}
+	return nil
+}
+
+// Render renders the text & HTML templates of the message into the bodies
+func (mm *MailMessage) Render() error {
+
+	if len(mm.Text_Template) > 0 {
+		_template, _err := ttemplate.New("text").Parse(mm.Text_Template)
+		if _err != nil {
+			return errors.New("invalid text template. " + _err.Error())
+		}
+		_body := &bytes.Buffer{}
+		if _err = _template.Execute(_body, mm.Template_Data); _err != nil {
+			return errors.New("failed to render text template. " + _err.Error())
+		}
+		mm.Text_Body = _body.String()
+	}
+
+	if len(mm.HTML_Template) > 0 {
+		_template, _err := htemplate.New("html").Parse(mm.HTML_Template)
+		if _err != nil {
+			return errors.New("invalid html template. " + _err.Error())
+		}
+		_body := &bytes.Buffer{}
+		if _err = _template.Execute(_body, mm.Template_Data); _err != nil {
+			return errors.New("failed to render html template. " + _err.Error())
+		}
+		mm.HTML_Body = _body.String()
+	}
+
+	return nil
+}
+
+// EML returns the message in RFC 5322 format with MIME parts. Can be used by the plugins to send via SMTP.
+// Bcc recipients are not included in the headers.
+func (mm *MailMessage) EML() ([]byte, error) {
+
+	_buffer := &bytes.Buffer{}
+
+	_header := func(pName string, pValue string) {
+		if len(pValue) > 0 {
+			_buffer.WriteString(pName + ": " + pValue + "\r\n")
+		}
+	}
+
+	_header("From", mm.From)
+	_header("To", strings.Join(mm.To, ", "))
+	_header("Cc", strings.Join(mm.Cc, ", "))
+	_header("Reply-To", mm.Reply_To)
+	_header("Subject", mime.QEncoding.Encode("utf-8", mm.Subject))
+	_header("Date", time.Now().Format(time.RFC1123Z))
+	_header("Message-ID", "<"+message_id()+"@agnione>")
+	_header("MIME-Version", "1.0")
+	for _name, _value := range mm.Headers {
+		_header(textproto.CanonicalMIMEHeaderKey(_name), _value)
+	}
+
+	_mixed := multipart.NewWriter(_buffer)
+	_header("Content-Type", "multipart/mixed; boundary="+_mixed.Boundary())
+	_buffer.WriteString("\r\n")
+
+	/// bodies
+	_alternative := &bytes.Buffer{}
+	_alt_writer := multipart.NewWriter(_alternative)
+
+	if _err := write_body(_alt_writer, "text/plain", mm.Text_Body, len(mm.Text_Body) > 0 || len(mm.HTML_Body) == 0); _err != nil {
+		return nil, _err
+	}
+	if _err := write_body(_alt_writer, "text/html", mm.HTML_Body, len(mm.HTML_Body) > 0); _err != nil {
+		return nil, _err
+	}
+	_alt_writer.Close()
+
+	_part, _err := _mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + _alt_writer.Boundary()}})
+	if _err != nil {
+		return nil, _err
+	}
+	_part.Write(_alternative.Bytes())
+
+	/// attachments
+	for _, _attachment := range mm.Attachments {
+
+		_content_type := _attachment.Content_Type
+		if len(_content_type) == 0 {
+			_content_type = mime.TypeByExtension(extension(_attachment.Name))
+		}
+		_media, _params, _err := mime.ParseMediaType(_content_type)
+		if _err != nil {
+			_media, _params = "application/octet-stream", map[string]string{}
+		}
+		_params["name"] = _attachment.Name
+
+		_disposition := "attachment"
+		_headers := textproto.MIMEHeader{}
+		if _attachment.Inline {
+			_disposition = "inline"
+			_headers.Set("Content-ID", "<"+_attachment.Name+">")
+		}
+		_headers.Set("Content-Type", mime.FormatMediaType(_media, _params))
+		_headers.Set("Content-Disposition", mime.FormatMediaType(_disposition, map[string]string{"filename": _attachment.Name}))
+		_headers.Set("Content-Transfer-Encoding", "base64")
+
+		_part, _err := _mixed.CreatePart(_headers)
+		if _err != nil {
+			return nil, _err
+		}
+		write_base64(_part, _attachment.Data)
+	}
+
+	if _err = _mixed.Close(); _err != nil {
+		return nil, _err
+	}
+
+	return _buffer.Bytes(), nil
+}
+
+// write_body writes the given body as a base64 encoded part, if pWrite is true
+func write_body(pWriter *multipart.Writer, pContent_Type string, pBody string, pWrite bool) error {
+
+	if !pWrite {
+		return nil
+	}
+
+	_part, _err := pWriter.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {pContent_Type + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"}})
+	if _err != nil {
+		return _err
+	}
+
+	return write_base64(_part, []byte(pBody))
+}
+
+// write_base64 writes the given data in base64 with 76 characters lines
+func write_base64(pWriter interface{ Write([]byte) (int, error) }, pData []byte) error {
+
+	_encoded := base64.StdEncoding.EncodeToString(pData)
+	for len(_encoded) > 76 {
+		if _, _err := pWriter.Write([]byte(_encoded[:76] + "\r\n")); _err != nil {
+			return _err
+		}
+		_encoded = _encoded[76:]
+	}
+	_, _err := pWriter.Write([]byte(_encoded + "\r\n"))
+	return _err
+}
+
+// extension returns the file extension of the given name including the dot
+func extension(pName string) string {
+	if _index := strings.LastIndex(pName, "."); _index >= 0 {
+		return pName[_index:]
+	}
+	return ""
+}
+
+// message_id returns a random message id
+func message_id() string {
+	_id := make([]byte, 12)
+	rand.Read(_id)
+	return hex.EncodeToString(_id)
+}
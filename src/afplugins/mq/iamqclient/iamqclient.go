@@ -0,0 +1,88 @@
+// iamqclient package defines the interface of the AgniOne message queue plugins
+//
+// MQ plugin exports the symbol set as ifname in core.config (eg: IAMQClient) that implements IAMQClient.
+// Units get the MQ client instance via Get_MQClient of the framework instance.
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   iamqclient
+
+	Objective     :   Define the message queue plugin interface
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package iamqclient
+
+import (
+	"context"
+	"time"
+)
+
+// DEFAULT_PREFETCH define the number of unacknowledged messages per subscription, if prefetch is not given
+const DEFAULT_PREFETCH int = 1
+
+// Message holds a message published to or received from a topic
+type Message struct {
+	ID          string            `json:"id"`    /// set by the broker if empty
+	Topic       string            `json:"topic"` /// set by the broker
+	Key         string            `json:"key"`   /// optional partition/routing key
+	Headers     map[string]string `json:"headers"`
+	Body        []byte            `json:"body"`
+	Timestamp   time.Time         `json:"timestamp"`   /// set by the broker if zero
+	Redelivered int               `json:"redelivered"` /// number of times the message is redelivered
+}
+
+// IDelivery is a received message that should be acknowledged or negatively acknowledged
+type IDelivery interface {
+	Message() *Message
+	Ack() error               /// message is processed. removed from the queue
+	Nack(pRequeue bool) error /// message is not processed. requeued or dropped
+}
+
+// Handler is called for every message delivered to a subscription
+type Handler func(pDelivery IDelivery)
+
+// SubscribeOptions holds the subscription options
+type SubscribeOptions struct {
+	Group    string `json:"group"`    /// consumer group. a message is delivered to one subscriber of the group. empty receives all messages
+	Prefetch int    `json:"prefetch"` /// max unacknowledged messages of the subscription. DEFAULT_PREFETCH if <= 0
+	Auto_Ack bool   `json:"auto_ack"` /// message is acknowledged when the handler returns, unless acked/nacked by the handler
+}
+
+// ISubscription is a subscription to a topic
+type ISubscription interface {
+	Topic() string
+	Group() string
+	Unsubscribe() error /// stops the deliveries. unacknowledged messages are requeued
+}
+
+// IAMQClient is the interface of the message queue plugins
+type IAMQClient interface {
+	New() interface{} /// creates a new client instance
+
+	Publish(pCTX context.Context, pTopic string, pMessage *Message) error
+	Subscribe(pTopic string, pOptions SubscribeOptions, pHandler Handler) (ISubscription, error)
+
+	Close() error /// unsubscribes all the subscriptions of the client and releases the connections
+}
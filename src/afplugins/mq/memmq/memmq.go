@@ -0,0 +1,480 @@
+// memmq package provides the built-in in-memory message broker of the AgniOne application framework
+//
+// It implements iamqclient.IAMQClient, so that units can be built and tested without a real broker.
+// Messages are not persisted and live only within the framework process.
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   memmq
+
+	Objective     :   In-memory message broker with topics, consumer groups, prefetch and ack/nack
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+	Ajith de Silva		19/10/2026	Updated 	Counted the unacknowledged messages in the queue size & kept the latest messages of the groups without subscribers
+
+#########################################################################################
+*/
+package memmq
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"agnione.appfm/src/afplugins/mq/iamqclient"
+)
+
+// DEFAULT_QUEUE_SIZE define the max messages of a consumer group, waiting in the queue or unacknowledged
+const DEFAULT_QUEUE_SIZE int = 10000
+
+// Broker holds the topics and their consumer groups
+type Broker struct {
+	lock       *sync.Mutex
+	topics     map[string]map[string]*group /// consumer groups by topic
+	queue_size int
+	sequence   atomic.Uint64
+}
+
+// group is a consumer group of a topic. Each group receives a copy of every message published to the topic.
+type group struct {
+	name        string
+	private     bool /// group of a subscription without a group name. removed on unsubscribe
+	lock        *sync.Mutex
+	cond        *sync.Cond
+	queue       *list.List
+	inflight    int /// messages delivered & not acknowledged. counted in the queue size until acked or dropped
+	subscribers int
+}
+
+// NewBroker creates a new in-memory broker with the given max queue size per consumer group
+func NewBroker(pQueue_Size int) *Broker {
+	if pQueue_Size <= 0 {
+		pQueue_Size = DEFAULT_QUEUE_SIZE
+	}
+	return &Broker{lock: &sync.Mutex{}, topics: make(map[string]map[string]*group), queue_size: pQueue_Size}
+}
+
+// New creates a new client of the given broker
+func New(pBroker *Broker) *Client {
+	return &Client{broker: pBroker, lock: &sync.Mutex{}, subscriptions: make(map[*subscription]bool)}
+}
+
+// publish adds a copy of the message to every consumer group of the topic.
+// Message is dropped if the topic has no consumer group. Returns error if the queue of a group with subscribers is full
+func (b *Broker) publish(pTopic string, pMessage *iamqclient.Message) error {
+
+	b.lock.Lock()
+	_groups := make([]*group, 0, len(b.topics[pTopic]))
+	for _, _group := range b.topics[pTopic] {
+		_groups = append(_groups, _group)
+	}
+	b.lock.Unlock()
+
+	var _err error
+	for _, _group := range _groups {
+		_copy := *pMessage
+		if pMessage.Headers != nil {
+			_copy.Headers = make(map[string]string, len(pMessage.Headers))
+			for _key, _value := range pMessage.Headers {
+				_copy.Headers[_key] = _value
+			}
+		}
+		if !_group.push(&_copy, b.queue_size) {
+			_err = errors.New("queue of the consumer group " + _group.name + " on " + pTopic + " is full")
+		}
+	}
+	return _err
+}
+
+// group returns the consumer group of the topic. Group is created if not exists
+func (b *Broker) group(pTopic string, pName string, pPrivate bool) *group {
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	_groups, _found := b.topics[pTopic]
+	if !_found {
+		_groups = make(map[string]*group)
+		b.topics[pTopic] = _groups
+	}
+
+	_group, _found := _groups[pName]
+	if !_found {
+		_group = &group{name: pName, private: pPrivate, lock: &sync.Mutex{}, queue: list.New()}
+		_group.cond = sync.NewCond(_group.lock)
+		_groups[pName] = _group
+	}
+	return _group
+}
+
+// remove_group removes the consumer group of the topic
+func (b *Broker) remove_group(pTopic string, pName string) {
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.topics[pTopic], pName)
+	if len(b.topics[pTopic]) == 0 {
+		delete(b.topics, pTopic)
+	}
+}
+
+// Queued returns the number of messages waiting in the consumer groups by topic/group
+func (b *Broker) Queued() map[string]int {
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	_queued := make(map[string]int)
+	for _topic, _groups := range b.topics {
+		for _name, _group := range _groups {
+			_group.lock.Lock()
+			_queued[_topic+"/"+_name] = _group.queue.Len()
+			_group.lock.Unlock()
+		}
+	}
+	return _queued
+}
+
+// push adds the message to the end of the queue. Returns false if the queue is full and the group has subscribers.
+// A group without subscribers keeps the latest messages: the oldest waiting message is dropped to add the new one
+func (g *group) push(pMessage *iamqclient.Message, pQueue_Size int) bool {
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.queue.Len()+g.inflight >= pQueue_Size {
+		if g.subscribers > 0 || g.queue.Len() == 0 {
+			return false
+		}
+		g.queue.Remove(g.queue.Front())
+	}
+
+	g.queue.PushBack(pMessage)
+	g.cond.Signal()
+	return true
+}
+
+// requeue adds the unacknowledged message to the front of the queue. It keeps the place taken in the queue size
+// while it was delivered, so the queue does not grow over the size
+func (g *group) requeue(pMessage *iamqclient.Message) {
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.inflight--
+	g.queue.PushFront(pMessage)
+	g.cond.Signal()
+}
+
+// settle frees the place of the acknowledged or dropped message in the queue size
+func (g *group) settle() {
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.inflight--
+}
+
+// add_subscriber adds a subscriber to the group. Subscriber is removed by its Unsubscribe
+func (g *group) add_subscriber() {
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.subscribers++
+}
+
+// pop waits for a message and removes it from the queue. Returns nil if the subscription is stopped
+func (g *group) pop(pStopped *atomic.Bool) *iamqclient.Message {
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for g.queue.Len() == 0 && !pStopped.Load() {
+		g.cond.Wait()
+	}
+
+	if pStopped.Load() {
+		g.cond.Signal() /// pass the wake up to other subscribers of the group
+		return nil
+	}
+
+	g.inflight++
+	return g.queue.Remove(g.queue.Front()).(*iamqclient.Message)
+}
+
+// Client is a client of the in-memory broker. Implements iamqclient.IAMQClient
+type Client struct {
+	broker        *Broker
+	lock          *sync.Mutex
+	subscriptions map[*subscription]bool
+	closed        bool
+}
+
+// New creates a new client of the same broker
+func (c *Client) New() interface{} {
+	return New(c.broker)
+}
+
+// Publish publishes the message to the topic
+func (c *Client) Publish(pCTX context.Context, pTopic string, pMessage *iamqclient.Message) error {
+
+	if pCTX != nil {
+		if _err := pCTX.Err(); _err != nil {
+			return _err
+		}
+	}
+
+	if pMessage == nil {
+		return errors.New("message is not given")
+	}
+
+	c.lock.Lock()
+	_closed := c.closed
+	c.lock.Unlock()
+
+	if _closed {
+		return errors.New("client is closed")
+	}
+
+	if len(pMessage.ID) == 0 {
+		pMessage.ID = strconv.FormatUint(c.broker.sequence.Add(1), 10)
+	}
+	if pMessage.Timestamp.IsZero() {
+		pMessage.Timestamp = time.Now()
+	}
+	pMessage.Topic = pTopic
+
+	return c.broker.publish(pTopic, pMessage)
+}
+
+// Subscribe subscribes the handler to the topic.
+// Handler is called for the messages one by one, with up to prefetch unacknowledged messages.
+func (c *Client) Subscribe(pTopic string, pOptions iamqclient.SubscribeOptions, pHandler iamqclient.Handler) (iamqclient.ISubscription, error) {
+
+	if pHandler == nil {
+		return nil, errors.New("handler is not given")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.closed {
+		return nil, errors.New("client is closed")
+	}
+
+	_prefetch := pOptions.Prefetch
+	if _prefetch <= 0 {
+		_prefetch = iamqclient.DEFAULT_PREFETCH
+	}
+
+	_name, _private := pOptions.Group, false
+	if len(_name) == 0 {
+		_name, _private = "~"+strconv.FormatUint(c.broker.sequence.Add(1), 10), true
+	}
+
+	_subscription := &subscription{
+		client:   c,
+		topic:    pTopic,
+		group:    c.broker.group(pTopic, _name, _private),
+		options:  pOptions,
+		handler:  pHandler,
+		lock:     &sync.Mutex{},
+		slots:    make(chan struct{}, _prefetch),
+		stop:     make(chan struct{}),
+		inflight: make(map[*delivery]bool),
+	}
+
+	_subscription.group.add_subscriber()
+	c.subscriptions[_subscription] = true
+	go _subscription.consume()
+
+	return _subscription, nil
+}
+
+// Close unsubscribes all the subscriptions of the client
+func (c *Client) Close() error {
+
+	c.lock.Lock()
+	c.closed = true
+	_subscriptions := make([]*subscription, 0, len(c.subscriptions))
+	for _subscription := range c.subscriptions {
+		_subscriptions = append(_subscriptions, _subscription)
+	}
+	c.lock.Unlock()
+
+	for _, _subscription := range _subscriptions {
+		_subscription.Unsubscribe()
+	}
+	return nil
+}
+
+// subscription is a subscription of a client to a topic
+type subscription struct {
+	client   *Client
+	topic    string
+	group    *group
+	options  iamqclient.SubscribeOptions
+	handler  iamqclient.Handler
+	lock     *sync.Mutex
+	slots    chan struct{} /// prefetch slots. a slot is taken per unacknowledged message
+	stop     chan struct{}
+	stopped  atomic.Bool
+	inflight map[*delivery]bool
+}
+
+func (s *subscription) Topic() string { return s.topic }
+
+func (s *subscription) Group() string {
+	if s.group.private {
+		return ""
+	}
+	return s.group.name
+}
+
+// consume delivers the messages of the group queue to the handler
+func (s *subscription) consume() {
+
+	for {
+		select {
+		case s.slots <- struct{}{}:
+		case <-s.stop:
+			return
+		}
+
+		_message := s.group.pop(&s.stopped)
+		if _message == nil {
+			<-s.slots
+			return
+		}
+
+		_delivery := &delivery{subscription: s, message: _message}
+
+		s.lock.Lock()
+		s.inflight[_delivery] = true
+		s.lock.Unlock()
+
+		s.handle(_delivery)
+	}
+}
+
+// handle calls the handler with the delivery. Message of a panicked handler is dropped.
+func (s *subscription) handle(pDelivery *delivery) {
+
+	defer func() {
+		if _r := recover(); _r != nil {
+			pDelivery.Nack(false)
+		}
+	}()
+
+	s.handler(pDelivery)
+
+	if s.options.Auto_Ack {
+		pDelivery.Ack()
+	}
+}
+
+// Unsubscribe stops the deliveries and requeues the unacknowledged messages.
+// Message in the handler is requeued too, and can be delivered to another subscriber of the group.
+func (s *subscription) Unsubscribe() error {
+
+	if s.stopped.Swap(true) {
+		return nil
+	}
+
+	close(s.stop)
+
+	s.group.lock.Lock()
+	s.group.subscribers--
+	s.group.cond.Broadcast()
+	s.group.lock.Unlock()
+
+	/// not waiting for the consumer, as Unsubscribe can be called by the handler
+	s.lock.Lock()
+	_inflight := make([]*delivery, 0, len(s.inflight))
+	for _delivery := range s.inflight {
+		_inflight = append(_inflight, _delivery)
+	}
+	s.lock.Unlock()
+
+	for _, _delivery := range _inflight {
+		_delivery.Nack(true)
+	}
+
+	if s.group.private {
+		s.client.broker.remove_group(s.topic, s.group.name)
+	}
+
+	s.client.lock.Lock()
+	delete(s.client.subscriptions, s)
+	s.client.lock.Unlock()
+
+	return nil
+}
+
+// delivery is a message delivered to a subscription. Implements iamqclient.IDelivery
+type delivery struct {
+	subscription *subscription
+	message      *iamqclient.Message
+}
+
+func (d *delivery) Message() *iamqclient.Message { return d.message }
+
+// Ack removes the message from the queue
+func (d *delivery) Ack() error {
+	return d.settle(false, false)
+}
+
+// Nack requeues the message to the front of the group queue or drops it
+func (d *delivery) Nack(pRequeue bool) error {
+	return d.settle(true, pRequeue)
+}
+
+// settle releases the prefetch slot of the delivery and requeues the message if requested
+func (d *delivery) settle(pNack bool, pRequeue bool) error {
+
+	_subscription := d.subscription
+
+	_subscription.lock.Lock()
+	if !_subscription.inflight[d] {
+		_subscription.lock.Unlock()
+		return errors.New("message " + d.message.ID + " is already acknowledged")
+	}
+	delete(_subscription.inflight, d)
+	_subscription.lock.Unlock()
+
+	if pNack && pRequeue {
+		d.message.Redelivered++
+		_subscription.group.requeue(d.message)
+	} else {
+		_subscription.group.settle()
+	}
+
+	<-_subscription.slots
+	return nil
+}
@@ -0,0 +1,250 @@
+package memmq
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"agnione.appfm/src/afplugins/mq/iamqclient"
+)
+
+// wait_for waits up to 1 second for the condition
+func wait_for(t *testing.T, pWhat string, pCondition func() bool) {
+
+	_deadline := time.Now().Add(time.Second)
+	for !pCondition() {
+		if time.Now().After(_deadline) {
+			t.Fatal(pWhat + " is not reached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// receiver keeps the deliveries of a subscription without settling them
+type receiver struct {
+	lock       sync.Mutex
+	deliveries []iamqclient.IDelivery
+}
+
+func (r *receiver) handle(pDelivery iamqclient.IDelivery) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.deliveries = append(r.deliveries, pDelivery)
+}
+
+func (r *receiver) count() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.deliveries)
+}
+
+func (r *receiver) get(pIndex int) iamqclient.IDelivery {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.deliveries[pIndex]
+}
+
+// bodies returns the message bodies of the deliveries
+func (r *receiver) bodies() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	_bodies := make([]string, 0, len(r.deliveries))
+	for _, _delivery := range r.deliveries {
+		_bodies = append(_bodies, string(_delivery.Message().Body))
+	}
+	return _bodies
+}
+
+// subscribe subscribes a new receiver to the topic
+func subscribe(t *testing.T, pClient *Client, pTopic string, pOptions iamqclient.SubscribeOptions) (*receiver, iamqclient.ISubscription) {
+
+	_receiver := &receiver{}
+	_subscription, _err := pClient.Subscribe(pTopic, pOptions, _receiver.handle)
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	return _receiver, _subscription
+}
+
+// publish publishes the messages with the given bodies
+func publish(t *testing.T, pClient *Client, pTopic string, pBodies ...string) {
+
+	for _, _body := range pBodies {
+		if _err := pClient.Publish(context.Background(), pTopic, &iamqclient.Message{Body: []byte(_body)}); _err != nil {
+			t.Fatal(_err)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+
+	_client := New(NewBroker(0))
+	defer _client.Close()
+
+	_first, _ := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "billing"})
+	_second, _ := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "billing"})
+	_audit, _ := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "audit", Prefetch: 10})
+	_all, _ := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Prefetch: 10})
+
+	publish(t, _client, "orders", "1", "2")
+
+	/// each group gets a copy. unacknowledged message of a billing subscriber sends the next one to the other
+	wait_for(t, "delivery to the groups", func() bool {
+		return _first.count() == 1 && _second.count() == 1 && _audit.count() == 2 && _all.count() == 2
+	})
+	if _got := _first.bodies()[0] + _second.bodies()[0]; _got != "12" && _got != "21" {
+		t.Fatalf("expected one message per billing subscriber, got %q", _got)
+	}
+
+	/// acknowledged subscriber takes the next message of the group
+	_next := _first
+	if _first.bodies()[0] == "1" {
+		_next = _second
+	}
+	_next.get(0).Ack()
+	publish(t, _client, "orders", "3")
+	wait_for(t, "next delivery to the acknowledged subscriber", func() bool { return _next.count() == 2 })
+
+	time.Sleep(10 * time.Millisecond)
+	if _first.count()+_second.count() != 3 {
+		t.Fatalf("message delivered to more than one subscriber of the group %v %v", _first.bodies(), _second.bodies())
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+
+	_broker := NewBroker(0)
+	_client := New(_broker)
+	defer _client.Close()
+
+	_receiver, _ := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "billing", Prefetch: 2})
+	publish(t, _client, "orders", "1", "2", "3", "4", "5")
+
+	wait_for(t, "prefetch deliveries", func() bool { return _receiver.count() == 2 })
+	time.Sleep(10 * time.Millisecond)
+	if _count := _receiver.count(); _count != 2 {
+		t.Fatalf("expected 2 unacknowledged messages, got %d", _count)
+	}
+	if _queued := _broker.Queued()["orders/billing"]; _queued != 3 {
+		t.Fatalf("expected 3 waiting messages, got %d", _queued)
+	}
+
+	if _err := _receiver.get(0).Ack(); _err != nil {
+		t.Fatal(_err)
+	}
+	if _err := _receiver.get(0).Ack(); _err == nil {
+		t.Fatal("expected error for the second ack")
+	}
+	wait_for(t, "delivery after the ack", func() bool { return _receiver.count() == 3 })
+}
+
+func TestNack(t *testing.T) {
+
+	_broker := NewBroker(0)
+	_client := New(_broker)
+	defer _client.Close()
+
+	_receiver, _ := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "billing"})
+	publish(t, _client, "orders", "1", "2")
+	wait_for(t, "first delivery", func() bool { return _receiver.count() == 1 })
+
+	/// requeued message is delivered again before the next message
+	_receiver.get(0).Nack(true)
+	wait_for(t, "redelivery", func() bool { return _receiver.count() == 2 })
+	if _message := _receiver.get(1).Message(); string(_message.Body) != "1" || _message.Redelivered != 1 {
+		t.Fatalf("expected redelivered message 1, got %q redelivered %d", _message.Body, _message.Redelivered)
+	}
+
+	/// dropped message is not delivered again
+	_receiver.get(1).Nack(false)
+	wait_for(t, "next delivery", func() bool { return _receiver.count() == 3 })
+	if _body := string(_receiver.get(2).Message().Body); _body != "2" {
+		t.Fatalf("expected message 2 after the drop, got %q", _body)
+	}
+	_receiver.get(2).Ack()
+
+	time.Sleep(10 * time.Millisecond)
+	if _count, _queued := _receiver.count(), _broker.Queued()["orders/billing"]; _count != 3 || _queued != 0 {
+		t.Fatalf("expected no more messages, got %d deliveries %d queued", _count, _queued)
+	}
+}
+
+func TestUnsubscribe_Redelivery(t *testing.T) {
+
+	_client := New(NewBroker(0))
+	defer _client.Close()
+
+	_first, _subscription := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "billing"})
+	publish(t, _client, "orders", "1")
+	wait_for(t, "first delivery", func() bool { return _first.count() == 1 })
+
+	/// unacknowledged message of the unsubscribed subscriber goes to the next subscriber of the group
+	_subscription.Unsubscribe()
+	if _err := _first.get(0).Ack(); _err == nil {
+		t.Fatal("expected error for the ack of the requeued message")
+	}
+
+	_second, _ := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "billing"})
+	wait_for(t, "redelivery", func() bool { return _second.count() == 1 })
+	if _message := _second.get(0).Message(); string(_message.Body) != "1" || _message.Redelivered != 1 {
+		t.Fatalf("expected redelivered message 1, got %q redelivered %d", _message.Body, _message.Redelivered)
+	}
+}
+
+func TestQueue_Full(t *testing.T) {
+
+	_broker := NewBroker(2)
+	_client := New(_broker)
+	defer _client.Close()
+
+	_receiver, _ := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "billing"})
+	publish(t, _client, "orders", "1")
+	wait_for(t, "first delivery", func() bool { return _receiver.count() == 1 })
+
+	/// unacknowledged message takes a place in the queue
+	publish(t, _client, "orders", "2")
+	if _err := _client.Publish(context.Background(), "orders", &iamqclient.Message{Body: []byte("3")}); _err == nil {
+		t.Fatal("expected error for the full queue")
+	}
+
+	/// requeue does not grow the queue over its size
+	_receiver.get(0).Nack(true)
+	wait_for(t, "redelivery", func() bool { return _receiver.count() == 2 })
+	if _err := _client.Publish(context.Background(), "orders", &iamqclient.Message{Body: []byte("3")}); _err == nil {
+		t.Fatal("expected error for the full queue after the requeue")
+	}
+	if _queued := _broker.Queued()["orders/billing"]; _queued != 1 {
+		t.Fatalf("expected 1 waiting message, got %d", _queued)
+	}
+}
+
+func TestQueue_Full_Without_Subscribers(t *testing.T) {
+
+	_broker := NewBroker(2)
+	_client := New(_broker)
+	defer _client.Close()
+
+	/// group stays after its last subscriber is gone
+	_, _subscription := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "billing"})
+	_subscription.Unsubscribe()
+
+	_active, _ := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "audit", Prefetch: 10})
+
+	for _index := 1; _index <= 5; _index++ {
+		publish(t, _client, "orders", strconv.Itoa(_index))
+		wait_for(t, "delivery to the audit group", func() bool { return _active.count() == _index })
+		_active.get(_index - 1).Ack()
+	}
+
+	if _queued := _broker.Queued()["orders/billing"]; _queued != 2 {
+		t.Fatalf("expected 2 waiting messages, got %d", _queued)
+	}
+
+	_receiver, _ := subscribe(t, _client, "orders", iamqclient.SubscribeOptions{Group: "billing", Prefetch: 10})
+	wait_for(t, "latest messages", func() bool { return _receiver.count() == 2 })
+	if _bodies := _receiver.bodies(); _bodies[0] != "4" || _bodies[1] != "5" {
+		t.Fatalf("expected the latest messages 4 & 5, got %v", _bodies)
+	}
+}
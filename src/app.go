@@ -1,13 +1,11 @@
 // main package provides launcher for Kandy application framework
 //
-//  - Creates an instance of Agni Application Framework
-//	- Initialize and starts the Agni
-//	- Watch OS Intercept signals and stops the running Agni instance
+//  - Reads the command line arguments
+//	- Runs the Agni Application Framework by agni.Run until an OS Intercept signal is received
 //
 // This package includes functions:
 //	- BuildInfo
 //	- GetBasePath
-//	- SignalHandler
 //  - usage
 //	- main
 /*
@@ -43,22 +41,24 @@
 
 	Ajith de Silva		06/03/2024	Updated 	Added the log path as command line argument
 
+	Ajith de Silva		19/10/2026	Updated 	Added the encrypt_secrets command line argument
+
+	Ajith de Silva		19/10/2026	Updated 	Moved the framework run & reload loop to agni.Run for the custom mains
+
+	Ajith de Silva		19/10/2026	Updated 	Added the unit process mode for the units with process isolation
+
 #######################################################################################################################
 */
 package main
 
 import (
-	"context"
 	"flag"
 	"fmt"
 	"os"
-	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
-	"syscall"
-	"time"
 
 	build "agnione/v1/src/lib" /// import the AgniOne lib package
 
@@ -69,10 +69,6 @@ import (
 )
 
 
-var agniApp *agni.AgniApp /// global Agni application instance 
-var cancelFunc context.CancelFunc
-var ctx context.Context
-
 // struct to hold application build information
 func BuildInfo() *build.BuildInfo {
 	return &build.BuildInfo{
@@ -93,12 +89,6 @@ func GetBasePath() *string {
 	return &_curDir
 }
 
-// signaleHandler wait for the interrupt signal to support graceful shutdown.
-func SignaleHandler() {
-	<-ctx.Done()
-	println("*********************************\nShutdown Signal Received\n*********************************")
-}
-
 /// define the command line arguments
 var main_path = flag.String("main_path", "", "base/root path of the application")
 var log_path = flag.String("log_path", "", "path that application writes the log entries. if not given, application will use path in config file")
@@ -106,6 +96,9 @@ var app_path = flag.String("app_path", "", "base path that application configura
 var cpu_count = flag.Int("cpu_count", 5, "number of cpu cores to be used. If not given, all available cpu cores will be used.")
 var rest_port= flag.Int("rest_port", 8080, "TCP port that application exposes its REST endpoints to control & monitor application. default it 8080. Max:65635.")
 var ws_port=flag.Int("ws_port", 2345, "TCP port that application exposes its web socket endpoints for real time application monitor. Default it 2345. Max:65635.")
+var encrypt_secrets=flag.String("encrypt_secrets", "", "plain JSON file of name:value pairs to encrypt into <main_path>config/secrets.enc. Application exits after encryption.")
+var unit_host=flag.String("unit_host", "", "internal. socket of the framework that started this process to run an unit with process isolation")
+var unit_name=flag.String("unit_name", "", "internal. name of the unit run by this process. used with unit_host")
 
 func Filter_Number(value string) int {
 	if _value,_err:=strconv.Atoi(value); _err != nil {
@@ -134,16 +127,6 @@ func main() {
 		runtime.GC()
 	}()
 
-	println("");println("")
-	color.Cyan(banner)
-	banner = ""
-	println("");println("")
-
-	_buildinfo := BuildInfo() /// get the application build information
-	color.Yellow("\tVersion : " + _buildinfo.Version + "\n\tBuilt time : " +  _buildinfo.Time + "\n\tBuilt user : " + _buildinfo.User + "\n\tBuilt Go version : " + _buildinfo.BuildGoVersion + "\n\n")
-	_buildinfo = nil
-	color.Cyan("############################################################\n\n")
-
 	flag.Usage = usage
 	
 	// if help is passed as cmd line. Show usage and exit.
@@ -164,6 +147,35 @@ func main() {
 		app_path = main_path
 	}
 
+	/// run the unit of the framework that started this process and exit
+	if *unit_host != "" {
+		if _err := agni.Run_Unit_Host(main_path, app_path, *unit_host, *unit_name); _err != nil {
+			println("unit process error " + _err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	println("");println("")
+	color.Cyan(banner)
+	banner = ""
+	println("");println("")
+
+	_buildinfo := BuildInfo() /// get the application build information
+	color.Yellow("\tVersion : " + _buildinfo.Version + "\n\tBuilt time : " +  _buildinfo.Time + "\n\tBuilt user : " + _buildinfo.User + "\n\tBuilt Go version : " + _buildinfo.BuildGoVersion + "\n\n")
+	_buildinfo = nil
+	color.Cyan("############################################################\n\n")
+
+	/// encrypt the local secrets file and exit
+	if *encrypt_secrets != "" {
+		if _secrets_file, _key_file, _err := agni.Encrypt_Secrets_File(main_path, encrypt_secrets); _err != nil {
+			println("error " + _err.Error())
+		} else {
+			println("secrets encrypted to " + _secrets_file + " with the key " + _key_file)
+		}
+		return
+	}
+
 	defer func() {
 
 		if _r:=recover();_r!=nil{
@@ -199,87 +211,10 @@ func main() {
 	println("CPU cores     : " +  strconv.Itoa(*cpu_count) + "/" + strconv.Itoa(runtime.NumCPU()))
 	println("OS Process ID : " + strconv.Itoa(_os_pid))
 	
-	var _err error
-
-	/// read config from config server and save it to the /config folder
-start:
-
-	/// create AgniOne App instance and initialize it
-	println("using app root path\t: " +  *main_path)
-	println("using app log path\t: "  +  *log_path)
-	println("using app unit path\t: " +  *app_path + "\n")
-	
-	println("\nInitialzing AgniOne ......")
-	agniApp = new(agni.AgniApp)
-
-	/// create the cancellation application context
-	ctx, cancelFunc = context.WithCancel(context.Background())
-	
-	defer func ()  {
-		ctx=nil
-		cancelFunc=nil
-		agniApp=nil
-	}()
-	
-	/// initialize the app framework using the parameters.
-	if _, _err = agniApp.Initialize(&ctx, &_os_pid, main_path,  app_path, log_path,rest_port,ws_port); _err != nil {
+	/// run the framework until an interrupt signal is received. custom mains with compiled-in units call the same
+	if _err := agni.Run(main_path, app_path, log_path, rest_port, ws_port); _err != nil {
 		println("error " + _err.Error() + ". AgniOne is terminating")
-		return
 	}
-	
-	println("Initializing AgniOne ............  DONE")
-	
-	/* SIGNAL handling section */
-	termChan := make(chan os.Signal, 1) // Handle sigterm and await terminate signal CTRL + C signal
-	signal.Notify(termChan, os.Interrupt, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGSEGV, syscall.SIGABRT)
-	
-	defer func ()  {
-		termChan=nil
-	}()
-	
-	go SignaleHandler() /// starts the interrupt signal handler
-
-	println("Starting ::" + agniApp.Name() + " (" + agniApp.ID() + ") - " + agniApp.Version())
-
-	agniApp.Start() /// starts the application framework
-
-	println("Started :: " + agniApp.Name() + " (" + agniApp.ID() + ") - " + agniApp.Version())
-	
-	<-termChan // Blocks here until interrupt occur
-
-	cancelFunc() /// initiate the context Cancel
-
-	println("Signalling the AgniOne Unit(s) to stop")
-	agniApp.Stop()                 /// call the stop method of the AgniOne Framework. This will trigger all routines in it to stop
-	time.Sleep(time.Second * 5) /// give some time to stop/cleanup routines
-
-	println("Flag all routines to stop.......")
-	agniApp.Terminate() // broadcast the main channel stopped message
-	println("Flag all routines to stop....... DONE")
-
-	println("Waiting for termination of routines.......")
-	agniApp.WaitforClose() // Wait until all routines are done
-	println("All routines terminated")
-	time.Sleep(time.Second * 1)
-	reload := agniApp.Reload_Requested() /// read if reload requested flag set
-
-	/// clear the variables
-	println("Stopped :: " + agniApp.Name() + " (" + agniApp.ID() + ") - " + agniApp.Version() + "\n")
-	println("Cleaning the Agni environment")
-	agniApp.DeInitialize()	///
-	agniApp = nil
-
-	/// if reload requested then reload the Agni
-	if reload {
-		println("Application reload requested.\r\n Reloading application....")
-		runtime.GC()
-		goto start
-	}
-
-	ctx = nil
-	termChan = nil
-
-	println("\nAgniOne Framework terminated.\n")
 }
 
 var banner = `
@@ -0,0 +1,109 @@
+// configsource package provides the sources to fetch the application configuration from
+//
+// This package includes:
+//	- IConfigSource interface
+//	- Register
+//	- New
+//	- file source
+//	- http(s) source
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   configsource
+
+	Objective     :   Define the pluggable configuration source interface and the built-in sources.
+					Sources are registered by type and created from the config_source settings of core.config
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version with file & http sources
+
+	Ajith de Silva		19/10/2026	Updated 	Documented the checksum as a truncation check & the signature as the integrity check
+
+#########################################################################################
+*/
+package configsource
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// Content holds the configuration content fetched from a source
+type Content struct {
+	Data      []byte
+	Version   string /// version of the content given by the source. ETag for http source
+	Checksum  string /// sha256 checksum given by the source. empty if not given. detects truncated content only
+	Signature []byte /// ed25519 signature given by the source. nil if not given. the integrity check of the content
+}
+
+// IConfigSource interface needs to be implemented by the configuration sources
+type IConfigSource interface {
+
+	// Name returns the source description to be used in logs
+	Name() string
+
+	// Fetch fetches the configuration content.
+	// pVersion is the version of the last fetched content. If the content is not changed since, returns nil,nil
+	Fetch(pCtx context.Context, pVersion string) (*Content, error)
+}
+
+// Factory creates a configuration source from the settings
+type Factory func(pSettings *fmtypes.ConfigSource) (IConfigSource, error)
+
+var factories = map[string]Factory{}
+var factories_lock = &sync.RWMutex{}
+
+// Register registers a configuration source factory for the given type.
+// Registered type can be used as config_source type in core.config
+func Register(pType string, pFactory Factory) {
+	factories_lock.Lock()
+	defer factories_lock.Unlock()
+	factories[strings.ToLower(pType)] = pFactory
+}
+
+// New creates the configuration source for the given settings
+// Returns IConfigSource and nil if success. Unless nil and error
+func New(pSettings *fmtypes.ConfigSource) (IConfigSource, error) {
+
+	factories_lock.RLock()
+	_factory, _found := factories[strings.ToLower(pSettings.Type)]
+	factories_lock.RUnlock()
+
+	if !_found {
+		return nil, errors.New("config source type " + pSettings.Type + " is not supported")
+	}
+
+	if len(pSettings.Location) == 0 {
+		return nil, errors.New("config source location is not set")
+	}
+
+	return _factory(pSettings)
+}
+
+func init() {
+	Register("file", new_file_source)
+	Register("http", new_http_source)
+	Register("https", new_http_source)
+}
@@ -0,0 +1,159 @@
+package configsource
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	autls "agnione.appfm/src/utils"
+)
+
+func TestNew(t *testing.T) {
+
+	_cases := []struct {
+		name     string
+		settings fmtypes.ConfigSource
+		error    string
+	}{
+		{"file", fmtypes.ConfigSource{Type: "file", Location: "app.config"}, ""},
+		{"type case", fmtypes.ConfigSource{Type: "HTTPS", Location: "https://localhost/app.config"}, ""},
+		{"unknown type", fmtypes.ConfigSource{Type: "ftp", Location: "ftp://localhost"}, "config source type ftp is not supported"},
+		{"no location", fmtypes.ConfigSource{Type: "http"}, "config source location is not set"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			_got := ""
+			if _, _err := New(&_case.settings); _err != nil {
+				_got = _err.Error()
+			}
+			if _got != _case.error {
+				t.Fatalf("expected error %q, got %q", _case.error, _got)
+			}
+		})
+	}
+}
+
+// config_server serves the content with the given response headers. ETag is checked against If-None-Match
+func config_server(t *testing.T, pContent string, pHeaders map[string]string) *httptest.Server {
+
+	_server := httptest.NewServer(http.HandlerFunc(func(pWriter http.ResponseWriter, pRequest *http.Request) {
+		if pRequest.Header.Get("apikey") != "key" {
+			pWriter.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if _etag := pHeaders["ETag"]; len(_etag) > 0 && pRequest.Header.Get("If-None-Match") == _etag {
+			pWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+		for _key, _value := range pHeaders {
+			pWriter.Header().Set(_key, _value)
+		}
+		pWriter.Write([]byte(pContent))
+	}))
+	t.Cleanup(_server.Close)
+	return _server
+}
+
+func TestHTTP_Source_Fetch(t *testing.T) {
+
+	_signature := base64.StdEncoding.EncodeToString([]byte("signature"))
+
+	_cases := []struct {
+		name    string
+		headers map[string]string
+		apikey  string
+		version string /// of the last fetch
+		changed bool
+		want    Content
+		error   string
+	}{
+		{name: "etag", headers: map[string]string{"ETag": `"v1"`, "X-Config-Checksum": "sha256:abc", "X-Config-Signature": _signature},
+			apikey: "key", changed: true, want: Content{Version: `"v1"`, Checksum: "sha256:abc", Signature: []byte("signature")}},
+		{name: "not modified", headers: map[string]string{"ETag": `"v1"`}, apikey: "key", version: `"v1"`},
+		{name: "changed etag", headers: map[string]string{"ETag": `"v2"`}, apikey: "key", version: `"v1"`,
+			changed: true, want: Content{Version: `"v2"`}},
+		{name: "no etag", apikey: "key", changed: true, want: Content{Version: autls.SHA256_Hex([]byte("{}"))}},
+		{name: "no etag not changed", apikey: "key", version: autls.SHA256_Hex([]byte("{}"))},
+		{name: "status", error: "config source responded with status 401"},
+		{name: "bad signature header", headers: map[string]string{"X-Config-Signature": "%%%"}, apikey: "key",
+			error: "invalid X-Config-Signature header."},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_server := config_server(t, "{}", _case.headers)
+			_source, _err := New(&fmtypes.ConfigSource{Type: "http", Location: _server.URL,
+				Headers: map[string]string{"apikey": _case.apikey}})
+			if _err != nil {
+				t.Fatal(_err)
+			}
+
+			_content, _err := _source.Fetch(context.Background(), _case.version)
+			if len(_case.error) > 0 {
+				if _err == nil || !strings.HasPrefix(_err.Error(), _case.error) {
+					t.Fatalf("expected error %q, got %v", _case.error, _err)
+				}
+				return
+			}
+			if _err != nil {
+				t.Fatal(_err)
+			}
+
+			if (_content != nil) != _case.changed {
+				t.Fatalf("expected changed %v, got %v", _case.changed, _content != nil)
+			}
+			if _content == nil {
+				return
+			}
+			if string(_content.Data) != "{}" || _content.Version != _case.want.Version || _content.Checksum != _case.want.Checksum ||
+				string(_content.Signature) != string(_case.want.Signature) {
+				t.Fatalf("unexpected content %+v", _content)
+			}
+		})
+	}
+}
+
+func TestHTTP_Source_Size(t *testing.T) {
+
+	_server := config_server(t, strings.Repeat(" ", int(MAX_CONFIG_SIZE)+1), nil)
+	_source, _ := New(&fmtypes.ConfigSource{Type: "http", Location: _server.URL, Headers: map[string]string{"apikey": "key"}})
+
+	if _, _err := _source.Fetch(context.Background(), ""); _err == nil || !strings.Contains(_err.Error(), "exceeds") {
+		t.Fatalf("expected error for the content over the max size, got %v", _err)
+	}
+}
+
+func TestFile_Source_Fetch(t *testing.T) {
+
+	_path := filepath.Join(t.TempDir(), "app.config")
+	os.WriteFile(_path, []byte("{}"), 0644)
+	os.WriteFile(_path+".sha256", []byte("sha256:abc\n"), 0644)
+	os.WriteFile(_path+".sig", []byte(base64.StdEncoding.EncodeToString([]byte("signature"))), 0644)
+
+	_source, _ := New(&fmtypes.ConfigSource{Type: "file", Location: _path})
+
+	_content, _err := _source.Fetch(context.Background(), "")
+	if _err != nil || _content == nil {
+		t.Fatalf("expected content, got %v", _err)
+	}
+	if _content.Version != autls.SHA256_Hex([]byte("{}")) || _content.Checksum != "sha256:abc" || string(_content.Signature) != "signature" {
+		t.Fatalf("unexpected content %+v", _content)
+	}
+
+	if _content, _err = _source.Fetch(context.Background(), _content.Version); _content != nil || _err != nil {
+		t.Fatalf("expected not changed, got %+v %v", _content, _err)
+	}
+
+	os.Remove(_path)
+	if _, _err = _source.Fetch(context.Background(), ""); _err == nil {
+		t.Fatal("expected error for the missing file")
+	}
+}
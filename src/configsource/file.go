@@ -0,0 +1,52 @@
+package configsource
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	autls "agnione.appfm/src/utils"
+)
+
+// file_source reads the configuration from a file. eg: a shared/mounted folder.
+//
+// Checksum is read from <file>.sha256 and signature from <file>.sig, if exist.
+type file_source struct {
+	path string
+}
+
+func new_file_source(pSettings *fmtypes.ConfigSource) (IConfigSource, error) {
+	return &file_source{path: pSettings.Location}, nil
+}
+
+func (fs *file_source) Name() string {
+	return "file " + fs.path
+}
+
+func (fs *file_source) Fetch(pCtx context.Context, pVersion string) (*Content, error) {
+
+	_data, _err := os.ReadFile(fs.path)
+	if _err != nil {
+		return nil, _err
+	}
+
+	_version := autls.SHA256_Hex(_data)
+	if _version == pVersion {
+		return nil, nil
+	}
+
+	_content := &Content{Data: _data, Version: _version}
+
+	if _checksum, _err := os.ReadFile(fs.path + ".sha256"); _err == nil {
+		_content.Checksum = strings.TrimSpace(string(_checksum))
+	}
+
+	if _signature, _err := os.ReadFile(fs.path + ".sig"); _err == nil {
+		if _content.Signature, _err = autls.Decode_Binary(string(_signature)); _err != nil {
+			_content.Signature = _signature /// raw binary signature
+		}
+	}
+
+	return _content, nil
+}
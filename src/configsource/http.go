@@ -0,0 +1,113 @@
+package configsource
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	autls "agnione.appfm/src/utils"
+)
+
+// MAX_CONFIG_SIZE define the max size of the configuration content accepted from http source
+const MAX_CONFIG_SIZE int64 = 4 << 20
+
+// http_source fetches the configuration from a http(s) endpoint.
+//
+// ETag of the last content is sent as If-None-Match, so unchanged content is not downloaded again.
+// Checksum is read from X-Config-Checksum header and signature from X-Config-Signature header (base64), if given.
+// Checksum comes with the content in the same response, so it detects a truncated download but not a tampered one.
+// Only the signature, verified with the public_key of the config source settings, protects the content.
+type http_source struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func new_http_source(pSettings *fmtypes.ConfigSource) (IConfigSource, error) {
+
+	_timeout := pSettings.Timeout
+	if _timeout <= 0 {
+		_timeout = 10
+	}
+
+	_transport := http.DefaultTransport.(*http.Transport).Clone()
+	if pSettings.Insecure_TLS == 1 {
+		_transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return &http_source{
+		url:     pSettings.Location,
+		headers: pSettings.Headers,
+		client:  &http.Client{Timeout: time.Second * time.Duration(_timeout), Transport: _transport},
+	}, nil
+}
+
+func (hs *http_source) Name() string {
+	return "http " + hs.url
+}
+
+func (hs *http_source) Fetch(pCtx context.Context, pVersion string) (*Content, error) {
+
+	_request, _err := http.NewRequestWithContext(pCtx, http.MethodGet, hs.url, nil)
+	if _err != nil {
+		return nil, _err
+	}
+
+	for _key, _value := range hs.headers {
+		_request.Header.Set(_key, _value)
+	}
+
+	if len(pVersion) > 0 {
+		_request.Header.Set("If-None-Match", pVersion)
+	}
+
+	_response, _err := hs.client.Do(_request)
+	if _err != nil {
+		return nil, _err
+	}
+	defer _response.Body.Close()
+
+	switch _response.StatusCode {
+	case http.StatusNotModified:
+		return nil, nil
+	case http.StatusOK:
+	default:
+		return nil, errors.New("config source responded with status " + strconv.Itoa(_response.StatusCode))
+	}
+
+	_data, _err := io.ReadAll(io.LimitReader(_response.Body, MAX_CONFIG_SIZE+1))
+	if _err != nil {
+		return nil, _err
+	}
+
+	if int64(len(_data)) > MAX_CONFIG_SIZE {
+		return nil, errors.New("config source content exceeds " + strconv.FormatInt(MAX_CONFIG_SIZE, 10) + " bytes")
+	}
+
+	_content := &Content{
+		Data:     _data,
+		Version:  _response.Header.Get("ETag"),
+		Checksum: _response.Header.Get("X-Config-Checksum"),
+	}
+
+	if len(_content.Version) == 0 {
+		/// no ETag support on the server. content checksum is used to detect the changes
+		_content.Version = autls.SHA256_Hex(_data)
+		if _content.Version == pVersion {
+			return nil, nil
+		}
+	}
+
+	if _signature := _response.Header.Get("X-Config-Signature"); len(_signature) > 0 {
+		if _content.Signature, _err = autls.Decode_Binary(_signature); _err != nil {
+			return nil, errors.New("invalid X-Config-Signature header. " + _err.Error())
+		}
+	}
+
+	return _content, nil
+}
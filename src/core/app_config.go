@@ -0,0 +1,489 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Configuration Apply Implementation
+//
+// Objective     :   Two-phase apply of the application configuration.
+//					New configuration is validated & staged, framework restarts with it and the unit startup
+//					is watched for a grace period. If required units failed to start, previous configuration
+//					is restored and framework restarts with it again.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Staged the configuration apply files atomically
+// Ajith de Silva				19/10/2026	Added 		Added the validation of the fetched configuration content
+// Ajith de Silva				19/10/2026	Added 		Added Validate_Unit_Requires for the plugins required by the units
+// Ajith de Silva				19/10/2026	Updated 	Checked the plugins required by the units before the apply
+// Ajith de Silva				19/10/2026	Updated 	Accepted the registered static:// unit paths
+// Ajith de Silva				19/10/2026	Updated 	Checked the depends_on of the units before the apply
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	apptypes "agnione/v1/src/appfm/types"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/pluginreg"
+	autls "agnione.appfm/src/utils"
+)
+
+// DEFAULT_APPLY_GRACE define the default seconds to watch the unit startup after a config apply
+const DEFAULT_APPLY_GRACE int = 30
+
+// app_config_file returns the full path of the application configuration file.
+func (app *AgniApp) app_config_file() string {
+	return app_config_path(*app.app_config)
+}
+
+// app_config_path returns the full path of the application configuration file of the given app_path.
+// app_path could be given as the folder of app.config or as the app.config file itself.
+func app_config_path(pApp_Path string) string {
+	if strings.HasSuffix(pApp_Path, ".config") {
+		return pApp_Path
+	}
+	return filepath.Join(pApp_Path, "app.config")
+}
+
+// Validate_App_Config checks the given application configuration before it is used.
+// Returns nil if the configuration is valid. Unless returns the error
+func (app *AgniApp) Validate_App_Config(pAppConfig *apptypes.AppConfig) error {
+	return validate_app_config(pAppConfig)
+}
+
+// validate_app_config checks the given application configuration. Used without a framework instance by the app shell
+func validate_app_config(pAppConfig *apptypes.AppConfig) error {
+
+	if pAppConfig == nil {
+		return errors.New("application configuration is empty")
+	}
+
+	if len(strings.TrimSpace(pAppConfig.App.ID)) == 0 || len(strings.TrimSpace(pAppConfig.App.Name)) == 0 {
+		return errors.New("application id and name are required")
+	}
+
+	_names := make(map[string]bool)
+	var _path string
+
+	for _index, _unit := range pAppConfig.Appunits {
+		if len(_unit.Uname) == 0 {
+			return errors.New("unit [" + strconv.Itoa(_index) + "] has no name")
+		}
+
+		if _names[_unit.Uname] {
+			return errors.New("unit " + _unit.Uname + " is defined more than once")
+		}
+		_names[_unit.Uname] = true
+
+		if _unit.Enable == 0 {
+			continue
+		}
+
+		if _unit.PoolSize <= 0 {
+			return errors.New("unit " + _unit.Uname + " is enabled with pool size " + strconv.Itoa(int(_unit.PoolSize)))
+		}
+
+		_path = _unit.Path
+		if pluginreg.Is_Static(_path) {
+			if _, _found := pluginreg.Static(pluginreg.UNIT_CATEGORY, pluginreg.Static_Name(_path)); !_found {
+				return errors.New("unit " + _unit.Uname + " static unit " + pluginreg.Static_Name(_path) + " is not registered")
+			}
+		} else if !autls.IsFileExist(&_path) {
+			return errors.New("unit " + _unit.Uname + " binary " + _unit.Path + " does not exist")
+		}
+
+		if len(_unit.ConfigFile) > 0 {
+			_path = _unit.ConfigFile
+			if !autls.IsFileExist(&_path) {
+				return errors.New("unit " + _unit.Uname + " config file " + _unit.ConfigFile + " does not exist")
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate_config_content decodes the given application configuration content with the secret references resolved
+// and checks it. Returns the configuration if valid. Unless nil,error
+func validate_config_content(pData []byte) (*apptypes.AppConfig, error) {
+
+	_resolved, _err := autls.Resolve_Secrets(pData)
+	if _err != nil {
+		return nil, _err
+	}
+
+	_config := &apptypes.AppConfig{}
+	if _err = json.Unmarshal(_resolved, _config); _err != nil {
+		return nil, _err
+	}
+
+	if _err = validate_app_config(_config); _err != nil {
+		return nil, _err
+	}
+	return _config, nil
+}
+
+// validate_reload_content checks the given application configuration content as Reload_Config checks the file,
+// including the plugins required by the units & their depends_on. Returns nil if valid. Unless returns the error
+func (app *AgniApp) validate_reload_content(pData []byte) error {
+
+	_config, _err := validate_config_content(pData)
+	if _err != nil {
+		return _err
+	}
+
+	_resolved, _err := autls.Resolve_Secrets(pData)
+	if _err != nil {
+		return _err
+	}
+
+	_config_ext := &fmtypes.AppConfigExt{}
+	if _err = json.Unmarshal(_resolved, _config_ext); _err != nil {
+		return _err
+	}
+
+	if _err = app.Validate_Unit_Requires(_config, _config_ext); _err != nil {
+		return _err
+	}
+	return validate_unit_depends(_config, _config_ext)
+}
+
+// validate_unit_depends checks the depends_on of the enabled units. Each dependency should be an enabled unit
+// and the units should not depend on each other in a cycle. Returns nil if valid. Unless returns the error
+func validate_unit_depends(pAppConfig *apptypes.AppConfig, pAppConfigExt *fmtypes.AppConfigExt) error {
+
+	_depends := make(map[string][]string)
+	for _, _unit := range pAppConfigExt.Appunits {
+		_depends[_unit.Uname] = _unit.Depends_On
+	}
+
+	_enabled := make(map[string]bool)
+	for _, _unit := range pAppConfig.Appunits {
+		_enabled[_unit.Uname] = _unit.Enable != 0
+	}
+
+	_order, _errors := unit_order(pAppConfig.Appunits, func(pUname string) []string { return _depends[pUname] })
+
+	for _, _index := range _order {
+		_unit := pAppConfig.Appunits[_index]
+		if _unit.Enable == 0 {
+			continue
+		}
+
+		if _reason, _found := _errors[_unit.Uname]; _found {
+			return errors.New("unit " + _unit.Uname + " " + _reason)
+		}
+		for _, _dependency := range _depends[_unit.Uname] {
+			if !_enabled[_dependency] {
+				return errors.New("unit " + _unit.Uname + " depends on disabled unit " + _dependency)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate_Unit_Requires checks that the plugins required by the enabled units are configured & enabled in core.config
+// Returns nil if valid. Unless returns the error
+func (app *AgniApp) Validate_Unit_Requires(pAppConfig *apptypes.AppConfig, pAppConfigExt *fmtypes.AppConfigExt) error {
+
+	if pAppConfig == nil || pAppConfigExt == nil {
+		return nil
+	}
+
+	_enabled := make(map[string]bool)
+	for _, _unit := range pAppConfig.Appunits {
+		_enabled[_unit.Uname] = _unit.Enable != 0
+	}
+
+	for _, _unit := range pAppConfigExt.Appunits {
+		if !_enabled[_unit.Uname] {
+			continue
+		}
+
+		for _, _require := range _unit.Requires {
+			_type := strings.ToLower(_require.Type)
+			if len(_type) == 0 {
+				_type = pluginreg.DEFAULT_TYPE
+			}
+
+			if _, _err := app.plugin_config(strings.ToLower(_require.Category), _type); _err != nil {
+				return errors.New("unit " + _unit.Uname + " requires " + pluginreg.Plugin_Key(_require.Category, _type) + ". " + _err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// Apply_App_Config stages the given application configuration and restarts the framework with it.
+// Configuration is checked as the reload checks it, including the plugins required by the units & their depends_on.
+// Files are replaced atomically, so that a failed apply leaves the current app.config as it is.
+//
+// After the restart, unit startup is watched for the given grace period (seconds).
+// If any enabled unit has no running instance at the end of it, previous configuration is restored.
+// Returns true,nil if the configuration is staged. Unless returns false,error
+func (app *AgniApp) Apply_App_Config(pAppConfigData *[]byte, pGrace_Period int) (bool, error) {
+
+	_config_file := app.app_config_file()
+	_apply_file := _config_file + ".apply"
+
+	if autls.IsFileExist(&_apply_file) {
+		return false, errors.New("configuration apply is already in progress")
+	}
+
+	if pGrace_Period <= 0 {
+		pGrace_Period = DEFAULT_APPLY_GRACE
+	}
+
+	_requested := time.Now().Format(time.RFC3339)
+
+	/// 1. validate the new configuration
+	if _err := app.validate_reload_content(*pAppConfigData); _err != nil {
+		app.Write2LogConsole("Configuration apply rejected. "+_err.Error(), apptypes.LOG_ERROR)
+		app.write_apply_result(&fmtypes.ConfigApplyResult{
+			State: fmtypes.APPLY_REJECTED, Requested: _requested, Message: _err.Error()})
+		return false, errors.New("invalid application configuration. " + _err.Error())
+	}
+
+	/// 2. keep the current configuration to roll back
+	_backup_file := _config_file + ".bak"
+	_current, _err := autls.Get_File_Content(&_config_file)
+	if _err != nil {
+		return false, errors.New("failed to read current configuration " + _config_file + ". " + _err.Error())
+	}
+
+	if _, _err = autls.WriteFileAtomic(&_backup_file, _current); _err != nil {
+		return false, errors.New("failed to backup current configuration to " + _backup_file + ". " + _err.Error())
+	}
+
+	/// 3. stage the new configuration & the apply state, then restart with it
+	_apply := fmtypes.ConfigApply{
+		State:        fmtypes.APPLY_PENDING,
+		Requested:    _requested,
+		Grace_Period: pGrace_Period,
+		Backup_File:  _backup_file,
+	}
+
+	_apply_data, _ := json.Marshal(_apply)
+	if _, _err = autls.WriteFileAtomic(&_apply_file, &_apply_data); _err != nil {
+		return false, errors.New("failed to write configuration apply state. " + _err.Error())
+	}
+
+	if _, _err = autls.WriteFileAtomic(&_config_file, pAppConfigData); _err != nil {
+		os.Remove(_apply_file)
+		return false, errors.New("failed to write " + _config_file + ". " + _err.Error())
+	}
+
+	app.Write2LogConsole("Configuration staged. restarting with the new configuration, grace period "+
+		strconv.Itoa(pGrace_Period)+"s", apptypes.LOG_WARN)
+
+	app.reload_requested = true
+	app.request_restart()
+
+	return true, nil
+}
+
+// Config_Apply_Status returns the state of the configuration apply in progress or the result of the last apply
+func (app *AgniApp) Config_Apply_Status() (*fmtypes.ConfigApplyResult, error) {
+
+	_config_file := app.app_config_file()
+	_apply_file := _config_file + ".apply"
+
+	if _apply, _err := read_apply_state(&_apply_file); _err == nil {
+		return &fmtypes.ConfigApplyResult{
+			State: _apply.State, Requested: _apply.Requested,
+			Message: "watching unit startup for " + strconv.Itoa(_apply.Grace_Period) + "s"}, nil
+	}
+
+	_result_file := _config_file + ".apply.result"
+	_data, _err := autls.Get_File_Content(&_result_file)
+	if _err != nil {
+		return nil, errors.New("no configuration apply found")
+	}
+
+	_result := &fmtypes.ConfigApplyResult{}
+	if _err = json.Unmarshal(*_data, _result); _err != nil {
+		return nil, errors.New("invalid configuration apply result. " + _err.Error())
+	}
+
+	return _result, nil
+}
+
+// watch_config_apply checks if the framework is started with a staged configuration.
+// If so, watches the unit startup for the grace period and commit or roll back the configuration.
+func (app *AgniApp) watch_config_apply() {
+
+	_config_file := app.app_config_file()
+	_apply_file := _config_file + ".apply"
+
+	_apply, _err := read_apply_state(&_apply_file)
+	if _err != nil {
+		return
+	}
+
+	app.Write2LogConsole("Started with staged configuration. watching unit startup for "+
+		strconv.Itoa(_apply.Grace_Period)+"s", apptypes.LOG_WARN)
+
+	app.Add_Routine()
+
+	go func() {
+		defer func() {
+			recover()
+			app.Remove_Routine()
+		}()
+
+		_timer := time.NewTimer(time.Second * time.Duration(_apply.Grace_Period))
+		defer _timer.Stop()
+
+		select {
+		case <-app.stopChan:
+			/// framework stopped during the grace period. apply state is kept, so it is watched again on next start.
+			return
+		case <-_timer.C:
+		}
+
+		_failed := app.failed_units()
+
+		_result := &fmtypes.ConfigApplyResult{
+			Requested: _apply.Requested,
+			Completed: time.Now().Format(time.RFC3339),
+			Failed:    _failed,
+		}
+
+		if len(_failed) == 0 {
+			_result.State = fmtypes.APPLY_COMMITTED
+			_result.Message = "all enabled units started"
+			os.Remove(_apply_file)
+			app.write_apply_result(_result)
+			app.Write2LogConsole("Configuration apply committed", apptypes.LOG_INFO)
+			app.send_apply_result(_result)
+			return
+		}
+
+		/// roll back to the previous configuration
+		_result.State = fmtypes.APPLY_ROLLEDBACK
+		_result.Message = strconv.Itoa(len(_failed)) + " unit(s) failed to start. previous configuration restored"
+
+		_previous, _err := autls.Get_File_Content(&_apply.Backup_File)
+		if _err == nil {
+			_, _err = autls.WriteFileAtomic(&_config_file, _previous)
+		}
+
+		if _err != nil {
+			_result.Message = strconv.Itoa(len(_failed)) + " unit(s) failed to start. failed to restore " +
+				_apply.Backup_File + ". " + _err.Error()
+			os.Remove(_apply_file)
+			app.write_apply_result(_result)
+			app.Write2LogConsole("Configuration apply failed. "+_result.Message, apptypes.LOG_ERROR)
+			app.send_apply_result(_result)
+			return
+		}
+
+		os.Remove(_apply_file)
+		app.write_apply_result(_result)
+
+		for _name, _reason := range _failed {
+			app.Write2LogConsole("Configuration apply :: unit "+_name+" failed. "+_reason, apptypes.LOG_ERROR)
+		}
+		app.Write2LogConsole("Configuration apply rolled back. restarting with the previous configuration", apptypes.LOG_ERROR)
+		app.send_apply_result(_result)
+
+		app.reload_requested = true
+		app.request_restart()
+	}()
+}
+
+// failed_units returns the enabled units that have no running instance, with the reason
+func (app *AgniApp) failed_units() map[string]string {
+
+	_failed := make(map[string]string)
+
+	for _, _unit := range app.running_config().Appunits {
+		if _unit.Enable == 0 {
+			continue
+		}
+
+		_running := 0
+		app.units_lock.RLock()
+		for _index, _name := range app.appunit_names {
+			if _name == _unit.Uname && app.appUnits[_index] != nil && app.appUnits[_index].IsStarted() {
+				_running++
+			}
+		}
+		app.units_lock.RUnlock()
+
+		if _running > 0 {
+			continue
+		}
+
+		if _reason, _found := app.unit_error(_unit.Uname); _found {
+			_failed[_unit.Uname] = _reason
+		} else {
+			_failed[_unit.Uname] = "no running instance"
+		}
+	}
+
+	return _failed
+}
+
+// write_apply_result writes the configuration apply result next to the app.config
+func (app *AgniApp) write_apply_result(pResult *fmtypes.ConfigApplyResult) {
+	_result_file := app.app_config_file() + ".apply.result"
+	_data, _ := json.Marshal(pResult)
+	if _, _err := autls.WriteFileContent(&_result_file, &_data); _err != nil {
+		app.Write2Log("Failed to write configuration apply result "+_result_file+". "+_err.Error(), apptypes.LOG_ERROR)
+	}
+}
+
+// send_apply_result broadcast the configuration apply result via web socket monitoring
+func (app *AgniApp) send_apply_result(pResult *fmtypes.ConfigApplyResult) {
+	if _data, _err := json.Marshal(pResult); _err == nil {
+		app.Send_Monitor_Message(_data)
+	}
+}
+
+// request_restart signals the application shell to stop & restart the framework.
+// app shell handles the SIGHUP and restarts when reload is requested.
+func (app *AgniApp) request_restart() {
+	if _err := syscall.Kill(*app.id, syscall.SIGHUP); _err != nil {
+		app.Write2LogConsole("Failed to signal the framework restart. "+_err.Error(), apptypes.LOG_ERROR)
+	}
+}
+
+// read_apply_state reads the configuration apply state from given file
+func read_apply_state(pApply_File *string) (*fmtypes.ConfigApply, error) {
+
+	_data, _err := autls.Get_File_Content(pApply_File)
+	if _err != nil {
+		return nil, _err
+	}
+
+	_apply := &fmtypes.ConfigApply{}
+	if _err = json.Unmarshal(*_data, _apply); _err != nil {
+		return nil, _err
+	}
+
+	return _apply, nil
+}
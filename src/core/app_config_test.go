@@ -0,0 +1,339 @@
+package agni
+
+import (
+	"encoding/json"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"agnione/v1/src/aau/iappunit"
+	iappfw "agnione/v1/src/appfm/iappfw"
+	apptypes "agnione/v1/src/appfm/types"
+	lib "agnione/v1/src/lib"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// config_unit is a started unit instance of the configuration tests
+type config_unit struct{}
+
+func (u *config_unit) New() any { return u }
+func (u *config_unit) Initialize(pApp iappfw.IAgniApp, pIndex int, pName string, pPath string, pConfig string) (bool, error) {
+	return true, nil
+}
+func (u *config_unit) Deinitialize()                 {}
+func (u *config_unit) Start() (bool, error)          { return true, nil }
+func (u *config_unit) Stop()                         {}
+func (u *config_unit) IsStarted() bool               { return true }
+func (u *config_unit) Info() *lib.BuildInfo          { return &lib.BuildInfo{} }
+func (u *config_unit) Status() *apptypes.AppUnitInfo { return &apptypes.AppUnitInfo{} }
+
+// config_test_unit holds an unit of the test app.config
+type config_test_unit struct {
+	name     string
+	enabled  bool
+	depends  []string
+	requires []fmtypes.PluginRequirement
+}
+
+// config_content returns the app.config content with the given units. Unit binaries are created in pDir
+func config_content(t *testing.T, pDir string, pID string, pUnits ...config_test_unit) []byte {
+
+	_config := apptypes.AppConfig{App: apptypes.App{ID: pID, Name: "test"}}
+	for _, _unit := range pUnits {
+		_path := filepath.Join(pDir, _unit.name+".so")
+		os.WriteFile(_path, nil, 0644)
+
+		_appunit := apptypes.Appunit{Uname: _unit.name, Path: _path, PoolSize: 1}
+		if _unit.enabled {
+			_appunit.Enable = 1
+		}
+		_config.Appunits = append(_config.Appunits, _appunit)
+	}
+
+	/// framework only settings are added to the unit entries
+	_data, _ := json.Marshal(_config)
+	_content := map[string]any{}
+	json.Unmarshal(_data, &_content)
+
+	for _index, _entry := range _content["appunits"].([]any) {
+		_entry.(map[string]any)["depends_on"] = pUnits[_index].depends
+		_entry.(map[string]any)["requires"] = pUnits[_index].requires
+	}
+
+	_data, _err := json.Marshal(_content)
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	return _data
+}
+
+// new_config_app returns a framework instance with the app.config of the given content in a temp folder
+func new_config_app(t *testing.T, pContent []byte) *AgniApp {
+
+	_dir := t.TempDir()
+	_config_file := filepath.Join(_dir, "app.config")
+	if _err := os.WriteFile(_config_file, pContent, 0644); _err != nil {
+		t.Fatal(_err)
+	}
+
+	_id := os.Getpid()
+	_core_ext := &fmtypes.FMConfigExt{Plugins: map[string][]fmtypes.PluginConfig{
+		"http": {{PlugIn: apptypes.PlugIn{Type: "default", Enable: 1}}}}}
+	return &AgniApp{
+		id:               &_id,
+		app_config:       &_config_file,
+		config_lock:      &sync.RWMutex{},
+		coreconfig_ext:   _core_ext,
+		appconfig:        &apptypes.AppConfig{},
+		units_lock:       &sync.RWMutex{},
+		unit_errors_lock: &sync.Mutex{},
+		unit_errors:      make(map[string]string),
+		routine_lock:     &sync.RWMutex{},
+		wgEntries:        &sync.WaitGroup{},
+		stopChan:         make(chan bool),
+	}
+}
+
+// restarts catches the SIGHUP sent by request_restart
+func restarts(t *testing.T) chan os.Signal {
+
+	_signals := make(chan os.Signal, 4)
+	signal.Notify(_signals, syscall.SIGHUP)
+	t.Cleanup(func() { signal.Stop(_signals) })
+	return _signals
+}
+
+// restarted checks whether the framework restart is requested in the given time
+func restarted(pSignals chan os.Signal, pWait time.Duration) bool {
+
+	select {
+	case <-pSignals:
+		return true
+	case <-time.After(pWait):
+		return false
+	}
+}
+
+// read_file returns the content of the file. Empty if not found
+func read_file(pFileName string) string {
+
+	_data, _ := os.ReadFile(pFileName)
+	return string(_data)
+}
+
+// temp_files returns the temp files left in the folder of the app.config
+func temp_files(pConfig_File string) []string {
+
+	_files, _ := filepath.Glob(filepath.Join(filepath.Dir(pConfig_File), ".*.tmp"))
+	return _files
+}
+
+func TestApply_App_Config_Rejected(t *testing.T) {
+
+	_http := []fmtypes.PluginRequirement{{Category: "http"}}
+	_mq := []fmtypes.PluginRequirement{{Category: "mq"}}
+
+	_cases := []struct {
+		name  string
+		id    string
+		units []config_test_unit
+		data  string /// content if not built from the units
+		fails string
+	}{
+		{name: "invalid JSON", data: "{", fails: "unexpected end of JSON input"},
+		{name: "no app id", units: []config_test_unit{{name: "api", enabled: true}}, fails: "application id and name are required"},
+		{name: "valid with a disabled unit", id: "app", units: []config_test_unit{{name: "api", enabled: true}, {name: "report"}}},
+		{name: "unknown dependency", id: "app", units: []config_test_unit{{name: "api", enabled: true, depends: []string{"cache"}}},
+			fails: "unit api depends on unknown unit cache"},
+		{name: "dependency cycle", id: "app", units: []config_test_unit{
+			{name: "api", enabled: true, depends: []string{"db"}}, {name: "db", enabled: true, depends: []string{"api"}}},
+			fails: "unit api in or depends on a depends_on cycle among api, db"},
+		{name: "disabled dependency", id: "app", units: []config_test_unit{
+			{name: "api", enabled: true, depends: []string{"db"}}, {name: "db"}},
+			fails: "unit api depends on disabled unit db"},
+		{name: "disabled unit depends", id: "app", units: []config_test_unit{
+			{name: "api", enabled: true}, {name: "report", depends: []string{"cache"}}}},
+		{name: "required plugin", id: "app", units: []config_test_unit{{name: "api", enabled: true, requires: _mq}},
+			fails: "unit api requires mq/default. Plugin category mq is NOT found"},
+		{name: "disabled unit requires", id: "app", units: []config_test_unit{
+			{name: "api", enabled: true, requires: _http}, {name: "report", requires: _mq}}},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_current := config_content(t, t.TempDir(), "app", config_test_unit{name: "api", enabled: true})
+			_app := new_config_app(t, _current)
+			_config_file := _app.app_config_file()
+			_signals := restarts(t)
+
+			_data := []byte(_case.data)
+			if len(_case.units) > 0 {
+				_data = config_content(t, filepath.Dir(_config_file), _case.id, _case.units...)
+			}
+
+			_staged, _err := _app.Apply_App_Config(&_data, 1)
+
+			if len(_case.fails) == 0 {
+				if !_staged || _err != nil {
+					t.Fatalf("valid configuration is not staged. %v", _err)
+				}
+				if !restarted(_signals, time.Second) {
+					t.Fatal("restart is not requested")
+				}
+				return
+			}
+
+			if _staged || _err == nil || !strings.HasSuffix(_err.Error(), _case.fails) {
+				t.Fatalf("expected %q, got %v", _case.fails, _err)
+			}
+
+			/// nothing is changed
+			if read_file(_config_file) != string(_current) {
+				t.Fatal("app.config is changed")
+			}
+			for _, _suffix := range []string{".apply", ".bak"} {
+				if _, _err := os.Stat(_config_file + _suffix); _err == nil {
+					t.Fatalf("%s is written", _suffix)
+				}
+			}
+			if _result, _ := _app.Config_Apply_Status(); _result == nil || _result.State != fmtypes.APPLY_REJECTED {
+				t.Fatalf("unexpected apply result %+v", _result)
+			}
+			if restarted(_signals, 50*time.Millisecond) {
+				t.Fatal("restart is requested")
+			}
+		})
+	}
+}
+
+func TestApply_App_Config_Staged(t *testing.T) {
+
+	_current := config_content(t, t.TempDir(), "app", config_test_unit{name: "api", enabled: true})
+	_app := new_config_app(t, _current)
+	_config_file := _app.app_config_file()
+	_signals := restarts(t)
+
+	_new := config_content(t, filepath.Dir(_config_file), "app",
+		config_test_unit{name: "db", enabled: true}, config_test_unit{name: "api", enabled: true, depends: []string{"db"}})
+
+	if _staged, _err := _app.Apply_App_Config(&_new, 5); !_staged || _err != nil {
+		t.Fatalf("configuration is not staged. %v", _err)
+	}
+	if !restarted(_signals, time.Second) {
+		t.Fatal("restart is not requested")
+	}
+
+	if read_file(_config_file) != string(_new) || read_file(_config_file+".bak") != string(_current) {
+		t.Fatal("new configuration is not staged with the backup of the current one")
+	}
+	if _files := temp_files(_config_file); len(_files) > 0 {
+		t.Fatalf("temp files are left %v", _files)
+	}
+
+	_apply, _err := read_apply_state(&[]string{_config_file + ".apply"}[0])
+	if _err != nil || _apply.State != fmtypes.APPLY_PENDING || _apply.Grace_Period != 5 || _apply.Backup_File != _config_file+".bak" {
+		t.Fatalf("unexpected apply state %+v %v", _apply, _err)
+	}
+	if _result, _ := _app.Config_Apply_Status(); _result == nil || _result.State != fmtypes.APPLY_PENDING {
+		t.Fatalf("unexpected apply status %+v", _result)
+	}
+
+	/// one apply at a time
+	if _staged, _err := _app.Apply_App_Config(&_new, 5); _staged || _err == nil || _err.Error() != "configuration apply is already in progress" {
+		t.Fatalf("second apply is staged. %v", _err)
+	}
+}
+
+func TestWatch_Config_Apply(t *testing.T) {
+
+	_cases := []struct {
+		name      string
+		started   bool   /// unit api has a started instance
+		reason    string /// load error of api
+		stop      bool   /// framework stops in the grace period
+		state     fmtypes.ConfigApplyState
+		restored  bool
+		restarted bool
+	}{
+		{name: "committed", started: true, state: fmtypes.APPLY_COMMITTED},
+		{name: "rolled back", reason: "port in use", state: fmtypes.APPLY_ROLLEDBACK, restored: true, restarted: true},
+		{name: "rolled back without a reason", state: fmtypes.APPLY_ROLLEDBACK, restored: true, restarted: true},
+		{name: "stopped in the grace period", stop: true},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_previous := config_content(t, t.TempDir(), "app", config_test_unit{name: "api", enabled: true})
+			_app := new_config_app(t, _previous)
+			_config_file := _app.app_config_file()
+			_signals := restarts(t)
+
+			/// framework restarted with the staged configuration
+			_staged := config_content(t, filepath.Dir(_config_file), "app", config_test_unit{name: "api", enabled: true})
+			if _staged, _err := _app.Apply_App_Config(&_staged, 1); !_staged || _err != nil {
+				t.Fatal(_err)
+			}
+			restarted(_signals, time.Second)
+			_staged_content := read_file(_config_file)
+
+			json.Unmarshal([]byte(_staged_content), _app.appconfig)
+			if _case.started {
+				_app.appUnits, _app.appunit_names = []iappunit.IAppUnit{&config_unit{}}, []string{"api"}
+			}
+			if len(_case.reason) > 0 {
+				_app.set_unit_error("api", _case.reason)
+			}
+
+			_app.watch_config_apply()
+			if _case.stop {
+				close(_app.stopChan)
+			}
+			_app.wgEntries.Wait()
+
+			if restarted(_signals, 50*time.Millisecond) != _case.restarted {
+				t.Fatalf("expected restart %v", _case.restarted)
+			}
+
+			if _case.stop {
+				if _, _err := os.Stat(_config_file + ".apply"); _err != nil {
+					t.Fatal("apply state is not kept for the next start")
+				}
+				return
+			}
+
+			_result, _err := _app.Config_Apply_Status()
+			if _err != nil || _result.State != _case.state || len(_result.Completed) == 0 {
+				t.Fatalf("unexpected apply result %+v %v", _result, _err)
+			}
+			if _, _err := os.Stat(_config_file + ".apply"); _err == nil {
+				t.Fatal("apply state is not removed")
+			}
+
+			_expected := _staged_content
+			if _case.restored {
+				_expected = string(_previous)
+				_reason := _case.reason
+				if len(_reason) == 0 {
+					_reason = "no running instance"
+				}
+				if _result.Failed["api"] != _reason {
+					t.Fatalf("unexpected failed units %v", _result.Failed)
+				}
+			}
+			if read_file(_config_file) != _expected {
+				t.Fatalf("unexpected app.config after the apply %s", read_file(_config_file))
+			}
+			if _files := temp_files(_config_file); len(_files) > 0 {
+				t.Fatalf("temp files are left %v", _files)
+			}
+		})
+	}
+}
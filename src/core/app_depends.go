@@ -0,0 +1,363 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Unit Dependency Implementation
+//
+// Objective     :   Order the units by "depends_on" of app.config, so that the units start after the units they
+//					depend on and stop before them.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Ordered the units of the given configuration snapshot
+// Ajith de Silva				19/10/2026	Updated 	Closed the plugin instances of the stopped units
+// Ajith de Silva				19/10/2026	Updated 	Added the unit start in the start order & checked the dependencies once
+// Ajith de Silva				19/10/2026	Updated 	Cancelled the routines of the stopped units
+// Ajith de Silva				19/10/2026	Updated 	Cancelled the contexts of the stopped units
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"agnione/v1/src/aau/iappunit"
+	apptypes "agnione/v1/src/appfm/types"
+)
+
+// unit_order returns the indexes of the units in the start order. A unit comes after the units of its depends_on.
+// Units keep the app.config order when they do not depend on each other.
+// Units with an unknown dependency or in a dependency cycle come last, with the reason by unit name
+func unit_order(pUnits []apptypes.Appunit, pDepends func(string) []string) ([]int, map[string]string) {
+
+	_errors := make(map[string]string)
+	_index_of := make(map[string]int, len(pUnits))
+	for _index, _unit := range pUnits {
+		_index_of[_unit.Uname] = _index
+	}
+
+	_pending := make([]int, len(pUnits)) /// dependencies not ordered yet
+	_dependents := make([][]int, len(pUnits))
+
+	for _index, _unit := range pUnits {
+		for _, _dependency := range pDepends(_unit.Uname) {
+			_from, _found := _index_of[_dependency]
+			switch {
+			case !_found:
+				_errors[_unit.Uname] = "depends on unknown unit " + _dependency
+			case _from == _index:
+				_errors[_unit.Uname] = "depends on itself"
+			default:
+				_pending[_index]++
+				_dependents[_from] = append(_dependents[_from], _index)
+			}
+		}
+	}
+
+	_order := make([]int, 0, len(pUnits))
+	_ordered := make([]bool, len(pUnits))
+
+	/// lowest ready index first, so that the independent units keep the app.config order
+	for len(_order) < len(pUnits) {
+		_next := -1
+		for _index := range pUnits {
+			if !_ordered[_index] && _pending[_index] == 0 {
+				_next = _index
+				break
+			}
+		}
+		if _next < 0 {
+			break
+		}
+
+		_ordered[_next] = true
+		_order = append(_order, _next)
+		for _, _dependent := range _dependents[_next] {
+			_pending[_dependent]--
+		}
+	}
+
+	/// units left are in a cycle or depend on a unit of a cycle
+	_cycle := make([]string, 0)
+	for _index, _unit := range pUnits {
+		if !_ordered[_index] {
+			_cycle = append(_cycle, _unit.Uname)
+		}
+	}
+	for _index, _unit := range pUnits {
+		if !_ordered[_index] {
+			_order = append(_order, _index)
+			if _, _found := _errors[_unit.Uname]; !_found {
+				_errors[_unit.Uname] = "in or depends on a depends_on cycle among " + strings.Join(_cycle, ", ")
+			}
+		}
+	}
+
+	return _order, _errors
+}
+
+// depends_on returns the depends_on units of the given unit in app.config
+func (app *AgniApp) depends_on(pUname string) []string {
+	return app.unit_ext(pUname).Depends_On
+}
+
+// start_order returns the indexes of the units of the given configuration in the start order
+func (app *AgniApp) start_order(pConfig *apptypes.AppConfig) ([]int, map[string]string) {
+	return unit_order(pConfig.Appunits, app.depends_on)
+}
+
+// stop_order returns the indexes of the unit pool in the stop order, the reverse of the start order of the units.
+// Last started instance of a unit is stopped first. Called with the units lock
+func (app *AgniApp) stop_order() []int {
+
+	_rank := make(map[string]int)
+	if _config := app.running_config(); _config != nil {
+		_order, _ := app.start_order(_config)
+		for _position, _index := range _order {
+			_rank[_config.Appunits[_index].Uname] = _position
+		}
+	}
+
+	_rank_of := func(pIndex int) int {
+		if pIndex < len(app.appunit_names) {
+			if _position, _found := _rank[app.appunit_names[pIndex]]; _found {
+				return _position
+			}
+		}
+		return -1 /// unit is not in app.config. stopped last
+	}
+
+	_indexes := make([]int, len(app.appUnits))
+	for _index := range _indexes {
+		_indexes[_index] = _index
+	}
+
+	slices.SortFunc(_indexes, func(pA int, pB int) int {
+		if _rank_a, _rank_b := _rank_of(pA), _rank_of(pB); _rank_a != _rank_b {
+			return _rank_b - _rank_a
+		}
+		return pB - pA
+	})
+	return _indexes
+}
+
+// unit_instances returns the instances of the unit in the pool
+func (app *AgniApp) unit_instances(pUname string) []iappunit.IAppUnit {
+
+	app.units_lock.RLock()
+	defer app.units_lock.RUnlock()
+
+	_instances := make([]iappunit.IAppUnit, 0)
+	for _index, _name := range app.appunit_names {
+		if _name == pUname && app.appUnits[_index] != nil {
+			_instances = append(_instances, app.appUnits[_index])
+		}
+	}
+	return _instances
+}
+
+// is_unit_started checks whether an instance of the unit is started
+func (app *AgniApp) is_unit_started(pUname string) bool {
+
+	for _, _instance := range app.unit_instances(pUname) {
+		if _instance.IsStarted() {
+			return true
+		}
+	}
+	return false
+}
+
+// check_dependencies checks once that an instance of each depends_on unit is started. It does not wait:
+// called after the load of the dependencies is completed, as Load_Units waits for them & the reload and the unit start
+// load the units one by one in the start order. Instances join the pool when they are started, so a dependency
+// without a started instance is not going to start and is not polled.
+// Returns error if a dependency is disabled, not loaded or stopped
+func (app *AgniApp) check_dependencies(pUname string) error {
+
+	_config := app.running_config()
+
+	for _, _dependency := range app.depends_on(pUname) {
+
+		_index := slices.IndexFunc(_config.Appunits, func(pUnit apptypes.Appunit) bool {
+			return pUnit.Uname == _dependency
+		})
+		if _index < 0 {
+			return errors.New("dependency " + _dependency + " is not found in app.config")
+		}
+		if _config.Appunits[_index].Enable == 0 {
+			return errors.New("dependency " + _dependency + " is disabled")
+		}
+
+		if len(app.unit_instances(_dependency)) == 0 {
+			return errors.New("dependency " + _dependency + " is not loaded")
+		}
+		if !app.is_unit_started(_dependency) {
+			return errors.New("dependency " + _dependency + " is stopped")
+		}
+	}
+	return nil
+}
+
+// start_units loads the given units again in the start order, after the units they depend on.
+// Stopped instances of the units are released & new instances are loaded with new contexts.
+// Returns the errors of the units not started by unit name
+func (app *AgniApp) start_units(pUnames []string) map[string]string {
+
+	_errors := make(map[string]string)
+	_config := app.running_config()
+	_order, _order_errors := app.start_order(_config)
+
+	for _, _index := range _order {
+		_appunit := _config.Appunits[_index]
+		if !slices.Contains(pUnames, _appunit.Uname) {
+			continue
+		}
+
+		if _err := app.start_unit(_index, &_appunit, _order_errors); _err != nil {
+			app.set_unit_error(_appunit.Uname, _err.Error())
+			app.Write2LogConsole("Failed to start AgniOne Unit "+_appunit.Uname+" - "+_err.Error(), apptypes.LOG_ERROR)
+			_errors[_appunit.Uname] = _err.Error()
+		}
+	}
+
+	for _, _uname := range pUnames {
+		if !slices.ContainsFunc(_config.Appunits, func(pUnit apptypes.Appunit) bool { return pUnit.Uname == _uname }) {
+			_errors[_uname] = "unit " + _uname + " is not found in app.config"
+		}
+	}
+	return _errors
+}
+
+// start_unit loads the unit of the given index of the running configuration, with the Load_Units checks.
+// Returns error if the unit is disabled, can not be ordered, its dependencies are not started or no instance started
+func (app *AgniApp) start_unit(pIndex int, pAppunit *apptypes.Appunit, pOrder_Errors map[string]string) error {
+
+	if pAppunit.Enable == 0 {
+		return errors.New("unit " + pAppunit.Uname + " is disabled")
+	}
+	if _reason, _found := pOrder_Errors[pAppunit.Uname]; _found {
+		return errors.New(_reason)
+	}
+	if _err := app.check_dependencies(pAppunit.Uname); _err != nil {
+		return _err
+	}
+
+	/// stopped instances can not be started again. they are released & the pool is loaded again
+	app.stop_unit_instances(pAppunit.Uname, -1)
+
+	if _loaded := app.Load_AppUnit(&pIndex, pAppunit); *_loaded == 0 {
+		if _reason, _found := app.unit_error(pAppunit.Uname); _found {
+			return errors.New(_reason)
+		}
+		return errors.New("no instance of unit " + pAppunit.Uname + " is started")
+	}
+	return nil
+}
+
+// start_errors returns the errors of start_units as one error. nil if no error
+func start_errors(pErrors map[string]string) error {
+
+	if len(pErrors) == 0 {
+		return nil
+	}
+
+	_names := make([]string, 0, len(pErrors))
+	for _name := range pErrors {
+		_names = append(_names, _name)
+	}
+	slices.Sort(_names)
+
+	_messages := make([]string, 0, len(_names))
+	for _, _name := range _names {
+		_messages = append(_messages, _name+": "+pErrors[_name])
+	}
+	return errors.New("units are not started. " + strings.Join(_messages, "; "))
+}
+
+// unit_dependents returns the units depending on the given unit, directly or via other units, in the stop order
+func (app *AgniApp) unit_dependents(pUname string) []string {
+
+	_affected := map[string]bool{pUname: true}
+	_config := app.running_config()
+	_order, _ := app.start_order(_config)
+
+	_dependents := make([]string, 0)
+	for _, _index := range _order {
+		_name := _config.Appunits[_index].Uname
+		for _, _dependency := range app.depends_on(_name) {
+			if _affected[_dependency] && !_affected[_name] {
+				_affected[_name] = true
+				_dependents = append(_dependents, _name)
+				break
+			}
+		}
+	}
+
+	slices.Reverse(_dependents)
+	return _dependents
+}
+
+// stop_unit cancels the contexts of the instances of the unit, stops them, cancels their routines
+// & closes the plugin instances of the unit. Instances are kept in the pool
+func (app *AgniApp) stop_unit(pUname string) int {
+
+	_stopped := 0
+	for _, _instance := range app.unit_instances(pUname) {
+		app.cancel_unit_context(_instance)
+		if _instance.IsStarted() {
+			_instance.Stop()
+			_stopped++
+		}
+		app.cancel_routines(_instance)
+	}
+	app.close_plugins(pUname)
+	app.Write2LogConsole("AppUnit - "+pUname+" stopped "+strconv.Itoa(_stopped)+" instances", apptypes.LOG_INFO)
+	return _stopped
+}
+
+// stop_unit_cascade stops the unit. Units depending on it are stopped first if pForce,
+// unless the unit is not stopped while its dependents are started
+func (app *AgniApp) stop_unit_cascade(pUname string, pForce bool) error {
+
+	if len(app.unit_instances(pUname)) == 0 {
+		return errors.New("unit " + pUname + " is not loaded")
+	}
+
+	_started := make([]string, 0)
+	for _, _dependent := range app.unit_dependents(pUname) {
+		if app.is_unit_started(_dependent) {
+			_started = append(_started, _dependent)
+		}
+	}
+
+	if len(_started) > 0 {
+		if !pForce {
+			app.Write2LogConsole("AppUnit - "+pUname+" is not stopped. started units depend on it: "+strings.Join(_started, ", "), apptypes.LOG_WARN)
+			return errors.New("units " + strings.Join(_started, ", ") + " depend on " + pUname + ". force to stop them too")
+		}
+
+		for _, _dependent := range _started {
+			app.Write2LogConsole("AppUnit - "+_dependent+" is stopped as it depends on "+pUname, apptypes.LOG_WARN)
+			app.stop_unit(_dependent)
+		}
+	}
+
+	app.stop_unit(pUname)
+	return nil
+}
@@ -0,0 +1,89 @@
+package agni
+
+import (
+	"strings"
+	"testing"
+
+	apptypes "agnione/v1/src/appfm/types"
+)
+
+// ordered_names returns the unit names of the start order
+func ordered_names(pUnits []apptypes.Appunit, pOrder []int) string {
+
+	_names := make([]string, 0, len(pOrder))
+	for _, _index := range pOrder {
+		_names = append(_names, pUnits[_index].Uname)
+	}
+	return strings.Join(_names, ",")
+}
+
+func TestUnit_Order(t *testing.T) {
+
+	_cases := []struct {
+		name    string
+		units   []string
+		depends map[string][]string
+		order   string
+		errors  map[string]string
+	}{
+		{name: "no dependencies", units: []string{"a", "b", "c"}, order: "a,b,c"},
+		{name: "dependency first", units: []string{"api", "db", "cache"},
+			depends: map[string][]string{"api": {"db", "cache"}}, order: "db,cache,api"},
+		{name: "chain", units: []string{"a", "b", "c"},
+			depends: map[string][]string{"a": {"b"}, "b": {"c"}}, order: "c,b,a"},
+		{name: "independent units keep the order", units: []string{"x", "api", "y", "db"},
+			depends: map[string][]string{"api": {"db"}}, order: "x,y,db,api"},
+		{name: "unknown dependency", units: []string{"api", "db"},
+			depends: map[string][]string{"api": {"queue"}}, order: "api,db",
+			errors: map[string]string{"api": "depends on unknown unit queue"}},
+		{name: "itself", units: []string{"api", "db"},
+			depends: map[string][]string{"api": {"api"}}, order: "api,db",
+			errors: map[string]string{"api": "depends on itself"}},
+		{name: "cycle", units: []string{"a", "b", "c"},
+			depends: map[string][]string{"a": {"b"}, "b": {"a"}}, order: "c,a,b",
+			errors: map[string]string{"a": "in or depends on a depends_on cycle among a, b",
+				"b": "in or depends on a depends_on cycle among a, b"}},
+		{name: "depends on a cycle", units: []string{"a", "b", "c", "d"},
+			depends: map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}, "d": {"c"}}, order: "a,b,c,d",
+			errors: map[string]string{"a": "in or depends on a depends_on cycle among a, b, c, d",
+				"b": "in or depends on a depends_on cycle among a, b, c, d",
+				"c": "in or depends on a depends_on cycle among a, b, c, d",
+				"d": "in or depends on a depends_on cycle among a, b, c, d"}},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_units := make([]apptypes.Appunit, 0, len(_case.units))
+			for _, _name := range _case.units {
+				_units = append(_units, apptypes.Appunit{Uname: _name})
+			}
+
+			_order, _errors := unit_order(_units, func(pUname string) []string { return _case.depends[pUname] })
+
+			if _names := ordered_names(_units, _order); _names != _case.order {
+				t.Fatalf("expected order %s, got %s", _case.order, _names)
+			}
+			if len(_errors) != len(_case.errors) {
+				t.Fatalf("unexpected errors %v", _errors)
+			}
+			for _name, _reason := range _case.errors {
+				if _errors[_name] != _reason {
+					t.Fatalf("expected %s error %q, got %q", _name, _reason, _errors[_name])
+				}
+			}
+		})
+	}
+}
+
+func TestStart_Errors(t *testing.T) {
+
+	if _err := start_errors(map[string]string{}); _err != nil {
+		t.Fatal(_err)
+	}
+
+	_err := start_errors(map[string]string{"db": "dependency cache is stopped", "api": "unit api is disabled"})
+	if _err == nil || _err.Error() != "units are not started. api: unit api is disabled; db: dependency cache is stopped" {
+		t.Fatalf("unexpected error %v", _err)
+	}
+}
@@ -0,0 +1,104 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Event Bus Implementation
+//
+// Objective     :   Provide the in-process event bus to the units, so that the units can publish & subscribe events
+//					without external systems. Units get it by checking the framework instance for eventbus.IEvents
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"agnione.appfm/src/eventbus"
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// BUS_EVENT define the event name of the event bus events broadcast via web socket monitoring
+const BUS_EVENT = "bus_event"
+
+// init_event_bus creates the event bus with the event_bus settings of core.config. Called by init_instance
+func (app *AgniApp) init_event_bus() {
+
+	_settings := app.coreconfig_ext.Core.Event_Bus
+
+	_bus, _err := eventbus.New(_settings.Queue_Size, eventbus.Policy(_settings.Policy))
+	if _err != nil {
+		fmt.Println("event bus settings are invalid, using defaults - " + _err.Error()) /// logger is not started yet
+		_bus, _ = eventbus.New(_settings.Queue_Size, eventbus.POLICY_DROP)
+	}
+
+	/// published events are broadcast for debugging
+	if _settings.Mirror == 1 {
+		_bus.Set_Mirror(app.mirror_event)
+	}
+
+	app.event_bus = _bus
+}
+
+// mirror_event broadcasts the published event via web socket monitoring
+func (app *AgniApp) mirror_event(pEvent *eventbus.Event) {
+
+	_message, _err := json.Marshal(struct {
+		Event string          `json:"event"`
+		Data  *eventbus.Event `json:"data"`
+	}{Event: BUS_EVENT, Data: pEvent})
+
+	if _err == nil {
+		app.Send_Monitor_Message(_message)
+	}
+}
+
+// Publish_Event publishes the payload to the topic of the event bus. Payload is sent as JSON.
+//
+// Returns nil if successful. Unless error
+func (app *AgniApp) Publish_Event(pCTX context.Context, pTopic string, pPayload any) error {
+
+	if app.event_bus == nil {
+		return errors.New("event bus is not initialized")
+	}
+	return app.event_bus.Publish(pCTX, pTopic, pPayload)
+}
+
+// Subscribe_Events subscribes the handler to the topic pattern of the event bus.
+// Subscription should be unsubscribed by the unit on Stop.
+//
+// Returns ISubscription,nil if successful. Unless nil,error
+func (app *AgniApp) Subscribe_Events(pPattern string, pOptions eventbus.SubscribeOptions, pHandler eventbus.Handler) (eventbus.ISubscription, error) {
+
+	if app.event_bus == nil {
+		return nil, errors.New("event bus is not initialized")
+	}
+	return app.event_bus.Subscribe(pPattern, pOptions, pHandler)
+}
+
+// Event_Stats returns the counters of the event bus
+func (app *AgniApp) Event_Stats() fmtypes.EventBusStats {
+
+	if app.event_bus == nil {
+		return fmtypes.EventBusStats{Subscriptions: make([]fmtypes.EventSubscriptionStats, 0)}
+	}
+	return app.event_bus.Stats()
+}
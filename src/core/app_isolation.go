@@ -0,0 +1,462 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Unit Process Isolation Implementation
+//
+// Objective     :   Run the units with "isolation": "process" in child processes of the framework binary.
+//					A crash of the unit ends its process only. Process is supervised & restarted by the framework,
+//					and is ended when the unit is unloaded.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	iappunit "agnione/v1/src/aau/iappunit"
+	iappfw "agnione/v1/src/appfm/iappfw"
+	apptypes "agnione/v1/src/appfm/types"
+	lib "agnione/v1/src/lib"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/unithost"
+)
+
+// ISOLATION_PROCESS define the isolation of the units run in a child process
+const ISOLATION_PROCESS = "process"
+
+const DEFAULT_MAX_RESTARTS = 5  /// restarts of a crashed unit process
+const DEFAULT_RESTART_DELAY = 1 /// seconds before the first restart of a crashed unit process
+const MAX_RESTART_DELAY = 60 * time.Second
+
+const UNIT_CONNECT_TIMEOUT = 15 * time.Second /// time for the unit process to connect to the framework
+const UNIT_CALL_TIMEOUT = 5 * time.Second     /// time for the unit process to reply to the Info, Status & IsStarted calls
+const UNIT_STOP_TIMEOUT = 10 * time.Second    /// time for the unit process to exit after Deinitialize, before it is killed
+
+// process_unit runs an unit in a child process. It is kept in the unit pool of the framework as the unit instance
+type process_unit struct {
+	iappunit.IAppUnit /// not set. functions of the unit are served by the unit process
+
+	app      *AgniApp
+	settings fmtypes.AppunitExt
+	lock     *sync.Mutex
+
+	init_args unithost.InitArgs /// arguments of Initialize. repeated on restart
+	cmd       *exec.Cmd
+	client    *unithost.UnitClient
+	exited    chan struct{} /// closed when the running process exits
+
+	started  bool /// unit is started by the framework. restarted process starts the unit
+	closing  bool /// unit is deinitialized. process is not restarted
+	killed   bool /// process is killed by Kill. restarted without delay & not counted
+	restarts int
+
+	info   lib.BuildInfo        /// last info of the unit
+	status apptypes.AppUnitInfo /// last status of the unit. returned while the process is restarted
+}
+
+// new_process_unit creates the unit that runs in a child process with the given settings
+func new_process_unit(pApp *AgniApp, pSettings fmtypes.AppunitExt) *process_unit {
+	return &process_unit{app: pApp, settings: pSettings, lock: &sync.Mutex{}}
+}
+
+// is_process_unit checks whether the unit runs in a child process of this framework instance
+func (app *AgniApp) is_process_unit(pUname string) bool {
+	return app.unit_host == nil && app.unit_ext(pUname).Isolation == ISOLATION_PROCESS
+}
+
+// Unit_Kill kills the unit processes of the given unit. Processes are restarted by the framework.
+// Returns the number of the killed processes. Unless 0,error
+func (app *AgniApp) Unit_Kill(pUnitName *string) (int, error) {
+
+	if !app.is_process_unit(*pUnitName) {
+		return 0, errors.New("unit " + *pUnitName + " is not running with process isolation")
+	}
+
+	app.units_lock.RLock()
+	defer app.units_lock.RUnlock()
+
+	_killed := 0
+	for _index, _unit := range app.appUnits {
+		if app.appunit_names[_index] != *pUnitName {
+			continue
+		}
+		if _process, _ok := _unit.(*process_unit); _ok && _process.Kill() == nil {
+			_killed++
+		}
+	}
+
+	if _killed == 0 {
+		return 0, errors.New("no running unit process of " + *pUnitName)
+	}
+
+	app.Write2LogConsole("Killed "+strconv.Itoa(_killed)+" unit processes of "+*pUnitName, apptypes.LOG_WARN)
+	return _killed, nil
+}
+
+// Initialize starts the unit process and initializes the unit in it
+func (pu *process_unit) Initialize(pApp iappfw.IAgniApp, pIndex int, pName string, pPath string, pConfig_File string) (bool, error) {
+
+	pu.init_args = unithost.InitArgs{Index: pIndex, Name: pName, Path: pPath, Config_File: pConfig_File}
+	pu.status.Info.Name = pName
+
+	return pu.launch(false)
+}
+
+// Start starts the unit in the unit process
+func (pu *process_unit) Start() (bool, error) {
+
+	_client := pu.current()
+	if _client == nil {
+		return false, errors.New("unit process of " + pu.init_args.Name + " is not running")
+	}
+
+	var _ok bool
+	if _err := _client.Call("Start", unithost.Empty{}, &_ok, 0); _err != nil {
+		return false, _err
+	}
+
+	pu.lock.Lock()
+	pu.started = true
+	pu.lock.Unlock()
+
+	return _ok, nil
+}
+
+// Stop stops the unit in the unit process. Process is kept running until Deinitialize
+func (pu *process_unit) Stop() {
+
+	pu.lock.Lock()
+	pu.started = false
+	pu.lock.Unlock()
+
+	if _client := pu.current(); _client != nil {
+		_client.Call("Stop", unithost.Empty{}, &unithost.Empty{}, 0)
+	}
+}
+
+// IsStarted checks whether the unit is started in a running unit process
+func (pu *process_unit) IsStarted() bool {
+
+	_client := pu.current()
+	if _client == nil {
+		return false
+	}
+
+	var _started bool
+	if _err := _client.Call("IsStarted", unithost.Empty{}, &_started, UNIT_CALL_TIMEOUT); _err != nil {
+		return false
+	}
+	return _started
+}
+
+// Info returns the build info of the unit. Last info is returned if the unit process is not running
+func (pu *process_unit) Info() *lib.BuildInfo {
+
+	if _client := pu.current(); _client != nil {
+		_info := lib.BuildInfo{}
+		if _err := _client.Call("Info", unithost.Empty{}, &_info, UNIT_CALL_TIMEOUT); _err == nil {
+			pu.lock.Lock()
+			pu.info = _info
+			pu.lock.Unlock()
+		}
+	}
+
+	pu.lock.Lock()
+	defer pu.lock.Unlock()
+
+	_info := pu.info
+	return &_info
+}
+
+// Status returns the status of the unit. Last status is returned if the unit process is not running
+func (pu *process_unit) Status() *apptypes.AppUnitInfo {
+
+	if _client := pu.current(); _client != nil {
+		_status := apptypes.AppUnitInfo{}
+		if _err := _client.Call("Status", unithost.Empty{}, &_status, UNIT_CALL_TIMEOUT); _err == nil {
+			pu.lock.Lock()
+			pu.status = _status
+			pu.lock.Unlock()
+		}
+	}
+
+	pu.lock.Lock()
+	defer pu.lock.Unlock()
+
+	_status := pu.status
+	return &_status
+}
+
+// Deinitialize deinitializes the unit and ends the unit process. Process is killed if it does not exit in time
+func (pu *process_unit) Deinitialize() {
+
+	pu.lock.Lock()
+	if pu.closing {
+		pu.lock.Unlock()
+		return
+	}
+	pu.closing = true
+	_cmd, _client, _exited := pu.cmd, pu.client, pu.exited
+	pu.cmd, pu.client, pu.exited = nil, nil, nil
+	pu.lock.Unlock()
+
+	if _client == nil {
+		return
+	}
+
+	_client.Call("Deinitialize", unithost.Empty{}, &unithost.Empty{}, UNIT_STOP_TIMEOUT)
+	_client.Close() /// unit process exits when the control connection is closed
+
+	select {
+	case <-_exited:
+	case <-time.After(UNIT_STOP_TIMEOUT):
+		pu.app.Write2LogConsole("Unit process of "+pu.init_args.Name+" did not exit in "+UNIT_STOP_TIMEOUT.String()+". killing it", apptypes.LOG_WARN)
+		kill_process(_cmd)
+		<-_exited
+	}
+
+	pu.app.Write2LogConsole("Unit process of "+pu.init_args.Name+" ended", apptypes.LOG_INFO)
+}
+
+// Kill kills the running unit process. Process is restarted by the supervisor
+func (pu *process_unit) Kill() error {
+
+	pu.lock.Lock()
+	defer pu.lock.Unlock()
+
+	if pu.cmd == nil || pu.closing {
+		return errors.New("unit process of " + pu.init_args.Name + " is not running")
+	}
+
+	pu.killed = true
+	return kill_process(pu.cmd)
+}
+
+// current returns the client of the running unit process. nil if the process is not running
+func (pu *process_unit) current() *unithost.UnitClient {
+	pu.lock.Lock()
+	defer pu.lock.Unlock()
+
+	return pu.client
+}
+
+// launch starts a new unit process and initializes the unit. Unit is started too, if pStart is true.
+// Process is supervised until it exits.
+func (pu *process_unit) launch(pStart bool) (bool, error) {
+
+	pu.lock.Lock()
+	_closing := pu.closing
+	pu.lock.Unlock()
+
+	if _closing {
+		return false, errors.New("unit " + pu.init_args.Name + " is deinitialized")
+	}
+
+	_cmd, _client, _exited, _err := pu.spawn()
+	if _err != nil {
+		return false, _err
+	}
+
+	var _ok bool
+	if _err = _client.Call("Initialize", pu.init_args, &_ok, 0); _err == nil && pStart {
+		_err = _client.Call("Start", unithost.Empty{}, &_ok, 0)
+	}
+
+	pu.lock.Lock()
+	if _err == nil && pu.closing {
+		_err = errors.New("unit " + pu.init_args.Name + " is deinitialized")
+	}
+	if _err != nil {
+		pu.lock.Unlock()
+		_client.Close()
+		kill_process(_cmd)
+		return false, _err
+	}
+	pu.cmd, pu.client, pu.exited = _cmd, _client, _exited
+	pu.lock.Unlock()
+
+	go pu.supervise(_cmd, _exited)
+
+	return _ok, nil
+}
+
+// spawn starts the unit process of the framework binary and waits until it connects to the framework.
+// Returns the process, the client of the unit & the channel closed when the process exits
+func (pu *process_unit) spawn() (*exec.Cmd, *unithost.UnitClient, chan struct{}, error) {
+
+	_executable, _err := os.Executable()
+	if _err != nil {
+		return nil, nil, nil, errors.New("failed to find the framework executable. " + _err.Error())
+	}
+
+	_socket := filepath.Join(os.TempDir(), "agni-"+strconv.Itoa(os.Getpid())+"-"+pu.init_args.Name+"-"+strconv.Itoa(pu.init_args.Index)+".sock")
+	os.Remove(_socket)
+
+	_listener, _err := net.ListenUnix("unix", &net.UnixAddr{Name: _socket, Net: "unix"})
+	if _err != nil {
+		return nil, nil, nil, errors.New("failed to listen on " + _socket + ". " + _err.Error())
+	}
+
+	/// socket is removed once the unit process is connected
+	defer func() {
+		_listener.Close()
+		os.Remove(_socket)
+	}()
+
+	if _err = os.Chmod(_socket, 0600); _err != nil {
+		return nil, nil, nil, errors.New("failed to restrict " + _socket + ". " + _err.Error())
+	}
+
+	_cmd := exec.Command(_executable, "--main_path", *pu.app.base_path, "--app_path", *pu.app.app_config,
+		"--unit_host", _socket, "--unit_name", pu.init_args.Name)
+	_cmd.Stdout = os.Stdout
+	_cmd.Stderr = os.Stderr
+	_cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true} /// interrupt of the framework terminal is not sent to the unit process
+
+	if _err = _cmd.Start(); _err != nil {
+		return nil, nil, nil, errors.New("failed to start the unit process. " + _err.Error())
+	}
+
+	_exited := make(chan struct{})
+	go func() {
+		_cmd.Wait()
+		close(_exited)
+	}()
+
+	/// stop waiting for the connections if the process exits before it connects
+	_connected := make(chan struct{})
+	defer close(_connected)
+	go func() {
+		select {
+		case <-_exited:
+			_listener.Close()
+		case <-_connected:
+		}
+	}()
+
+	_client, _err := unithost.Accept(_listener, UNIT_CONNECT_TIMEOUT, unithost.NewHostService(pu.app))
+	if _err != nil {
+		kill_process(_cmd)
+		return nil, nil, nil, _err
+	}
+
+	pu.app.Write2LogConsole("Unit process of "+pu.init_args.Name+" started. pid "+strconv.Itoa(_cmd.Process.Pid), apptypes.LOG_INFO)
+
+	return _cmd, _client, _exited, nil
+}
+
+// supervise waits for the unit process to exit and restarts it, with a delay doubled on each restart,
+// until the unit is deinitialized or the max restarts is reached
+func (pu *process_unit) supervise(pCmd *exec.Cmd, pExited chan struct{}) {
+
+	<-pExited
+	_reason := "exited. " + pCmd.ProcessState.String()
+
+	for {
+		pu.lock.Lock()
+		if pu.closing || pu.cmd != pCmd {
+			pu.lock.Unlock()
+			return
+		}
+
+		if pu.client != nil {
+			pu.client.Close()
+		}
+		pu.cmd, pu.client, pu.exited = nil, nil, nil
+
+		_delay := time.Duration(0)
+		if pu.killed {
+			pu.killed = false
+			_reason = "killed"
+		} else {
+			if pu.restarts >= pu.max_restarts() {
+				pu.lock.Unlock()
+				pu.app.Write2LogConsole("Unit process of "+pu.init_args.Name+" "+_reason+" not restarted after "+
+					strconv.Itoa(pu.restarts)+" restarts", apptypes.LOG_ERROR)
+				return
+			}
+			pu.restarts++
+			_delay = pu.restart_delay()
+		}
+		_start := pu.started
+		pu.lock.Unlock()
+
+		pu.app.Write2LogConsole("Unit process of "+pu.init_args.Name+" "+_reason+" restarting in "+_delay.String(), apptypes.LOG_WARN)
+		time.Sleep(_delay)
+
+		_, _err := pu.launch(_start)
+		if _err == nil {
+			pu.app.Write2LogConsole("Unit process of "+pu.init_args.Name+" restarted", apptypes.LOG_INFO)
+			return
+		}
+
+		/// failed restart is handled as another exit of the process
+		pCmd = nil
+		_reason = "failed to restart. " + _err.Error() + "."
+	}
+}
+
+// max_restarts returns the max restarts of a crashed unit process
+func (pu *process_unit) max_restarts() int {
+
+	if pu.settings.Max_Restarts < 0 {
+		return 0
+	}
+	if pu.settings.Max_Restarts == 0 {
+		return DEFAULT_MAX_RESTARTS
+	}
+	return pu.settings.Max_Restarts
+}
+
+// restart_delay returns the delay before the current restart. Restart_Delay doubled on each restart
+func (pu *process_unit) restart_delay() time.Duration {
+
+	_delay := time.Duration(pu.settings.Restart_Delay) * time.Second
+	if _delay <= 0 {
+		_delay = DEFAULT_RESTART_DELAY * time.Second
+	}
+
+	for _restart := 1; _restart < pu.restarts && _delay < MAX_RESTART_DELAY; _restart++ {
+		_delay *= 2
+	}
+	return min(_delay, MAX_RESTART_DELAY)
+}
+
+// kill_process kills the unit process with its process group
+func kill_process(pCmd *exec.Cmd) error {
+
+	if pCmd == nil || pCmd.Process == nil {
+		return nil
+	}
+
+	if _err := syscall.Kill(-pCmd.Process.Pid, syscall.SIGKILL); _err != nil {
+		return pCmd.Process.Kill()
+	}
+	return nil
+}
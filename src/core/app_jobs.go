@@ -0,0 +1,122 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Job Scheduler Implementation
+//
+// Objective     :   Provide the cron, fixed-rate & fixed-delay jobs to the units, instead of their own ticker loops.
+//					Units get it by checking the framework instance for scheduler.IJobs
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Ran the jobs with the context of the unit instance
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"context"
+	"errors"
+
+	apptypes "agnione/v1/src/appfm/types"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/scheduler"
+)
+
+// Schedule_Job schedules the job for the unit instance. Each pool instance of the unit schedules the job
+// with the same name & schedule. Job runs on every started instance, or on one of them if Singleton is set.
+//
+// Returns nil if successful. Unless error
+func (app *AgniApp) Schedule_Job(pOwner scheduler.IOwner, pJob scheduler.Job, pRun scheduler.Func) error {
+
+	if app.scheduler == nil {
+		return errors.New("scheduler is not initialized")
+	}
+	if _err := app.scheduler.Schedule(pOwner, pJob, pRun); _err != nil {
+		return _err
+	}
+
+	app.Write2LogConsole("Job "+pJob.Name+" is scheduled", apptypes.LOG_DEBUG)
+	return nil
+}
+
+// Unschedule_Job removes the job scheduled by the unit instance.
+// Jobs are removed by the framework too, when the instance is removed from the unit pool
+func (app *AgniApp) Unschedule_Job(pOwner scheduler.IOwner, pName string) {
+
+	if app.scheduler != nil {
+		app.scheduler.Unschedule(pOwner, pName)
+	}
+}
+
+// Jobs_List returns the run status of the scheduled jobs
+func (app *AgniApp) Jobs_List() []fmtypes.JobStatus {
+
+	if app.scheduler == nil {
+		return make([]fmtypes.JobStatus, 0)
+	}
+	return app.scheduler.Jobs()
+}
+
+// Job_Trigger starts a run of the job now.
+//
+// Returns nil if successful. Unless error
+func (app *AgniApp) Job_Trigger(pName string) error {
+
+	if app.scheduler == nil {
+		return errors.New("scheduler is not initialized")
+	}
+	if _err := app.scheduler.Trigger(pName); _err != nil {
+		return _err
+	}
+
+	app.Write2LogConsole("Job "+pName+" is triggered", apptypes.LOG_INFO)
+	return nil
+}
+
+// Job_Pause pauses or resumes the scheduled runs of the job.
+//
+// Returns nil if successful. Unless error
+func (app *AgniApp) Job_Pause(pName string, pPause bool) error {
+
+	if app.scheduler == nil {
+		return errors.New("scheduler is not initialized")
+	}
+	if _err := app.scheduler.Pause(pName, pPause); _err != nil {
+		return _err
+	}
+
+	if pPause {
+		app.Write2LogConsole("Job "+pName+" is paused", apptypes.LOG_INFO)
+	} else {
+		app.Write2LogConsole("Job "+pName+" is resumed", apptypes.LOG_INFO)
+	}
+	return nil
+}
+
+// job_context returns the context of the unit instance for its job runs, so that a run is cancelled with the instance
+func (app *AgniApp) job_context(pOwner scheduler.IOwner) context.Context {
+	return app.Unit_Context(pOwner)
+}
+
+// remove_jobs removes the jobs of the unit instance removed from the pool
+func (app *AgniApp) remove_jobs(pOwner scheduler.IOwner) {
+
+	if app.scheduler != nil && pOwner != nil {
+		app.scheduler.Remove_Owner(pOwner)
+	}
+}
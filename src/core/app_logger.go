@@ -24,20 +24,27 @@
 // Ajith de Silva				29/01/2024	Added 		Added the Write2Log method with loglevel parameter
 // Ajith de Silva 				09/04/2024  Optimized   optimized the write log function
 // 														Added the log message broadcast to log function
+// Ajith de Silva				19/10/2026	Updated 	Masked the resolved secrets in the log entries
+// Ajith de Silva				19/10/2026	Updated 	Sent the log entries of the unit process to the framework
+// Ajith de Silva				19/10/2026	Added 		Added the Write2Log_Context method with the unit context prefix
 //#################################################################################################################
 
 package agni
 
 import (
 	aftypes "agnione/v1/src/appfm/types"
+	"context"
 	"fmt"
 	"time"
 
 	"agnione.appfm/src/logger"
+	"agnione.appfm/src/unitctx"
+	autls "agnione.appfm/src/utils"
 )
 
 // Write2Console writes the given entry into console
 func (app *AgniApp) Write2Console(pEntry string) {
+	pEntry = autls.Mask_Secrets(pEntry) /// resolved secrets never go to the console
 	go func ()  {
 		fmt.Println(pEntry)
 	}()
@@ -51,6 +58,14 @@ func (app *AgniApp) Set_LogLevel(log_level aftypes.LogLevel) {
 // Write2Log writes the given entry into the application log
 func (app *AgniApp) Write2Log(pEntry string, pLog_Level aftypes.LogLevel) {
 
+	pEntry = autls.Mask_Secrets(pEntry) /// resolved secrets never go to the log
+
+	/// unit process writes to the framework log
+	if app.unit_host != nil {
+		app.unit_host.Log(pEntry, pLog_Level)
+		return
+	}
+
 	go func ()  {
 		/// broadcast log entries to websocket endpoint	
 		if app.WSMonitor != nil && app.WSMonitor.IsStarted() {
@@ -68,6 +83,12 @@ func (app *AgniApp) Write2Log(pEntry string, pLog_Level aftypes.LogLevel) {
 		}()
 }
 
+// Write2Log_Context writes the given entry into the application log,
+// prefixed with the unit name, pool index & correlation id of the context
+func (app *AgniApp) Write2Log_Context(pCTX context.Context, pEntry string, pLog_Level aftypes.LogLevel) {
+	app.Write2Log(unitctx.Log_Prefix(pCTX) + pEntry, pLog_Level)
+}
+
 // Write2Log writes the given entry into the application log
 func (app *AgniApp) Write2LogConsole(pEntry string, pLog_Level aftypes.LogLevel) {
 	go func(){
@@ -0,0 +1,172 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Mailer Implementation
+//
+// Objective     :   Provide the mailer plugin instances to the units.
+//					Built-in spool mailer writes the messages as .eml files to the spool folder,
+//					so that units can be tested without a SMTP server. It is also the fallback of
+//					the mailer plugins that are not available or fail to send.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Spooled the messages of the unavailable or failed mailer plugins
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	apptypes "agnione/v1/src/appfm/types"
+
+	"agnione.appfm/src/afplugins/mailer/iamailer"
+	"agnione.appfm/src/pluginreg"
+)
+
+// SPOOL_MAILER define the mailer type of the built-in spool mailer
+const SPOOL_MAILER = "spool"
+
+// DEFAULT_MAIL_SPOOL define the default spool folder of the spool mailer, relative to base path
+const DEFAULT_MAIL_SPOOL = "spool/mail/"
+
+func init() {
+	pluginreg.Register("mailer", pluginreg.Hook_Of(func(pLib iamailer.IAMailer) any { return pLib.New() }))
+}
+
+// Get_Mailer returns a new instance of the mailer plugin of the given type configured in core.config.
+// Type "spool" returns the built-in spool mailer. Its folder can be set by the path of the spool mailer entry.
+// Spool mailer is the fallback of the mailer plugin: it is returned if the plugin is not available,
+// and writes the messages the plugin fails to send.
+//
+// Templates of the messages are rendered and the messages are validated before passing to the mailer.
+// Returns IAMailer,nil
+func (app *AgniApp) Get_Mailer(pType *string) (iamailer.IAMailer, error) {
+
+	_type := *pType
+	if len(_type) == 0 {
+		_type = pluginreg.DEFAULT_TYPE
+	}
+
+	_spool := app.spool_mailer()
+	if _type == SPOOL_MAILER {
+		return &template_mailer{IAMailer: _spool}, nil
+	}
+
+	_mailer, _err := pluginreg.Get[iamailer.IAMailer](app, "mailer", _type)
+	if _err != nil {
+		app.Write2Log("Mailer "+_type+" is not available. messages are written to the spool "+_spool.path+". "+_err.Error(), apptypes.LOG_WARN)
+		return &template_mailer{IAMailer: _spool}, nil
+	}
+
+	return &template_mailer{IAMailer: _mailer, mailer_type: _type, fallback: _spool, app: app}, nil
+}
+
+// spool_mailer returns the spool mailer on the folder of the spool mailer entry, or the default spool folder
+func (app *AgniApp) spool_mailer() *spool_mailer {
+
+	_path := *app.base_path + DEFAULT_MAIL_SPOOL
+	if _config, _err := app.plugin_config("mailer", SPOOL_MAILER); _err == nil && len(_config.Path) > 0 {
+		_path = _config.Path
+		if !filepath.IsAbs(_path) {
+			_path = *app.base_path + _path
+		}
+	}
+	return &spool_mailer{path: _path}
+}
+
+// template_mailer renders the templates and validates the message before passing to the mailer.
+// Messages the mailer fails to send are written by the fallback mailer, if set
+type template_mailer struct {
+	iamailer.IAMailer
+	mailer_type string
+	fallback    iamailer.IAMailer
+	app         *AgniApp
+}
+
+// Send renders the templates of the message, validates and sends it via the mailer
+func (tm *template_mailer) Send(pMessage *iamailer.MailMessage) error {
+
+	if pMessage == nil {
+		return errors.New("mail message is not given")
+	}
+
+	if _err := pMessage.Render(); _err != nil {
+		return _err
+	}
+
+	if _err := pMessage.Validate(); _err != nil {
+		return _err
+	}
+
+	_err := tm.IAMailer.Send(pMessage)
+	if _err == nil || tm.fallback == nil {
+		return _err
+	}
+
+	if _spool_err := tm.fallback.Send(pMessage); _spool_err != nil {
+		return errors.New(_err.Error() + ". failed to spool the message. " + _spool_err.Error())
+	}
+
+	tm.app.Write2Log("Mailer "+tm.mailer_type+" failed to send. message is written to the spool. "+_err.Error(), apptypes.LOG_WARN)
+	return nil
+}
+
+// spool_sequence makes the spool file names unique within the same nano second
+var spool_sequence atomic.Uint64
+
+// spool_mailer writes the messages as .eml files to the spool folder
+type spool_mailer struct {
+	path string
+}
+
+// New creates a new spool mailer instance on the same spool folder
+func (sm *spool_mailer) New() interface{} {
+	return &spool_mailer{path: sm.path}
+}
+
+// Send writes the message as <time>-<sequence>.eml to the spool folder
+func (sm *spool_mailer) Send(pMessage *iamailer.MailMessage) error {
+
+	_eml, _err := pMessage.EML()
+	if _err != nil {
+		return _err
+	}
+
+	if _err = os.MkdirAll(sm.path, 0755); _err != nil {
+		return errors.New("failed to create mail spool " + sm.path + ". " + _err.Error())
+	}
+
+	_name := filepath.Join(sm.path, time.Now().Format("20060102-150405.000000000")+"-"+
+		strconv.FormatUint(spool_sequence.Add(1), 10))
+
+	/// write & rename, so that spool readers never see a partial message
+	if _err = os.WriteFile(_name+".tmp", _eml, 0644); _err != nil {
+		return _err
+	}
+	return os.Rename(_name+".tmp", _name+".eml")
+}
+
+// Close does nothing for the spool mailer
+func (sm *spool_mailer) Close() error {
+	return nil
+}
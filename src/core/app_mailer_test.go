@@ -0,0 +1,109 @@
+package agni
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	apptypes "agnione/v1/src/appfm/types"
+
+	"agnione.appfm/src/afplugins/mailer/iamailer"
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/pluginreg"
+)
+
+// test_mailer is a mailer plugin of the Get_Mailer tests. Send fails if fail is set
+type test_mailer struct {
+	fail bool
+	sent *atomic.Int32
+}
+
+func (m *test_mailer) New() interface{} { return m }
+func (m *test_mailer) Close() error     { return nil }
+func (m *test_mailer) Send(pMessage *iamailer.MailMessage) error {
+	if m.fail {
+		return errors.New("smtp server is not reachable")
+	}
+	m.sent.Add(1)
+	return nil
+}
+
+// new_mailer_app returns a framework instance with the "ok" & "failing" mailer plugins & the spool in a temp folder
+func new_mailer_app(t *testing.T, pSent *atomic.Int32) (*AgniApp, string) {
+
+	RegisterPlugin("mailer", "ok_mailer_test", func() any { return &test_mailer{sent: pSent} })
+	RegisterPlugin("mailer", "failing_mailer_test", func() any { return &test_mailer{fail: true, sent: pSent} })
+
+	_base := t.TempDir() + "/"
+	_spool := filepath.Join(_base, "spool")
+	_core_ext := &fmtypes.FMConfigExt{Plugins: map[string][]fmtypes.PluginConfig{"mailer": {
+		{PlugIn: apptypes.PlugIn{Type: "ok", Enable: 1, Path: pluginreg.STATIC_PREFIX + "ok_mailer_test"}},
+		{PlugIn: apptypes.PlugIn{Type: "failing", Enable: 1, Path: pluginreg.STATIC_PREFIX + "failing_mailer_test"}},
+		{PlugIn: apptypes.PlugIn{Type: SPOOL_MAILER, Enable: 1, Path: _spool}},
+	}}}
+
+	return &AgniApp{
+		base_path:      &_base,
+		config_lock:    &sync.RWMutex{},
+		coreconfig_ext: _core_ext,
+		plugin_pools:   pluginreg.NewPools(),
+	}, _spool
+}
+
+func TestGet_Mailer(t *testing.T) {
+
+	_message := func() *iamailer.MailMessage {
+		return &iamailer.MailMessage{From: "app@example.com", To: []string{"ops@example.com"}, Subject: "Report",
+			Text_Template: "Processed {{.}} items", Template_Data: 10}
+	}
+
+	_cases := []struct {
+		name    string
+		type_   string
+		message *iamailer.MailMessage
+		sent    int32
+		spooled int
+		error   string
+	}{
+		{name: "sent", type_: "ok", message: _message(), sent: 1},
+		{name: "failed to send", type_: "failing", message: _message(), spooled: 1},
+		{name: "not available", type_: "missing", message: _message(), spooled: 1},
+		{name: "default type", type_: "", message: _message(), spooled: 1},
+		{name: "spool", type_: SPOOL_MAILER, message: _message(), spooled: 1},
+		{name: "invalid message", type_: "failing", message: &iamailer.MailMessage{From: "app"}, error: "invalid sender address app"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_sent := &atomic.Int32{}
+			_app, _spool := new_mailer_app(t, _sent)
+
+			_type := _case.type_
+			_mailer, _err := _app.Get_Mailer(&_type)
+			if _err != nil {
+				t.Fatal(_err)
+			}
+			if _type != _case.type_ {
+				t.Fatalf("type of the caller is changed to %q", _type)
+			}
+
+			_got := ""
+			if _err = _mailer.Send(_case.message); _err != nil {
+				_got = _err.Error()
+			}
+			if _got != _case.error {
+				t.Fatalf("expected error %q, got %q", _case.error, _got)
+			}
+
+			if _count := _sent.Load(); _count != _case.sent {
+				t.Fatalf("expected %d messages sent by the plugin, got %d", _case.sent, _count)
+			}
+			if _files, _ := filepath.Glob(filepath.Join(_spool, "*.eml")); len(_files) != _case.spooled {
+				t.Fatalf("expected %d spooled messages, got %v", _case.spooled, _files)
+			}
+		})
+	}
+}
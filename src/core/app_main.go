@@ -2,8 +2,10 @@
 //
 // This package includes functions:
 //	- Add_Routine
+//	- Call_Service
 //	- App_Path
 //	- DeInitialize
+//	- Get_Config_Content
 //	- Get_Context
 //	- Failed_Request_Count
 //	- Get_App_Info
@@ -13,31 +15,51 @@
 //	- Get_FileInfo
 //	- Get_AppUnit
 //	- Get_Mailer
+//	- Get_MQClient
+//	- Event_Stats
+//	- Get_Plugin
+//	- Acquire_Plugin
+//	- Release_Plugin
+//	- Plugin_Stats
 //	- Get_RESTClient
 //	- Get_WSClient
+//	- Go
 //	- Handled_Request_Count
+//	- Job_Pause
+//	- Job_Trigger
+//	- Jobs_List
 //	- Initialize
 //	- Is_Interrupted
 //	- Load_Units
 //	- Memory_Usage
 //	- Name
+//	- Publish_Event
 //	- Reload_Config
+//	- Register_Service
 //	- Reload_Requested
+//	- Schedule_Job
 //	- Remove_Routine
 //	- Add_Request_Failed_Count
 //	- Add_Request_HandleCount
 //	- Routine_Count
+//	- Routines_List
 //	- Send_Monitor_Message
+//	- Services_List
 //	- Start
 //	- StartHttpMonitor
 //	- Start_WSMonitor
 //	- Started
 //	- Stop
 //	- Stop_WSMonitor
+//	- Subscribe_Events
+//	- Unregister_Service
+//	- Unit_Context
+//	- Unschedule_Job
 //	- Version
 //	- WaitforClose
 //	- Write2Console
 //	- Write2Log
+//	- Write2Log_Context
 //	- WriteFileContent
 /*
 #########################################################################################
@@ -75,6 +97,30 @@ Objective     :   Define common package for Application that work as a container
 
 	Ajith de Silva		29/03/2024	Updated 	added the logger to application framework
 
+	Ajith de Silva		19/10/2026	Updated 	guarded the running application configuration replaced by the reload
+
+	Ajith de Silva		19/10/2026	Updated 	added the unit process isolation
+
+	Ajith de Silva		19/10/2026	Updated 	ended the exec & wasm units with the framework
+
+	Ajith de Silva		19/10/2026	Updated 	added the event bus shared by the units
+
+	Ajith de Silva		19/10/2026	Updated 	added the service registry shared by the units
+
+	Ajith de Silva		19/10/2026	Updated 	started the units by depends_on & stopped them in reverse order
+
+	Ajith de Silva		19/10/2026	Updated 	started the units & pool instances concurrently with startup timeouts
+
+	Ajith de Silva		19/10/2026	Added 		cron, fixed-rate & fixed-delay job scheduler of the units
+
+	Ajith de Silva		19/10/2026	Added 		managed routines of the units, cancelled on unit stop
+
+	Ajith de Silva		19/10/2026	Added 		context of each unit instance, cancelled on unit stop
+
+	Ajith de Silva		19/10/2026	Updated 	ran the jobs with the context of the unit instance
+
+	Ajith de Silva		19/10/2026	Updated 	ran the managed routines with the context of the unit instance
+
 #########################################################################################
 */
 package agni
@@ -88,15 +134,28 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"agnione/v1/src/aau/iappunit"
 	iappfw "agnione/v1/src/appfm/iappfw"
 	apptypes "agnione/v1/src/appfm/types"
 
+	"agnione.appfm/src/afplugins/mq/memmq"
+	"agnione.appfm/src/eventbus"
+	"agnione.appfm/src/execunit"
+	fmtypes "agnione.appfm/src/fmtypes"
 	"agnione.appfm/src/logger"
+	"agnione.appfm/src/pluginreg"
+	"agnione.appfm/src/routines"
+	"agnione.appfm/src/scheduler"
+	"agnione.appfm/src/servicereg"
+	"agnione.appfm/src/unitctx"
+	"agnione.appfm/src/unithost"
+	"agnione.appfm/src/wasmunit"
 	ihttpm "agnione.appfm/src/monitors/http"
 	iwsm "agnione.appfm/src/monitors/ws"
+	autls "agnione.appfm/src/utils"
 )
 
 // struct to hold the framework instance data
@@ -106,6 +165,9 @@ type AgniApp struct {
 	wgEntries    *sync.WaitGroup /// instance wait group for go routines
 	counter_lock *sync.RWMutex     /// sync lock for request handle counters
 	routine_lock *sync.RWMutex     /// sync lock for routine counter
+	units_lock *sync.RWMutex     /// sync lock for the unit pool
+	reload_lock *sync.Mutex     /// sync lock to serialize the configuration reloads
+	config_lock *sync.RWMutex     /// sync lock for the running application configuration
 	info_lock *sync.RWMutex     /// sync lock for info of application
 	status_lock *sync.RWMutex     /// sync lock for status of application
 	ctx *context.Context
@@ -113,9 +175,27 @@ type AgniApp struct {
 	HTTPMonitor *ihttpm.HttpMonitor
 	appconfig  *apptypes.AppConfig  /// pointer for application configuration
 	coreconfig  *apptypes.FMConfig  /// pointer for application configuration
+	coreconfig_ext *fmtypes.FMConfigExt /// pointer for framework only settings of core configuration
+	appconfig_ext *fmtypes.AppConfigExt /// pointer for framework only settings of application configuration
+	config_watcher *autls.FileWatcher /// watcher of the configuration files. nil if not enabled
+	mq_broker *memmq.Broker /// built-in in-memory message broker shared by the units
+	event_bus *eventbus.Bus /// in-process event bus shared by the units
+	services *servicereg.Registry /// request/reply services of the units
+	scheduler *scheduler.Scheduler /// scheduled jobs of the units
+	routines *routines.Registry /// managed routines of the units
+	plugin_pools *pluginreg.Pools /// plugin instance pools by category & type
+	unit_host *unithost.HostClient /// framework of the parent process. set only in the unit process of an isolated unit
 
 	logger     *logger.ALogger
 	appUnits         []iappunit.IAppUnit /// pool to hold the application units
+	appunit_names    []string /// unit name of each instance in the appUnits pool
+	unit_errors      map[string]string /// last load/start error of the units by unit name
+	unit_errors_lock *sync.Mutex /// sync lock for the unit errors, set by the concurrent unit startup
+	unit_sequence    atomic.Int32 /// index of the next unit instance
+	startup_slots    chan struct{} /// unit instances initialized & started at the same time
+	unit_contexts    map[unitctx.IOwner]*unit_context /// context of the unit instances
+	unit_contexts_lock *sync.Mutex /// sync lock for the unit contexts
+	released_contexts []unitctx.IOwner /// released unit instances with a kept context, the oldest first
 	
 	appunit_info []apptypes.AppUnitInfo
 	appinfo *apptypes.AppInfo
@@ -157,44 +237,12 @@ func (app *AgniApp) Initialize(pCTX_Current *context.Context, pOS_PID *int, pBas
 	pREST_Port *int, pWS_Port *int) (bool, error) {
 	
 	app.ctx=pCTX_Current
-	app.base_path = pBase_Path /// sets the base path
-	app.app_config = pApp_Config
 	app.id=pOS_PID
 
-	var _err error
-	_temp_path:=*pBase_Path + "config/core.config"
-	app.coreconfig, _err = app.LoadCoreConfiguration(&_temp_path) /// try to load the main configuration
-	if _err != nil {
-		return false,errors.New("main configuration file failed to load - " +  _err.Error())
-	}
-	
-	app.appconfig, _err = app.LoadAppConfiguration(pApp_Config) /// try to load the application configuration
-	if _err != nil {
-		fmt.Printf("application configuration file failed to load\n%v\n", _err)
-		return false, fmt.Errorf("main configuration file failed to load - %v", _err)
+	if _err := app.init_instance(pBase_Path, pApp_Config); _err != nil {
+		return false, _err
 	}
 
-	app.requests_handled = 0          /// init counter request_handled
-	app.requests_failed = 0           /// init counter requests_failed
-	app.started = time.Now()          /// set the started time to current date-time
-	
-	app.stopChan = make(chan bool)    /// init the stopper channel
-
-	
-	/// init the sync locks
-	app.wgEntries = &sync.WaitGroup{}
-	app.counter_lock = &sync.RWMutex{}
-	app.routine_lock = &sync.RWMutex{}
-	app.status_lock=&sync.RWMutex{}
-	app.info_lock=&sync.RWMutex{}
-	
-	app.appinfo=&apptypes.AppInfo{}
-	app.appstatus=&apptypes.AppStatus{}
-	
-	/// set the appication name and version
-	app.name = app.appconfig.App.Name
-	app.version = app.appconfig.App.Version
-
 	/// determine the log file base.
 	/// first priority to input variable by App shell
 	/// if not check with config file, override by the app.config then use it
@@ -221,8 +269,8 @@ func (app *AgniApp) Initialize(pCTX_Current *context.Context, pOS_PID *int, pBas
 	fmt.Println("Initializing the Logger with base path " +  app.logfile_base)
 
 	app.logger = &logger.ALogger{}
-	app.app_log_file = app.logfile_base + app.appconfig.App.ID + ".log"
-	_, _err = app.logger.Initialize(iappfw.IAgniApp(app), app.app_log_file, apptypes.LOG_INFO, os.Getpid())
+	app.app_log_file = app.logfile_base + app.running_config().App.ID + ".log"
+	_, _err := app.logger.Initialize(iappfw.IAgniApp(app), app.app_log_file, apptypes.LOG_INFO, os.Getpid())
 	if _err != nil {
 		fmt.Println("Failed to create the instance of Logger.\n " +  _err.Error())
 		app.logger = nil
@@ -242,6 +290,75 @@ func (app *AgniApp) Initialize(pCTX_Current *context.Context, pOS_PID *int, pBas
 	return true, nil
 }
 
+// init_instance loads the configurations and creates the shared resources of the framework instance.
+// Logger & monitors are not created. Used by Initialize and by the unit process.
+func (app *AgniApp) init_instance(pBase_Path *string, pApp_Config *string) error {
+
+	app.base_path = pBase_Path /// sets the base path
+	app.app_config = pApp_Config
+	app.config_lock = &sync.RWMutex{}
+
+	var _err error
+	autls.Init_Secrets(pBase_Path) /// secret references in the configuration are resolved on load
+	
+	_temp_path:=*pBase_Path + "config/core.config"
+	app.coreconfig, _err = app.LoadCoreConfiguration(&_temp_path) /// try to load the main configuration
+	if _err != nil {
+		return errors.New("main configuration file failed to load - " +  _err.Error())
+	}
+	
+	if app.coreconfig_ext, _err = autls.LoadCoreConfigurationExt(&_temp_path); _err != nil {
+		fmt.Println("framework settings of main configuration failed to load, using defaults - " +  _err.Error())
+		app.coreconfig_ext = &fmtypes.FMConfigExt{}
+	}
+	
+	app.appconfig, _err = app.LoadAppConfiguration(pApp_Config) /// try to load the application configuration
+	if _err != nil {
+		fmt.Printf("application configuration file failed to load\n%v\n", _err)
+		return fmt.Errorf("main configuration file failed to load - %v", _err)
+	}
+
+	if app.appconfig_ext, _err = autls.LoadAppConfigurationExt(pApp_Config); _err != nil {
+		fmt.Println("framework settings of application configuration failed to load, using defaults - " +  _err.Error())
+		app.appconfig_ext = &fmtypes.AppConfigExt{}
+	}
+
+	app.requests_handled = 0          /// init counter request_handled
+	app.requests_failed = 0           /// init counter requests_failed
+	app.started = time.Now()          /// set the started time to current date-time
+	
+	app.stopChan = make(chan bool)    /// init the stopper channel
+
+	
+	/// init the sync locks
+	app.wgEntries = &sync.WaitGroup{}
+	app.counter_lock = &sync.RWMutex{}
+	app.routine_lock = &sync.RWMutex{}
+	app.units_lock = &sync.RWMutex{}
+	app.reload_lock = &sync.Mutex{}
+	app.unit_errors_lock = &sync.Mutex{}
+	app.unit_contexts_lock = &sync.Mutex{}
+	app.unit_contexts = make(map[unitctx.IOwner]*unit_context)
+	app.status_lock=&sync.RWMutex{}
+	app.info_lock=&sync.RWMutex{}
+	
+	app.appinfo=&apptypes.AppInfo{}
+	app.appstatus=&apptypes.AppStatus{}
+	app.mq_broker = memmq.NewBroker(memmq.DEFAULT_QUEUE_SIZE)
+	app.init_event_bus()
+	app.services = servicereg.New()
+	app.scheduler = scheduler.New(app.job_context)
+	app.routines = routines.New(app.routine_event, app.routine_context)
+	app.startup_slots = make(chan struct{}, app.startup_concurrency())
+	app.plugin_pools = pluginreg.NewPools()
+	
+	/// set the appication name and version
+	app.name = app.appconfig.App.Name
+	app.version = app.appconfig.App.Version
+
+	return nil
+}
+
 // DeInitialize clear the initialize objects
 func (app *AgniApp) DeInitialize() {
 
@@ -273,8 +390,21 @@ func (app *AgniApp) DeInitialize() {
 	
 	/// clear all varaibales - 
 
-	app.appconfig = nil
-	app.coreconfig = nil
+	app.set_running_config(nil)
+	app.set_running_core_config(nil, nil)
+	app.set_running_config_ext(nil)
+	app.config_watcher = nil
+	app.mq_broker = nil
+	if app.event_bus != nil {
+		app.event_bus.Close()
+		app.event_bus = nil
+	}
+	app.services = nil
+	if app.scheduler != nil {
+		app.scheduler.Close()
+		app.scheduler = nil
+	}
+	app.routines = nil
 
 	app.HTTPMonitor = nil
 	app.WSMonitor = nil
@@ -285,7 +415,11 @@ func (app *AgniApp) DeInitialize() {
 	
 	app.counter_lock = nil
 	app.routine_lock = nil
+	app.units_lock = nil
+	app.reload_lock = nil
 	app.appUnits = nil
+	app.appunit_names = nil
+	app.unit_errors = nil
 	app.info_lock=nil
 	app.status_lock=nil
 	app.appunit_info=nil
@@ -307,19 +441,50 @@ func (app *AgniApp) Get_Context() *context.Context{
 }
 
 // Reload_Config reloads the application configuration
+//
+// Running configuration is compared with the new one and only the units whose path, config file,
+// pool size or enable flag changed are started, stopped or resized.
 // Returns true,nil if reload successful. Unless returns false,error
 func (app *AgniApp) Reload_Config() (bool, error) {
 
 	/* call loadAppConfiguration() and assign the result to appConfig*/
- 	_temp_path:=*app.app_config + "/app.config"
+ 	_temp_path:=app.app_config_file()
 	
-	if _newConfig, _err := app.LoadAppConfiguration(&_temp_path); _err != nil {
+	_newConfig, _err := app.LoadAppConfiguration(&_temp_path)
+	if _err != nil {
 		return false, _err
-	} else {
-		app.appconfig = _newConfig
-		app.reload_requested = true /// set reload is requested. will be used in routine to reload the app
-		return true, nil
 	}
+
+	if _err = app.Validate_App_Config(_newConfig); _err != nil {
+		app.Write2LogConsole("Configuration reload rejected. " + _err.Error(), apptypes.LOG_ERROR)
+		return false, _err
+	}
+
+	_newExt, _err := autls.LoadAppConfigurationExt(&_temp_path)
+	if _err != nil {
+		_newExt = &fmtypes.AppConfigExt{}
+	}
+
+	if _err = app.Validate_Unit_Requires(_newConfig, _newExt); _err != nil {
+		app.Write2LogConsole("Configuration reload rejected. " + _err.Error(), apptypes.LOG_ERROR)
+		return false, _err
+	}
+
+	app.reload_lock.Lock()
+	defer app.reload_lock.Unlock()
+
+	app.set_running_config_ext(_newExt) /// unit pins are used by the units started below
+	_changes := app.apply_config_diff(_newConfig)
+
+	if len(_changes) == 0 {
+		app.Write2LogConsole("Configuration reloaded. no unit changes found", apptypes.LOG_INFO)
+	}
+	
+	for _name, _change := range _changes {
+		app.Write2LogConsole("Configuration reloaded. unit " + _name + " -> " + string(_change), apptypes.LOG_INFO)
+	}
+
+	return true, nil
 }
 
 
@@ -339,7 +504,7 @@ func (app *AgniApp) Save_App_Config(pAppConfigData *[]byte) (bool, error) {
 		return false,errors.New("invalid application configuration received")
 	}
 	
-	_temp_path:=*app.app_config + "/app.config"
+	_temp_path:=app.app_config_file()
 	
 	if _,_err=app.Write_FileContent(&_temp_path,pAppConfigData);_err!=nil{
 		app.Write2Log("Failed to save " + _temp_path + ". " + _err.Error(),apptypes.LOG_ERROR)
@@ -393,6 +558,12 @@ func (app *AgniApp) Start() {
 	
 	app.Load_Units() /// loads the business model to run
 	
+	app.watch_config_apply() /// watch the unit startup, if started with a staged configuration
+	
+	app.start_config_watch() /// watch the configuration files for changes, if enabled
+	
+	app.start_config_source() /// poll the config source for changes, if enabled
+	
 	time.Sleep(time.Second * 1)
 	app.stopStatus = make(chan bool)    /// init the stopper channel for status reads
 		
@@ -433,24 +604,46 @@ func (app *AgniApp) Stop_Units() {
 		
 		app.Write2LogConsole("Found AppUnits " + strconv.Itoa(len(app.appUnits)) + " in pool", apptypes.LOG_INFO)
 		
-		for _index,_appUnit :=range app.appUnits {
-			if _appUnit!= nil {
+		app.units_lock.Lock()
+		defer app.units_lock.Unlock()
+		
+		/// dependents are stopped before the units they depend on
+		for _,_index :=range app.stop_order() {
+			if _appUnit:=app.appUnits[_index]; _appUnit!= nil {
+				app.remove_jobs(_appUnit) /// no job runs while the unit stops
+				app.cancel_unit_context(_appUnit)
 				if _appUnit.IsStarted() {
-					app.Write2LogConsole("AppUnit - [" + strconv.Itoa(_index) + "] Stop called" , apptypes.LOG_INFO)
+					app.Write2LogConsole("AppUnit - " + app.appunit_names[_index] + " [" + strconv.Itoa(_index) + "] Stop called" , apptypes.LOG_INFO)
 					_appUnit.Stop()
 					time.Sleep(time.Millisecond * 200)
 				}else{
 					app.Write2LogConsole("AppUnit - [" + strconv.Itoa(_index) + "] NOT Started", apptypes.LOG_INFO)
 				}
+				/// unit processes, exec & wasm units are ended with the framework
+				switch _process := _appUnit.(type) {
+				case *process_unit:
+					_process.Deinitialize()
+				case *execunit.Unit:
+					_process.Deinitialize()
+				case *wasmunit.Unit:
+					_process.Deinitialize()
+				}
+				app.release_instance(_appUnit)
 				_appUnit=nil
 				app.appUnits[_index]=nil
 			}
 		}
 		
 		clear(app.appunit_info)
+		app.appunit_names=nil
 	}else{
 		app.Write2LogConsole("No AppUnits loaded", apptypes.LOG_INFO)
 	}
+	
+	if app.plugin_pools != nil {
+		app.Write2LogConsole("Closing plugin instances", apptypes.LOG_INFO)
+		app.plugin_pools.Close_All()
+	}
 }
 
 func (app *AgniApp) Stop_Logger() {
@@ -493,20 +686,67 @@ func (app *AgniApp) Terminate() {
 func (app *AgniApp) Load_Units() {
 
 	app.appUnits = make([]iappunit.IAppUnit, 0) /// creates the pool of AppUnits
-	
-	var _unitIndex int=0
-	var _appUnit apptypes.Appunit
+	app.appunit_names = make([]string, 0)
+	app.unit_errors_lock.Lock()
+	app.unit_errors = make(map[string]string)
+	app.unit_errors_lock.Unlock()
+	app.unit_sequence.Store(0)
+
+	/// units are started after the units of their depends_on. other units are started at the same time
+	_config := app.running_config()
+	_order, _order_errors := app.start_order(_config)
+
+	_loading := make(map[string]*sync.WaitGroup) /// done when the load of the unit is completed
+	for _, _unitIndex := range _order {
+		_uname := _config.Appunits[_unitIndex].Uname
+		if _loading[_uname] == nil {
+			_loading[_uname] = &sync.WaitGroup{}
+		}
+		_loading[_uname].Add(1)
+	}
+
+	_wait := &sync.WaitGroup{}
 
-	for _unitIndex, _appUnit = range app.appconfig.Appunits {
+	for _, _unitIndex := range _order {
+		_appUnit := _config.Appunits[_unitIndex]
 
 		///if the app unit is disabled, log it and continue the the next
 		if _appUnit.Enable == 0 {
 			app.Write2LogConsole(strconv.Itoa(_unitIndex) + " unit " + _appUnit.Uname + " -> DISABLED", apptypes.LOG_WARN)
-		} else {
-			app.Load_AppUnit(&_unitIndex, &_appUnit)
+			_loading[_appUnit.Uname].Done()
+			continue
 		}
+
+		if _reason, _found := _order_errors[_appUnit.Uname]; _found {
+			app.set_unit_error(_appUnit.Uname, _reason)
+			app.Write2LogConsole("Failed to load AgniOne Unit " + _appUnit.Uname + " - " + _reason, apptypes.LOG_ERROR)
+			_loading[_appUnit.Uname].Done()
+			continue
+		}
+
+		_wait.Add(1)
+		go func(pUnitIndex int, pAppUnit apptypes.Appunit) {
+			defer _wait.Done()
+			defer _loading[pAppUnit.Uname].Done()
+
+			for _, _dependency := range app.depends_on(pAppUnit.Uname) {
+				if _loading[_dependency] != nil {
+					_loading[_dependency].Wait()
+				}
+			}
+
+			if _err := app.check_dependencies(pAppUnit.Uname); _err != nil {
+				app.set_unit_error(pAppUnit.Uname, _err.Error())
+				app.Write2LogConsole("Failed to load AgniOne Unit " + pAppUnit.Uname + " - " + _err.Error(), apptypes.LOG_ERROR)
+				return
+			}
+
+			app.Load_AppUnit(&pUnitIndex, &pAppUnit)
+		}(_unitIndex, _appUnit)
 	}
 
+	_wait.Wait()
+
 	if len(app.appUnits) == 0 {
 		app.appUnits = nil
 		app.Write2LogConsole("No AgniOne Units loaded", apptypes.LOG_WARN)
@@ -518,7 +758,8 @@ func (app *AgniApp) Load_Units() {
 	}
 }
 
-// / loads the Applcation Unit(s) Model that implemets the logic/code base on the business requirments
+// / loads the Applcation Unit(s) Model that implemets the logic/code base on the business requirments.
+// / Pool instances are initialized & started at the same time, limited by the startup concurrency
 func (app *AgniApp) Load_AppUnit(_unitIndex *int, appunit *apptypes.Appunit) *int8 {
 	
 	var _loaded_count int8 = 0
@@ -531,6 +772,7 @@ func (app *AgniApp) Load_AppUnit(_unitIndex *int, appunit *apptypes.Appunit) *in
 	}()
 	
 	if appunit.PoolSize == 0 {
+		app.set_unit_error(appunit.Uname, "pool size set to 0")
 		app.Write2LogConsole("Failed to load AgniOne Unit " +  appunit.Uname + " - pool size set to 0", apptypes.LOG_WARN)
 		return &_loaded_count
 	}
@@ -543,63 +785,45 @@ func (app *AgniApp) Load_AppUnit(_unitIndex *int, appunit *apptypes.Appunit) *in
 	app.Write2LogConsole("Found " + strconv.Itoa(int(appunit.PoolSize)) + " pool setting for appunit " + appunit.Uname, apptypes.LOG_INFO)
 	
 	var _pool_index int8
+	_wait := &sync.WaitGroup{}
+	_count_lock := &sync.Mutex{}
 	
 	/// loads the app unit into pool
 	for _pool_index = 0; _pool_index < appunit.PoolSize; _pool_index++ {
-		_appUnit, _err := app.Get_AppUnit(_unitIndex) /// Get the AgniOne Unit instance
 
-		if _err != nil {
-			
-			app.Write2LogConsole("Failed to load AgniOne " + appunit.Uname  + " - " + appunit.Path + ". " + _err.Error(), apptypes.LOG_ERROR)
-			return &_loaded_count
-		}
-		
-		app.Write2LogConsole("Loading the " + strconv.Itoa(int(_pool_index)) + " appunit of pool [" + strconv.Itoa(int(appunit.PoolSize)) +"] of " + appunit.Uname, apptypes.LOG_INFO)
-		
-		if _, _err = _appUnit.Initialize(app, len(app.appUnits), appunit.Uname,  appunit.Path,appunit.ConfigFile); _err != nil {
-			
-			app.Write2LogConsole(fmt.Sprintf("Failed to initialize " + strconv.Itoa(int(_pool_index) +1) + "instace of the appunit " + strconv.Itoa(int(appunit.PoolSize)) + 
-					" into pool of " + appunit.Uname + "\n" + _err.Error(),  appunit.PoolSize, appunit.Uname, _err), apptypes.LOG_ERROR)
-
-			_appUnit = nil
-			return &_loaded_count
-		}
-		
-		app.Write2LogConsole("Starting (" + strconv.Itoa(int(_pool_index)) + ") of [" + strconv.Itoa(int(appunit.PoolSize)) +"] - " + 
-			appunit.Uname + ".............\nAppunit info -> " + _appUnit.Info().BuildGoVersion, apptypes.LOG_INFO)
-		
-		if _, _err = _appUnit.Start(); _err != nil {
+		_wait.Add(1)
+		go func(pPool_Index int8) {
+			defer _wait.Done()
 
-			/// failed to start. clear the resources
-			app.Write2LogConsole("Failed to start (" + strconv.Itoa(int(_pool_index)) + ") of [" + strconv.Itoa(int(appunit.PoolSize)) +"] - " + appunit.Uname + ". " + _err.Error(), apptypes.LOG_ERROR)
+			app.startup_slots <- struct{}{}
+			defer func() { <-app.startup_slots }()
 
-			_appUnit.Deinitialize()
-			_appUnit = nil
-			continue
-		} else {
-			/// All good. store the started AppUnit in the pool
-			_loaded_count++
-			app.appUnits = append(app.appUnits, _appUnit)
-			
-			app.Write2LogConsole("Started ------ (" + strconv.Itoa(int(_pool_index)) + ") of [" + strconv.Itoa(int(appunit.PoolSize)) +"] - " + appunit.Uname + " successfully", apptypes.LOG_INFO)
-			
-		}
+			if app.load_instance(*_unitIndex, appunit, pPool_Index) {
+				_count_lock.Lock()
+				_loaded_count++
+				_count_lock.Unlock()
+			}
+		}(_pool_index)
 	}
 
+	_wait.Wait()
+
 	app.Write2LogConsole(
-		"Started\t" + strconv.Itoa(int(_loaded_count)) + " out of " + strconv.Itoa(len(app.appUnits)) + " pool of appunit " + appunit.Uname + "\n",apptypes.LOG_INFO)
+		"Started\t" + strconv.Itoa(int(_loaded_count)) + " out of " + strconv.Itoa(int(appunit.PoolSize)) + " pool of appunit " + appunit.Uname + "\n",apptypes.LOG_INFO)
 
 	return &_loaded_count
 }
 
 // Is_Interrupted returns if the applcation stop channel.
 // External package routines should check this for an interupption/stop request and stop accordingly.
+// It is closed only when the framework terminates. Units should use Unit_Context to stop with their instance.
 func (app *AgniApp) Is_Interrupted() chan bool {
 	return app.stopChan
 }
 
-// WaitforClose waits for all the routines close
+// WaitforClose waits for all the routines close. Managed routines are cancelled & waited up to ROUTINE_STOP_TIMEOUT
 func (app *AgniApp) WaitforClose() {
+	app.close_routines()
 	app.wgEntries.Wait()
 }
 
@@ -21,6 +21,7 @@
 // Ajith de Silva				26/01/2024	Created 	Created the initial version
 // Ajith de Silva				29/01/2024	Updated 	Defined functions with parameters & return values
 // Ajith de Silva				29/01/2024	Updated 	Updated the WSMonitor as library
+// Ajith de Silva				19/10/2026	Updated 	Broadcast the monitor messages of the unit process via the framework
 //#################################################################################################################
 //
 
@@ -57,6 +58,10 @@ import (
 // /admin/monitor/stop - stops the web socket monitoring. (if already started)
 //
 // /admin/config/reload - reloads application configuration
+//
+// /admin/config/apply - stages the posted application configuration and restarts with it. rolls back if units failed to start
+//
+// /admin/config/apply/status - returns the state/result of the configuration apply
 func (app *AgniApp) StartHttpMonitor() {
 	
 	defer func ()  {
@@ -186,6 +191,12 @@ func (app *AgniApp) broadcast_status() {
 // If the web socket monitoring is not started then this message will be discarded.
 func (app *AgniApp) Send_Monitor_Message(pMessage []byte) {
 
+	/// unit process broadcasts via the framework
+	if app.unit_host != nil {
+		app.unit_host.Monitor_Message(pMessage)
+		return
+	}
+
 	go func (pMessage []byte)  {
 		defer recover()
 		
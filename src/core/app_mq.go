@@ -0,0 +1,62 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Message Queue Implementation
+//
+// Objective     :   Provide the message queue plugin instances to the units.
+//					Built-in in-memory broker is shared by all the units of the framework instance.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"errors"
+
+	"agnione.appfm/src/afplugins/mq/iamqclient"
+	"agnione.appfm/src/afplugins/mq/memmq"
+	"agnione.appfm/src/pluginreg"
+)
+
+// MEMORY_MQ define the MQ type of the built-in in-memory broker
+const MEMORY_MQ = "memory"
+
+func init() {
+	pluginreg.Register("mq", pluginreg.Hook_Of(func(pLib iamqclient.IAMQClient) any { return pLib.New() }))
+}
+
+// Get_MQClient returns a new instance of the MQ client plugin of the given type configured in core.config.
+// Type "memory" returns a client of the built-in in-memory broker.
+//
+// Returns IAMQClient,nil if successful. Unless nil,error
+func (app *AgniApp) Get_MQClient(pType *string) (iamqclient.IAMQClient, error) {
+
+	if len(*pType) == 0 {
+		*pType = pluginreg.DEFAULT_TYPE
+	}
+
+	if *pType == MEMORY_MQ {
+		if app.mq_broker == nil {
+			return nil, errors.New("in-memory broker is not initialized")
+		}
+		return memmq.New(app.mq_broker), nil
+	}
+
+	return pluginreg.Get[iamqclient.IAMQClient](app, "mq", *pType)
+}
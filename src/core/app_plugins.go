@@ -26,14 +26,38 @@
 //	 	Ajith de Silva		29/01/2024	Updated 	Updated the WSMonitor as library
 //	 	Ajith de Silva		01/03/2024	Updated 	Added Get_AppUnit function to return the application unit instance
 //	 	Ajith de Silva		05/03/2024	Updated 	Added Get_Mailer function to return the mailer instance
+//	 	Ajith de Silva		19/10/2026	Updated 	Read the running configuration snapshot replaced by the reload
+//	 	Ajith de Silva		19/10/2026	Updated 	Masked the resolved secrets in Units_List
+//	 	Ajith de Silva		19/10/2026	Updated 	Read the core configuration snapshot replaced by the plugins reload
+//	 	Ajith de Silva		19/10/2026	Updated 	Added Get_Plugin to return the plugin of any category via pluginreg
+//	 	Ajith de Silva		19/10/2026	Updated 	Added the plugin instance pools
+//	 	Ajith de Silva		19/10/2026	Updated 	Pooled the instances of Get_Plugin & the legacy getters for the framework owner
+//	 	Ajith de Silva		19/10/2026	Updated 	Added Check_Plugins for the build compatibility check
+//	 	Ajith de Silva		19/10/2026	Updated 	Added the integrity verification of the plugin & unit files
+//	 	Ajith de Silva		19/10/2026	Updated 	Loaded the verified copy of the plugin & unit files
+//	 	Ajith de Silva		19/10/2026	Updated 	Added Plugins_Inventory of the installed & configured plugins
+//	 	Ajith de Silva		19/10/2026	Updated 	Added the plugin settings with per unit overrides
+//	 	Ajith de Silva		19/10/2026	Updated 	Added the resolve of the plugins required by the units
+//	 	Ajith de Silva		19/10/2026	Updated 	Resolved the static:// units & plugins from the static registry
+//	 	Ajith de Silva		19/10/2026	Updated 	Loaded the units with process isolation in the unit processes
+//	 	Ajith de Silva		19/10/2026	Updated 	Added the exec units
+//	 	Ajith de Silva		19/10/2026	Updated 	Added the WebAssembly units
+//	 	Ajith de Silva		19/10/2026	Updated 	Unit_Stop stops the unit, cascaded to its dependents if forced
+//	 	Ajith de Silva		19/10/2026	Updated 	Unit_Start & Unit_Restart load the unit again in the start order
 // #######################################################################################
 
 package agni
 
 import (
 	aap "agnione/v1/src/aau/iappunit" /// import the unit interface
+	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	ihttp "agnione/v1/src/afplugins/http/iahttpclient" /// import the http interface
@@ -42,7 +66,11 @@ import (
 
 	atypes "agnione/v1/src/appfm/types"
 
+	"agnione.appfm/src/execunit"
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/pluginreg"
 	zutls "agnione.appfm/src/utils"
+	"agnione.appfm/src/wasmunit"
 )
 
 func (app *AgniApp) Get_Plugin_Config(pPluginCategory string, pPlugins *[]atypes.PlugIn, pType *string) (*atypes.PlugIn,error) {
@@ -62,70 +90,518 @@ func (app *AgniApp) Get_Plugin_Config(pPluginCategory string, pPlugins *[]atypes
 
 
 
-func (app *AgniApp) Get_WSClient(pType *string) (iws.IAWSClient, error) {
+// init registers the interface assertion hooks of the plugin categories known to the framework
+func init() {
+	pluginreg.Register("http", pluginreg.Hook_Of(func(pLib ihttp.IAHTTPClient) any { return pLib.New() }))
+	pluginreg.Register("websocket", pluginreg.Hook_Of(func(pLib iws.IAWSClient) any { return pLib.New() }))
+}
 
-	if len(*pType)==0{
-		*pType="default"
+// plugin_config returns the enabled plugin configuration of the given category & type from core.config
+func (app *AgniApp) plugin_config(pCategory string, pType string) (*fmtypes.PluginConfig, error) {
+
+	_core_ext := app.running_core_config_ext()
+	if _core_ext == nil || _core_ext.Plugins == nil {
+		return nil, errors.New("plugin configuration is not initialized")
 	}
-	
-	_plugin_config,_err:=app.Get_Plugin_Config("websocket", &app.coreconfig.Plugins.Websocket,pType)
-	
-	defer func ()  {
-		_plugin_config=nil
-	}()
-	
-	if _err!=nil{
-		return nil,_err
+
+	_plugins, _found := _core_ext.Plugins[pCategory]
+	if !_found {
+		return nil, errors.New("Plugin category " + pCategory + " is NOT found")
 	}
-	
-	if _plugin_config.Enable != 1 {
-		return nil, errors.New("websocket plug-in is disabled")
+
+	for _index := range _plugins {
+		if strings.ToLower(_plugins[_index].Type) == pType {
+			if _plugins[_index].Enable == 1 {
+				return &_plugins[_index], nil
+			}
+			return nil, errors.New("Plugin " + pCategory + "(" + pType + ") is not enable")
+		}
 	}
 
-	_path:=*app.base_path + _plugin_config.Path + _plugin_config.Name
-	_iPlugIn, _err := zutls.Get_WSClientPlugIn(&_plugin_config.Ifname, &_path)
+	return nil, errors.New("Plugin " + pCategory + "(" + pType + ") is NOT found")
+}
+
+// Get_Plugin returns an idle or a new instance of the plugin of the given category & type configured in core.config.
+// Instance is taken from the plugin pool for the framework owner, as the caller is not known.
+// It is closed when the framework stops, unless it is returned before with Release_Plugin for reuse.
+// Use pluginreg.Get to get the instance as the category interface.
+//
+// Returns the plugin instance,nil if successful. Unless nil,error
+func (app *AgniApp) Get_Plugin(pCategory string, pType string) (any, error) {
+	return app.acquire_plugin(pluginreg.FRAMEWORK_OWNER, "", pCategory, pType)
+}
+
+// Acquire_Plugin returns an idle or a new instance of the plugin of the given category & type for the owner (unit name).
+// New instance is created via the hook registered for the category in pluginreg and configured with the "settings"
+// of the plugin entry, merged with the overrides of the owner in app.config.
+// Owners with overrides get the instances of their own pool, shown as <type>@<owner> in the plugin stats.
+// Instances of the owner are closed when the unit is stopped.
+// Use pluginreg.Acquire to get the instance as the category interface & Release_Plugin to return it for reuse.
+//
+// Returns the plugin instance,nil if successful. Unless nil,error
+func (app *AgniApp) Acquire_Plugin(pOwner string, pCategory string, pType string) (any, error) {
+
+	if len(pOwner) == 0 {
+		return nil, errors.New("unit name is required to acquire a pooled plugin instance. use Get_Plugin without it")
+	}
+	return app.acquire_plugin(pOwner, pOwner, pCategory, pType)
+}
+
+// acquire_plugin returns an instance of the plugin from its pool for the owner.
+// Settings are merged with the overrides of the given unit. Unit is empty for the framework owner
+func (app *AgniApp) acquire_plugin(pOwner string, pUname string, pCategory string, pType string) (any, error) {
+
+	_pool_type, _plugin_config, _factory, _err := app.plugin_factory(pUname, pCategory, pType)
 	if _err != nil {
 		return nil, _err
-	}else{
-		return _iPlugIn, nil
 	}
+
+	_pool := app.plugin_pools.Pool(strings.ToLower(pCategory), _pool_type, _plugin_config.Max_Instances,
+		_plugin_config.Max_Idle, _factory)
+
+	return _pool.Acquire(pOwner)
 }
 
-func (app *AgniApp) Get_RESTClient(pType *string) (ihttp.IAHTTPClient, error) {
+// plugin_factory returns the pool type, the configuration & the factory of the instances of the plugin of the
+// given category & type for the unit. Unit is empty for the instances without the overrides of an unit.
+// Returns the pool type,configuration,factory,nil if successful. Unless error
+func (app *AgniApp) plugin_factory(pOwner string, pCategory string, pType string) (string, *fmtypes.PluginConfig,
+	pluginreg.Factory, error) {
 
-	if len(*pType)==0{
-		*pType="default"
+	pCategory = strings.ToLower(pCategory)
+	pType = strings.ToLower(pType)
+	if len(pType) == 0 {
+		pType = pluginreg.DEFAULT_TYPE
 	}
-	
-	_plugin_config,_err:=app.Get_Plugin_Config("http", &app.coreconfig.Plugins.HTTP,pType)
-	
-	defer func ()  {
-		_plugin_config=nil
-	}()
-	
-	if _err!=nil{
-		return nil,_err
+
+	_plugin_config, _err := app.plugin_config(pCategory, pType)
+	if _err != nil {
+		return "", nil, nil, _err
 	}
-	
-	if _plugin_config.Enable != 1 {
-		return nil, errors.New("http plug-in is disabled")
+
+	_ifname := _plugin_config.Ifname
+	_path := app.plugin_path(_plugin_config)
+	_static := pluginreg.Is_Static(_path)
+
+	_name := "plugin " + pCategory + "(" + pType + ")"
+	_verify := app.plugin_verifier(_name, _plugin_config.SHA256, _plugin_config.Signature)
+
+	_pool_type := pType
+	_overrides := app.unit_ext(pOwner).Plugins[pCategory][pType]
+	if len(pOwner) > 0 && _overrides != nil {
+		_pool_type = pType + "@" + pOwner
+	}
+	_settings := pluginreg.Merge_Settings(_plugin_config.Settings, _overrides)
+
+	_factory := func() (any, error) {
+		var _instance any
+		var _err error
+
+		/// compiled-in plugins are created by the registered factory, without plugin.Open
+		if _static {
+			_instance, _err = static_instance(pCategory, static_name(_path, pType), _path)
+		} else {
+			_instance, _err = zutls.Get_PlugIn(&_ifname, &_path, pluginreg.Hook_For(pCategory), _verify)
+		}
+		if _err != nil {
+			return nil, _err
+		}
+
+		/// every instance gets its own copy of the settings
+		_configured, _err := pluginreg.Configure(_instance, pluginreg.Merge_Settings(_settings, nil))
+		if _err != nil {
+			return nil, errors.New(_name + " settings are rejected. " + _err.Error())
+		}
+		if !_configured && len(_settings) > 0 {
+			app.Write2LogConsole(_name + " does not implement Configure. settings are ignored", atypes.LOG_WARN)
+		}
+		return _instance, nil
+	}
+
+	return _pool_type, _plugin_config, _factory, nil
+}
+
+// plugin_verifier returns the integrity check of a plugin/unit file, run before the file is opened.
+// File is checked against the sha256 pin & the signature file with the trusted keys of the config directory.
+// The check returns the verified copy of the file to open. Refused files are logged as security events.
+func (app *AgniApp) plugin_verifier(pName string, pSHA256 string, pSignature string) func(*string) (string, error) {
+
+	return func(pFileName *string) (string, error) {
+
+		_policy := fmtypes.PluginIntegrity{}
+		if _core_ext := app.running_core_config_ext(); _core_ext != nil {
+			_policy = _core_ext.Core.Plugin_Integrity
+		}
+
+		_keys_dir := _policy.Trusted_Keys
+		if len(_keys_dir) == 0 {
+			_keys_dir = zutls.DEFAULT_TRUSTED_KEYS
+		}
+		if !filepath.IsAbs(_keys_dir) {
+			_keys_dir = *app.base_path + _keys_dir
+		}
+
+		_signature := pSignature
+		if len(_signature) == 0 {
+			_signature = *pFileName + zutls.SIGNATURE_EXT
+		} else if !filepath.IsAbs(_signature) {
+			_signature = *app.base_path + _signature
+		}
+
+		_file := ""
+		_keys, _err := zutls.Load_Trusted_Keys(&_keys_dir)
+		if _err == nil {
+			_file, _err = zutls.Verify_Plugin_File(pFileName, pSHA256, _signature, _keys, &_policy)
+		}
+
+		if _err != nil {
+			app.Write2LogConsole("SECURITY " + pName + " refused to load " + *pFileName + ". " + _err.Error(), atypes.LOG_ERROR)
+		}
+		return _file, _err
+	}
+}
+
+// unit_ext returns the framework only settings of the given unit from app.config
+func (app *AgniApp) unit_ext(pUname string) fmtypes.AppunitExt {
+
+	if _config_ext := app.running_config_ext(); _config_ext != nil {
+		for _, _unit := range _config_ext.Appunits {
+			if _unit.Uname == pUname {
+				return _unit
+			}
+		}
+	}
+	return fmtypes.AppunitExt{Uname: pUname}
+}
+
+// resolve_requires acquires the plugins declared as "requires" of the unit in app.config, for an unit instance.
+// Returns the instances keyed by pluginreg.Plugin_Key. Unless nil,error with the acquired instances released
+func (app *AgniApp) resolve_requires(pUname string) (map[string]any, error) {
+
+	_plugins := make(map[string]any)
+
+	for _, _require := range app.unit_ext(pUname).Requires {
+		_key := pluginreg.Plugin_Key(_require.Category, _require.Type)
+
+		_instance, _err := app.Acquire_Plugin(pUname, _require.Category, _require.Type)
+		if _err != nil {
+			app.release_plugins(_plugins)
+			return nil, errors.New("required plugin " + _key + " is not available. " + _err.Error())
+		}
+		_plugins[_key] = _instance
+	}
+
+	return _plugins, nil
+}
+
+// new_unit_instance creates an instance of the unit of the given index with the required plugins injected.
+// Returns the unit & the injected plugins, released by the caller if the unit fails to start.
+// Required plugins of the isolated units are resolved by the unit process.
+func (app *AgniApp) new_unit_instance(pAppUnit *int) (aap.IAppUnit, map[string]any, error) {
+
+	_uname := app.running_config().Appunits[*pAppUnit].Uname
+
+	if app.is_process_unit(_uname) {
+		_unit, _err := app.Get_AppUnit(pAppUnit)
+		if _err != nil {
+			return nil, nil, errors.New("failed to load. " + _err.Error())
+		}
+		return _unit, nil, nil
+	}
+
+	/// plugins required by the unit should exist, be enabled & load before the unit is initialized
+	_plugins, _err := app.resolve_requires(_uname)
+	if _err != nil {
+		return nil, nil, errors.New("failed to load. " + _err.Error())
+	}
+
+	_unit, _err := app.Get_AppUnit(pAppUnit)
+	if _err != nil {
+		app.release_plugins(_plugins)
+		return nil, nil, errors.New("failed to load. " + _err.Error())
+	}
+
+	/// units without Inject_Plugins acquire the plugins by themselves. validated instances are kept idle for them
+	if _injectable, _ok := _unit.(pluginreg.IPluginInjectable); _ok && len(_plugins) > 0 {
+		if _err = _injectable.Inject_Plugins(_plugins); _err != nil {
+			app.release_plugins(_plugins)
+			return nil, nil, errors.New("failed to inject the plugins. " + _err.Error())
+		}
+		return _unit, _plugins, nil
+	}
+
+	app.release_plugins(_plugins)
+	return _unit, nil, nil
+}
+
+// release_plugins returns the given plugin instances to the plugin pools
+func (app *AgniApp) release_plugins(pPlugins map[string]any) {
+	for _, _instance := range pPlugins {
+		app.Release_Plugin(_instance)
+	}
+}
+
+// close_plugins closes the plugin instances acquired by the unit, when the unit is stopped
+func (app *AgniApp) close_plugins(pUname string) {
+
+	if app.plugin_pools == nil {
+		return
+	}
+	if _closed := app.plugin_pools.Close_Owner(pUname); _closed > 0 {
+		app.Write2LogConsole("AppUnit - "+pUname+" closed "+strconv.Itoa(_closed)+" plugin instances", atypes.LOG_INFO)
+	}
+}
+
+// Release_Plugin returns the plugin instance to the plugin pool for reuse
+func (app *AgniApp) Release_Plugin(pInstance any) error {
+	return app.plugin_pools.Release(pInstance)
+}
+
+// Plugin_Stats returns the instance counts of the plugins
+func (app *AgniApp) Plugin_Stats() []fmtypes.PluginPoolStats {
+	if app.plugin_pools == nil {
+		return nil
+	}
+	return app.plugin_pools.Stats()
+}
+
+// Check_Plugins checks the build compatibility of the configured plugins & units with the framework.
+// Units are reported with category "unit" and the unit name as type.
+func (app *AgniApp) Check_Plugins() []fmtypes.PluginABIReport {
+
+	_reports := make([]fmtypes.PluginABIReport, 0)
+
+	_check := func(pCategory string, pType string, pFileName string) {
+		_report := &fmtypes.PluginABIReport{File: pFileName, Mismatches: make([]fmtypes.PluginABIMismatch, 0)}
+		if pluginreg.Is_Static(pFileName) {
+			/// compiled into the framework binary
+			_, _found := pluginreg.Static(pCategory, static_name(pFileName, pType))
+			_report.Checked, _report.Compatible = _found, _found
+			_report.Go_Version, _report.Host_Go_Version = runtime.Version(), runtime.Version()
+			if !_found {
+				_report.Error = "static " + pCategory + " " + static_name(pFileName, pType) + " is not registered"
+			}
+		} else if zutls.IsFileExist(&pFileName) {
+			_report = zutls.Check_Plugin_ABI(&pFileName)
+		} else {
+			_report.Error = "file is not found"
+		}
+		_report.Category, _report.Type = pCategory, pType
+		_reports = append(_reports, *_report)
+	}
+
+	if _core_ext := app.running_core_config_ext(); _core_ext != nil {
+		_categories := make([]string, 0, len(_core_ext.Plugins))
+		for _category := range _core_ext.Plugins {
+			_categories = append(_categories, _category)
+		}
+		sort.Strings(_categories)
+
+		for _, _category := range _categories {
+			for _, _plugin := range _core_ext.Plugins[_category] {
+				_check(_category, _plugin.Type, app.plugin_path(&_plugin))
+			}
+		}
+	}
+
+	if _config := app.running_config(); _config != nil {
+		for _, _unit := range _config.Appunits {
+			/// exec & wasm units are not Go plugins
+			if _type := app.unit_ext(_unit.Uname).Type; _type == execunit.UNIT_TYPE || _type == wasmunit.UNIT_TYPE {
+				continue
+			}
+			_check(pluginreg.UNIT_CATEGORY, _unit.Uname, _unit.Path)
+		}
+	}
+
+	return _reports
+}
+
+// PLUGINS_DIR define the plugins tree scanned for the plugin inventory. relative to base path
+const PLUGINS_DIR string = "plugins/"
+
+// Plugins_Inventory returns the plugins configured in core.config, the units of app.config (category "unit")
+// and the other .so files found in the plugins tree, with their build metadata, exported symbols & load status.
+// Files found only in the plugins tree are reported with the first folder under plugins/ as category.
+func (app *AgniApp) Plugins_Inventory() []fmtypes.PluginInventory {
+
+	_inventory := make([]fmtypes.PluginInventory, 0)
+	_listed := make(map[string]bool)
+
+	_add := func(pCategory string, pType string, pFileName string, pIfname string, pConfigured bool, pEnabled bool) {
+
+		_entry := fmtypes.PluginInventory{Category: pCategory, Type: pType, Path: pFileName, Ifname: pIfname,
+			Configured: pConfigured, Enabled: pEnabled, Installed: zutls.IsFileExist(&pFileName),
+			Exports: make([]string, 0), PluginLoadStats: zutls.Plugin_Load_Stats(&pFileName)}
+
+		_static := pluginreg.Is_Static(pFileName)
+		_file_error := ""
+		if _static {
+			/// compiled into the framework binary. no symbols to check
+			_, _entry.Installed = pluginreg.Static(pCategory, static_name(pFileName, pType))
+			_entry.Go_Version = runtime.Version()
+		} else if _entry.Installed {
+			_info := zutls.Read_Plugin_File(&pFileName)
+			_entry.Module, _entry.Go_Version, _entry.Exports = _info.Module, _info.Go_Version, _info.Exports
+			_file_error = _info.Error
+		}
+
+		switch {
+		case !_entry.Installed:
+			_entry.Status = fmtypes.PLUGIN_MISSING
+			if len(_entry.Last_Error) == 0 {
+				_entry.Last_Error = "file is not found"
+			}
+		case len(_entry.Last_Error) > 0:
+			_entry.Status = fmtypes.PLUGIN_FAILED
+		case _entry.Load_Count > 0:
+			_entry.Status = fmtypes.PLUGIN_LOADED
+		case pConfigured && !_static && len(_file_error) == 0 && !slices.Contains(_entry.Exports, pIfname):
+			_entry.Status = fmtypes.PLUGIN_INVALID
+			_entry.Last_Error = "symbol " + pIfname + " is not exported"
+		default:
+			_entry.Status = fmtypes.PLUGIN_NOT_LOADED
+			_entry.Last_Error = _file_error
+		}
+
+		_listed[abs_path(pFileName)] = true
+		_inventory = append(_inventory, _entry)
+	}
+
+	if _core_ext := app.running_core_config_ext(); _core_ext != nil {
+		_categories := make([]string, 0, len(_core_ext.Plugins))
+		for _category := range _core_ext.Plugins {
+			_categories = append(_categories, _category)
+		}
+		sort.Strings(_categories)
+
+		for _, _category := range _categories {
+			for _, _plugin := range _core_ext.Plugins[_category] {
+				_add(_category, _plugin.Type, app.plugin_path(&_plugin), _plugin.Ifname, true, _plugin.Enable == 1)
+			}
+		}
+	}
+
+	if _config := app.running_config(); _config != nil {
+		for _, _unit := range _config.Appunits {
+			if _type := app.unit_ext(_unit.Uname).Type; _type == execunit.UNIT_TYPE || _type == wasmunit.UNIT_TYPE {
+				_inventory = append(_inventory, exec_inventory(&_unit, _type))
+				_listed[abs_path(_unit.Path)] = true
+				continue
+			}
+			_add(pluginreg.UNIT_CATEGORY, _unit.Uname, _unit.Path, "IAppUnit", true, _unit.Enable == 1)
+		}
+	}
+
+	_plugins_dir := *app.base_path + PLUGINS_DIR
+	_files, _err := zutls.Scan_Plugins(&_plugins_dir)
+	if _err != nil {
+		app.Write2LogConsole("Failed to scan " + _plugins_dir + ". " + _err.Error(), atypes.LOG_WARN)
+	}
+
+	for _, _file := range _files {
+		if _listed[abs_path(_file)] {
+			continue
+		}
+
+		_category := ""
+		if _relative, _err := filepath.Rel(_plugins_dir, _file); _err == nil {
+			if _folder, _, _found := strings.Cut(filepath.ToSlash(_relative), "/"); _found {
+				_category = _folder
+			}
+		}
+		_add(_category, "", _file, "", false, false)
+	}
+
+	return _inventory
+}
+
+// exec_inventory returns the inventory entry of the exec or wasm unit. File is not read for Go build metadata
+func exec_inventory(pUnit *atypes.Appunit, pType string) fmtypes.PluginInventory {
+
+	_entry := fmtypes.PluginInventory{Category: pluginreg.UNIT_CATEGORY, Type: pUnit.Uname, Path: pUnit.Path,
+		Ifname: pType, Configured: true, Enabled: pUnit.Enable == 1, Installed: zutls.IsFileExist(&pUnit.Path),
+		Exports: make([]string, 0), PluginLoadStats: zutls.Plugin_Load_Stats(&pUnit.Path)}
+
+	switch {
+	case !_entry.Installed:
+		_entry.Status = fmtypes.PLUGIN_MISSING
+		if len(_entry.Last_Error) == 0 {
+			_entry.Last_Error = "file is not found"
+		}
+	case len(_entry.Last_Error) > 0:
+		_entry.Status = fmtypes.PLUGIN_FAILED
+	case _entry.Load_Count > 0:
+		_entry.Status = fmtypes.PLUGIN_LOADED
+	default:
+		_entry.Status = fmtypes.PLUGIN_NOT_LOADED
+	}
+	return _entry
+}
+
+// Get_WSClient returns a websocket client from the plugin pool, as Get_Plugin does.
+// Release it with Release_Plugin for reuse
+func (app *AgniApp) Get_WSClient(pType *string) (iws.IAWSClient, error) {
+
+	_type := *pType
+	if len(_type)==0{
+		_type=pluginreg.DEFAULT_TYPE
 	}
 	
-	_path:=*app.base_path + _plugin_config.Path +  _plugin_config.Name
-	if _iPlugIn, _err := zutls.Get_HTTPClientPlugIn(&_plugin_config.Ifname, &_path); _err != nil {
-		return nil, _err
-	} else {
-		return _iPlugIn, nil
+	return pluginreg.Get[iws.IAWSClient](app, "websocket", _type)
+}
+
+// Get_RESTClient returns a http client from the plugin pool, as Get_Plugin does.
+// Release it with Release_Plugin for reuse
+func (app *AgniApp) Get_RESTClient(pType *string) (ihttp.IAHTTPClient, error) {
+
+	_type := *pType
+	if len(_type)==0{
+		_type=pluginreg.DEFAULT_TYPE
 	}
+	
+	return pluginreg.Get[ihttp.IAHTTPClient](app, "http", _type)
 }
 
 func (app *AgniApp) Get_AppUnit(pAppUnit *int) (aap.IAppUnit, error) {
 	
-	if app.appconfig.Appunits[*pAppUnit].Enable != 1 {
+	_appunit := app.running_config().Appunits[*pAppUnit]
+
+	if _appunit.Enable != 1 {
 		return nil, fmt.Errorf("http plug-in is disabled")
 	}
 
-	_iPlugIn, _err := zutls.Get_AppUnit(&app.appconfig.Appunits[*pAppUnit].Path)
+	_unit := app.unit_ext(_appunit.Uname)
+
+	/// isolated units are loaded by the unit process. the unit process loads the unit in-process
+	if len(_unit.Isolation) > 0 && app.unit_host == nil {
+		if _unit.Isolation != ISOLATION_PROCESS {
+			return nil, errors.New("unsupported isolation " + _unit.Isolation)
+		}
+		return new_process_unit(app, _unit), nil
+	}
+
+	_verify := app.plugin_verifier("unit " + _unit.Uname, _unit.SHA256, _unit.Signature)
+
+	/// exec units run the path as an executable, wasm units load the path as a WebAssembly module.
+	/// the file is verified before it is started or loaded
+	switch _unit.Type {
+	case "":
+	case execunit.UNIT_TYPE:
+		return execunit.New(_appunit.Path, _unit.Args, _unit.Env, _verify), nil
+	case wasmunit.UNIT_TYPE:
+		return wasmunit.New(_appunit.Path,
+			wasmunit.Settings{Allow_HTTP: _unit.Allow_HTTP, Tick_Interval: _unit.Tick_Interval}, _verify), nil
+	default:
+		return nil, errors.New("unsupported unit type " + _unit.Type)
+	}
+
+	/// compiled-in units are created by the registered factory, without plugin.Open
+	if pluginreg.Is_Static(_appunit.Path) {
+		return static_unit(_appunit.Path)
+	}
+
+	_iPlugIn, _err := zutls.Get_AppUnit(&_appunit.Path, _verify)
 	if _err != nil {
 		return nil, _err
 	}
@@ -135,25 +611,67 @@ func (app *AgniApp) Get_AppUnit(pAppUnit *int) (aap.IAppUnit, error) {
 
 func (app *AgniApp) Units_List() ([]atypes.Appunit, error){
 
-	if app.appconfig==nil{
+	_config := app.running_config()
+	if _config==nil{
 		return nil,errors.New("application configuration is not initialized")
 	}
 	
-	return app.appconfig.Appunits,nil
+	/// resolved secrets are masked in the returned copy
+	_data, _err := json.Marshal(_config.Appunits)
+	if _err != nil {
+		return nil, _err
+	}
+
+	_units := make([]atypes.Appunit, 0)
+	if _err = json.Unmarshal([]byte(zutls.Mask_Secrets(string(_data))), &_units); _err != nil {
+		return nil, _err
+	}
+
+	return _units,nil
 }
 
+// Unit_Stop stops the instances of the given unit. Instances are kept in the pool.
+// Unit is not stopped while units depending on it are started, unless pForce that stops the dependents first.
 func (app *AgniApp) Unit_Stop(pUnitName *string,pForce bool)(bool,error){
 
+	if _err := app.stop_unit_cascade(*pUnitName, pForce); _err != nil {
+		return false, _err
+	}
 	return true,nil
 }
 
+// Unit_Start starts the stopped unit. Stopped instances are released and the pool is loaded again,
+// after the depends_on units of the unit are checked to be started.
 func (app *AgniApp) Unit_Start(pUnitName *string)(bool,error){
 
+	if app.is_unit_started(*pUnitName) {
+		return false, errors.New("unit " + *pUnitName + " is already started")
+	}
+
+	if _err := start_errors(app.start_units([]string{*pUnitName})); _err != nil {
+		return false, _err
+	}
 	return true,nil
 }
 
+// Unit_Restart stops the unit and loads it again. With pForce, started units depending on it are stopped first
+// and started again after it, in the start order. Unit is not restarted while its dependents are started, unless pForce.
 func (app *AgniApp) Unit_Restart(pUnitName *string,pForce bool)(bool,error){
 
+	_units := []string{*pUnitName}
+	for _, _dependent := range app.unit_dependents(*pUnitName) {
+		if app.is_unit_started(_dependent) {
+			_units = append(_units, _dependent)
+		}
+	}
+
+	if _err := app.stop_unit_cascade(*pUnitName, pForce); _err != nil {
+		return false, _err
+	}
+
+	if _err := start_errors(app.start_units(_units)); _err != nil {
+		return false, _err
+	}
 	return true,nil
 }
 
@@ -0,0 +1,92 @@
+package agni
+
+import (
+	"sync"
+	"testing"
+
+	apptypes "agnione/v1/src/appfm/types"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/pluginreg"
+)
+
+// new_plugins_app returns a framework instance with the given plugins of core.config & framework settings of the units
+func new_plugins_app(pPlugins map[string][]fmtypes.PluginConfig, pUnits []fmtypes.AppunitExt) *AgniApp {
+
+	_base := "/"
+	return &AgniApp{
+		base_path:      &_base,
+		config_lock:    &sync.RWMutex{},
+		coreconfig_ext: &fmtypes.FMConfigExt{Plugins: pPlugins},
+		appconfig_ext:  &fmtypes.AppConfigExt{Appunits: pUnits},
+		plugin_pools:   pluginreg.NewPools(),
+	}
+}
+
+func TestValidate_Unit_Requires(t *testing.T) {
+
+	_app := new_plugins_app(map[string][]fmtypes.PluginConfig{
+		"http": {{PlugIn: apptypes.PlugIn{Type: "default", Enable: 1}}},
+		"mq":   {{PlugIn: apptypes.PlugIn{Type: "kafka", Enable: 0}}},
+	}, nil)
+
+	_cases := []struct {
+		name     string
+		enable   int8
+		requires []fmtypes.PluginRequirement
+		error    string
+	}{
+		{name: "no requires", enable: 1},
+		{name: "default type", enable: 1, requires: []fmtypes.PluginRequirement{{Category: "HTTP"}}},
+		{name: "type", enable: 1, requires: []fmtypes.PluginRequirement{{Category: "http", Type: "Default"}}},
+		{name: "unknown category", enable: 1, requires: []fmtypes.PluginRequirement{{Category: "http"}, {Category: "mailer"}},
+			error: "unit api requires mailer/default. Plugin category mailer is NOT found"},
+		{name: "unknown type", enable: 1, requires: []fmtypes.PluginRequirement{{Category: "http", Type: "fast"}},
+			error: "unit api requires http/fast. Plugin http(fast) is NOT found"},
+		{name: "disabled plugin", enable: 1, requires: []fmtypes.PluginRequirement{{Category: "mq", Type: "kafka"}},
+			error: "unit api requires mq/kafka. Plugin mq(kafka) is not enable"},
+		{name: "disabled unit", enable: 0, requires: []fmtypes.PluginRequirement{{Category: "mailer"}}},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_config := &apptypes.AppConfig{Appunits: []apptypes.Appunit{{Uname: "api", Enable: _case.enable}}}
+			_config_ext := &fmtypes.AppConfigExt{Appunits: []fmtypes.AppunitExt{{Uname: "api", Requires: _case.requires}}}
+
+			_got := ""
+			if _err := _app.Validate_Unit_Requires(_config, _config_ext); _err != nil {
+				_got = _err.Error()
+			}
+			if _got != _case.error {
+				t.Fatalf("expected error %q, got %q", _case.error, _got)
+			}
+		})
+	}
+}
+
+func TestResolve_Requires_Errors(t *testing.T) {
+
+	_app := new_plugins_app(map[string][]fmtypes.PluginConfig{
+		"mq": {{PlugIn: apptypes.PlugIn{Type: "kafka", Enable: 0}}},
+	}, []fmtypes.AppunitExt{
+		{Uname: "none"},
+		{Uname: "disabled", Requires: []fmtypes.PluginRequirement{{Category: "mq", Type: "kafka"}}},
+		{Uname: "missing", Requires: []fmtypes.PluginRequirement{{Category: "mailer"}}},
+	})
+
+	if _plugins, _err := _app.resolve_requires("none"); _err != nil || len(_plugins) != 0 {
+		t.Fatalf("expected no plugins for the unit without requires, got %v %v", _plugins, _err)
+	}
+
+	_cases := map[string]string{
+		"disabled": "required plugin mq/kafka is not available. Plugin mq(kafka) is not enable",
+		"missing":  "required plugin mailer/default is not available. Plugin category mailer is NOT found",
+	}
+
+	for _uname, _error := range _cases {
+		if _plugins, _err := _app.resolve_requires(_uname); _err == nil || _err.Error() != _error || _plugins != nil {
+			t.Fatalf("expected error %q for unit %s, got %v %v", _error, _uname, _plugins, _err)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Configuration Reload Implementation
+//
+// Objective     :   Reload the application configuration by comparing the running and the new configuration.
+//					Only the units that are changed are started, stopped or resized. Monitors, logger,
+//					counters and unaffected units keep running.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Guarded the running configuration & stopped the removed instances outside the units lock
+// Ajith de Silva				19/10/2026	Updated 	Closed the plugin instances of the unit via close_plugins
+// Ajith de Silva				19/10/2026	Updated 	Added running_config_ext for the unit pins of the running configuration
+// Ajith de Silva				19/10/2026	Updated 	Removed the services of the stopped unit instances
+// Ajith de Silva				19/10/2026	Updated 	Applied the unit changes by depends_on order
+// Ajith de Silva				19/10/2026	Updated 	Removed the jobs of the stopped unit instances
+// Ajith de Silva				19/10/2026	Updated 	Cancelled the routines of the stopped unit instances
+// Ajith de Silva				19/10/2026	Updated 	Cancelled the contexts of the stopped unit instances
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"agnione/v1/src/aau/iappunit"
+	apptypes "agnione/v1/src/appfm/types"
+	"errors"
+	"slices"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// UnitChange defines the action taken for a unit during the configuration reload
+type UnitChange string
+
+const (
+	UNIT_ADDED     UnitChange = "start"   /// unit added or enabled
+	UNIT_REMOVED   UnitChange = "stop"    /// unit removed or disabled
+	UNIT_RELOADED  UnitChange = "restart" /// unit path or config file changed
+	UNIT_RESIZED   UnitChange = "resize"  /// unit pool size changed
+	UNIT_UNCHANGED UnitChange = ""
+)
+
+// pool_size returns the pool size of the given unit limited to MAX_POOL_SIZE
+func pool_size(pAppunit *apptypes.Appunit) int8 {
+	if pAppunit.PoolSize > MAX_POOL_SIZE {
+		return MAX_POOL_SIZE
+	}
+	return pAppunit.PoolSize
+}
+
+// running_config returns the running application configuration. The configuration is replaced, not modified,
+// by a reload, so the returned pointer is used as a snapshot
+func (app *AgniApp) running_config() *apptypes.AppConfig {
+
+	app.config_lock.RLock()
+	defer app.config_lock.RUnlock()
+
+	return app.appconfig
+}
+
+// running_config_ext returns the framework settings of the running application configuration
+func (app *AgniApp) running_config_ext() *fmtypes.AppConfigExt {
+
+	app.config_lock.RLock()
+	defer app.config_lock.RUnlock()
+
+	return app.appconfig_ext
+}
+
+// set_running_config replaces the running application configuration
+func (app *AgniApp) set_running_config(pConfig *apptypes.AppConfig) {
+
+	app.config_lock.Lock()
+	defer app.config_lock.Unlock()
+
+	app.appconfig = pConfig
+}
+
+// set_running_config_ext replaces the framework settings of the running application configuration
+func (app *AgniApp) set_running_config_ext(pConfig_Ext *fmtypes.AppConfigExt) {
+
+	app.config_lock.Lock()
+	defer app.config_lock.Unlock()
+
+	app.appconfig_ext = pConfig_Ext
+}
+
+// unit_change compares the running & new configuration of a unit and returns the action to take
+func unit_change(pOld *apptypes.Appunit, pNew *apptypes.Appunit) UnitChange {
+
+	_old_enabled := pOld != nil && pOld.Enable != 0
+	_new_enabled := pNew != nil && pNew.Enable != 0
+
+	switch {
+	case !_old_enabled && !_new_enabled:
+		return UNIT_UNCHANGED
+	case !_old_enabled:
+		return UNIT_ADDED
+	case !_new_enabled:
+		return UNIT_REMOVED
+	case pOld.Path != pNew.Path || pOld.ConfigFile != pNew.ConfigFile:
+		return UNIT_RELOADED
+	case pool_size(pOld) != pool_size(pNew):
+		return UNIT_RESIZED
+	}
+
+	return UNIT_UNCHANGED
+}
+
+// apply_config_diff applies the differences between the running and the given configuration to the unit pool.
+// Returns the action taken by unit name.
+func (app *AgniApp) apply_config_diff(pNewConfig *apptypes.AppConfig) map[string]UnitChange {
+
+	_changes := make(map[string]UnitChange)
+
+	_old_config := app.running_config()
+	_old_units := make(map[string]*apptypes.Appunit)
+	for _index := range _old_config.Appunits {
+		_old_units[_old_config.Appunits[_index].Uname] = &_old_config.Appunits[_index]
+	}
+
+	/// 1. stop the units removed from the new configuration
+	_new_names := make(map[string]bool)
+	for _, _unit := range pNewConfig.Appunits {
+		_new_names[_unit.Uname] = true
+	}
+
+	for _name, _old := range _old_units {
+		if !_new_names[_name] {
+			if _change := unit_change(_old, nil); _change != UNIT_UNCHANGED {
+				_changes[_name] = _change
+				app.stop_unit_instances(_name, -1)
+			}
+		}
+	}
+
+	/// 2. switch to the new configuration. Get_AppUnit resolves the unit by index of the running configuration
+	app.set_running_config(pNewConfig)
+
+	/// units are started after the units of their depends_on
+	_order, _order_errors := app.start_order(pNewConfig)
+
+	for _, _index := range _order {
+		_new := &pNewConfig.Appunits[_index]
+		_old := _old_units[_new.Uname]
+
+		_change := unit_change(_old, _new)
+		if _change == UNIT_UNCHANGED {
+			continue
+		}
+		_changes[_new.Uname] = _change
+
+		if _change == UNIT_ADDED || _change == UNIT_RELOADED {
+			_err := app.check_dependencies(_new.Uname)
+			if _reason, _found := _order_errors[_new.Uname]; _found {
+				_err = errors.New(_reason)
+			}
+			if _err != nil {
+				app.stop_unit_instances(_new.Uname, -1)
+				app.set_unit_error(_new.Uname, _err.Error())
+				app.Write2LogConsole("Failed to load AgniOne Unit "+_new.Uname+" - "+_err.Error(), apptypes.LOG_ERROR)
+				continue
+			}
+		}
+
+		switch _change {
+		case UNIT_ADDED:
+			app.Load_AppUnit(&_index, _new)
+
+		case UNIT_REMOVED:
+			app.stop_unit_instances(_new.Uname, -1)
+
+		case UNIT_RELOADED:
+			app.stop_unit_instances(_new.Uname, -1)
+			app.Load_AppUnit(&_index, _new)
+
+		case UNIT_RESIZED:
+			_running := app.unit_instance_count(_new.Uname)
+			_size := int(pool_size(_new))
+
+			if _running > _size {
+				app.stop_unit_instances(_new.Uname, _running-_size)
+			} else if _running < _size {
+				_extra := *_new
+				_extra.PoolSize = int8(_size - _running)
+				app.Load_AppUnit(&_index, &_extra)
+			}
+		}
+	}
+
+	return _changes
+}
+
+// unit_instance_count returns the number of instances of the given unit in the pool
+func (app *AgniApp) unit_instance_count(pUnitName string) int {
+
+	app.units_lock.RLock()
+	defer app.units_lock.RUnlock()
+
+	_count := 0
+	for _, _name := range app.appunit_names {
+		if _name == pUnitName {
+			_count++
+		}
+	}
+	return _count
+}
+
+// stop_unit_instances stops, de-initializes and removes the given number of instances of the unit from the pool.
+// Last started instances are stopped first. If pCount is negative, all the instances are stopped.
+// Instances are removed from the pool with the units lock and stopped after it is released, so that
+// the other units are served while they stop. Returns the number of stopped instances.
+func (app *AgniApp) stop_unit_instances(pUnitName string, pCount int) int {
+
+	_removed := app.remove_unit_instances(pUnitName, pCount)
+
+	for _, _unit := range _removed {
+		if _unit.IsStarted() {
+			app.Write2LogConsole("AppUnit - "+pUnitName+" Stop called", apptypes.LOG_INFO)
+			_unit.Stop()
+		}
+		_unit.Deinitialize()
+		app.release_instance(_unit)
+	}
+
+	_stopped := len(_removed)
+
+	app.units_lock.RLock()
+	_last := !slices.Contains(app.appunit_names, pUnitName)
+	app.units_lock.RUnlock()
+
+	/// plugin instances of the unit are closed with its last instance
+	if _stopped > 0 && _last {
+		app.close_plugins(pUnitName)
+	}
+
+	return _stopped
+}
+
+// remove_unit_instances removes the given number of instances of the unit from the pool, last started first.
+// Jobs & contexts of the removed instances are cancelled. Returns the removed instances to be stopped
+func (app *AgniApp) remove_unit_instances(pUnitName string, pCount int) []iappunit.IAppUnit {
+
+	app.units_lock.Lock()
+	defer app.units_lock.Unlock()
+
+	_removed := make([]iappunit.IAppUnit, 0)
+	_count := 0
+
+	for _index := len(app.appUnits) - 1; _index >= 0; _index-- {
+		if pCount >= 0 && _count >= pCount {
+			break
+		}
+
+		if app.appunit_names[_index] != pUnitName {
+			continue
+		}
+
+		if _unit := app.appUnits[_index]; _unit != nil {
+			app.remove_jobs(_unit) /// no job runs while the unit stops
+			app.cancel_unit_context(_unit)
+			_removed = append(_removed, _unit)
+		}
+
+		app.appUnits = append(app.appUnits[:_index], app.appUnits[_index+1:]...)
+		app.appunit_names = append(app.appunit_names[:_index], app.appunit_names[_index+1:]...)
+		_count++
+	}
+
+	return _removed
+}
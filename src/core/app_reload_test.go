@@ -0,0 +1,202 @@
+package agni
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"agnione/v1/src/aau/iappunit"
+	iappfw "agnione/v1/src/appfm/iappfw"
+	apptypes "agnione/v1/src/appfm/types"
+	lib "agnione/v1/src/lib"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/pluginreg"
+	"agnione.appfm/src/routines"
+	"agnione.appfm/src/scheduler"
+	"agnione.appfm/src/servicereg"
+	"agnione.appfm/src/unitctx"
+)
+
+func TestUnit_Change(t *testing.T) {
+
+	_unit := func(pEnable int8, pPath string, pConfig string, pSize int8) *apptypes.Appunit {
+		return &apptypes.Appunit{Uname: "api", Enable: pEnable, Path: pPath, ConfigFile: pConfig, PoolSize: pSize}
+	}
+
+	_cases := []struct {
+		name string
+		old  *apptypes.Appunit
+		new  *apptypes.Appunit
+		want UnitChange
+	}{
+		{"unchanged", _unit(1, "api.so", "api.json", 2), _unit(1, "api.so", "api.json", 2), UNIT_UNCHANGED},
+		{"added", nil, _unit(1, "api.so", "api.json", 1), UNIT_ADDED},
+		{"enabled", _unit(0, "api.so", "api.json", 1), _unit(1, "api.so", "api.json", 1), UNIT_ADDED},
+		{"removed", _unit(1, "api.so", "api.json", 1), nil, UNIT_REMOVED},
+		{"disabled", _unit(1, "api.so", "api.json", 1), _unit(0, "api.so", "api.json", 1), UNIT_REMOVED},
+		{"disabled in both", _unit(0, "api.so", "api.json", 1), _unit(0, "api2.so", "api.json", 3), UNIT_UNCHANGED},
+		{"removed disabled", _unit(0, "api.so", "api.json", 1), nil, UNIT_UNCHANGED},
+		{"path", _unit(1, "api.so", "api.json", 1), _unit(1, "api2.so", "api.json", 1), UNIT_RELOADED},
+		{"config file", _unit(1, "api.so", "api.json", 1), _unit(1, "api.so", "api2.json", 3), UNIT_RELOADED},
+		{"pool size", _unit(1, "api.so", "api.json", 1), _unit(1, "api.so", "api.json", 3), UNIT_RESIZED},
+		{"pool size over max", _unit(1, "api.so", "api.json", MAX_POOL_SIZE), _unit(1, "api.so", "api.json", MAX_POOL_SIZE+1), UNIT_UNCHANGED},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			if _got := unit_change(_case.old, _case.new); _got != _case.want {
+				t.Fatalf("expected %v, got %v", _case.want, _got)
+			}
+		})
+	}
+}
+
+// reload_counts counts the started & stopped instances of the reload_unit by unit name
+type reload_counts struct {
+	lock    sync.Mutex
+	started map[string]int
+	stopped map[string]int
+}
+
+func (c *reload_counts) add(pCounts map[string]int, pName string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	pCounts[pName]++
+}
+
+func (c *reload_counts) get(pCounts map[string]int, pName string) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return pCounts[pName]
+}
+
+// reload_unit is an unit of the apply_config_diff tests
+type reload_unit struct {
+	counts  *reload_counts
+	name    string
+	started atomic.Bool
+}
+
+func (u *reload_unit) New() any { return u }
+func (u *reload_unit) Initialize(pApp iappfw.IAgniApp, pIndex int, pName string, pPath string, pConfig string) (bool, error) {
+	u.name = pName
+	return true, nil
+}
+func (u *reload_unit) Deinitialize() {}
+func (u *reload_unit) Start() (bool, error) {
+	u.started.Store(true)
+	u.counts.add(u.counts.started, u.name)
+	return true, nil
+}
+func (u *reload_unit) Stop() {
+	u.started.Store(false)
+	u.counts.add(u.counts.stopped, u.name)
+}
+func (u *reload_unit) IsStarted() bool               { return u.started.Load() }
+func (u *reload_unit) Info() *lib.BuildInfo          { return &lib.BuildInfo{} }
+func (u *reload_unit) Status() *apptypes.AppUnitInfo { return &apptypes.AppUnitInfo{} }
+
+var reload_units atomic.Int32
+
+// new_reload_app returns a framework instance with an empty unit pool & the static unit path of reload_unit
+func new_reload_app(pCounts *reload_counts) (*AgniApp, string) {
+
+	_path := pluginreg.STATIC_PREFIX + "reload_test_" + strconv.Itoa(int(reload_units.Add(1)))
+	RegisterUnit(_path[len(pluginreg.STATIC_PREFIX):], func() iappunit.IAppUnit { return &reload_unit{counts: pCounts} })
+
+	return &AgniApp{
+		config_lock:        &sync.RWMutex{},
+		appconfig:          &apptypes.AppConfig{},
+		appconfig_ext:      &fmtypes.AppConfigExt{},
+		units_lock:         &sync.RWMutex{},
+		unit_errors_lock:   &sync.Mutex{},
+		unit_errors:        make(map[string]string),
+		unit_contexts_lock: &sync.Mutex{},
+		unit_contexts:      make(map[unitctx.IOwner]*unit_context),
+		startup_slots:      make(chan struct{}, 4),
+		routines:           routines.New(nil, nil),
+		scheduler:          scheduler.New(nil),
+		services:           servicereg.New(),
+		plugin_pools:       pluginreg.NewPools(),
+	}, _path
+}
+
+func TestApply_Config_Diff(t *testing.T) {
+
+	_counts := &reload_counts{started: make(map[string]int), stopped: make(map[string]int)}
+	_app, _path := new_reload_app(_counts)
+
+	_old := &apptypes.AppConfig{Appunits: []apptypes.Appunit{
+		{Uname: "same", Enable: 1, Path: _path, ConfigFile: "same.json", PoolSize: 2},
+		{Uname: "grow", Enable: 1, Path: _path, ConfigFile: "grow.json", PoolSize: 1},
+		{Uname: "shrink", Enable: 1, Path: _path, ConfigFile: "shrink.json", PoolSize: 3},
+		{Uname: "gone", Enable: 1, Path: _path, ConfigFile: "gone.json", PoolSize: 2},
+		{Uname: "off", Enable: 1, Path: _path, ConfigFile: "off.json", PoolSize: 1},
+		{Uname: "config", Enable: 1, Path: _path, ConfigFile: "config.json", PoolSize: 2},
+	}}
+	if _changes := _app.apply_config_diff(_old); len(_changes) != len(_old.Appunits) {
+		t.Fatalf("expected all the units added, got %v", _changes)
+	}
+
+	_new := &apptypes.AppConfig{Appunits: []apptypes.Appunit{
+		{Uname: "added", Enable: 1, Path: _path, ConfigFile: "added.json", PoolSize: 2},
+		{Uname: "same", Enable: 1, Path: _path, ConfigFile: "same.json", PoolSize: 2},
+		{Uname: "grow", Enable: 1, Path: _path, ConfigFile: "grow.json", PoolSize: 3},
+		{Uname: "shrink", Enable: 1, Path: _path, ConfigFile: "shrink.json", PoolSize: 1},
+		{Uname: "off", Enable: 0, Path: _path, ConfigFile: "off.json", PoolSize: 1},
+		{Uname: "config", Enable: 1, Path: _path, ConfigFile: "config2.json", PoolSize: 1},
+		{Uname: "orphan", Enable: 1, Path: _path, ConfigFile: "orphan.json", PoolSize: 1},
+	}}
+	/// added is started after same. orphan depends on the removed unit
+	_app.set_running_config_ext(&fmtypes.AppConfigExt{Appunits: []fmtypes.AppunitExt{
+		{Uname: "added", Depends_On: []string{"same"}},
+		{Uname: "orphan", Depends_On: []string{"gone"}},
+	}})
+
+	_changes := _app.apply_config_diff(_new)
+
+	_cases := []struct {
+		name      string
+		change    UnitChange
+		instances int
+		started   int /// since the first apply
+		stopped   int
+	}{
+		{"added", UNIT_ADDED, 2, 2, 0},
+		{"same", UNIT_UNCHANGED, 2, 0, 0},
+		{"grow", UNIT_RESIZED, 3, 2, 0},
+		{"shrink", UNIT_RESIZED, 1, 0, 2},
+		{"gone", UNIT_REMOVED, 0, 0, 2},
+		{"off", UNIT_REMOVED, 0, 0, 1},
+		{"config", UNIT_RELOADED, 1, 1, 2},
+		{"orphan", UNIT_ADDED, 0, 0, 0},
+	}
+
+	_first := map[string]int{"same": 2, "grow": 1, "shrink": 3, "gone": 2, "off": 1, "config": 2}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			if _change, _found := _changes[_case.name]; _change != _case.change || _found != (_case.change != UNIT_UNCHANGED) {
+				t.Fatalf("expected change %v, got %v", _case.change, _change)
+			}
+			if _count := _app.unit_instance_count(_case.name); _count != _case.instances {
+				t.Fatalf("expected %d instances in the pool, got %d", _case.instances, _count)
+			}
+			if _started := _counts.get(_counts.started, _case.name) - _first[_case.name]; _started != _case.started {
+				t.Fatalf("expected %d started instances, got %d", _case.started, _started)
+			}
+			if _stopped := _counts.get(_counts.stopped, _case.name); _stopped != _case.stopped {
+				t.Fatalf("expected %d stopped instances, got %d", _case.stopped, _stopped)
+			}
+		})
+	}
+
+	if _reason, _ := _app.unit_error("orphan"); _reason != "depends on unknown unit gone" {
+		t.Fatalf("unexpected error of the unit with a removed dependency %q", _reason)
+	}
+	if _app.running_config() != _new {
+		t.Fatal("new configuration is not running")
+	}
+}
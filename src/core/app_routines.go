@@ -0,0 +1,118 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Managed Routine Implementation
+//
+// Objective     :   Provide the named go routines to the units, so that the routines are cancelled on unit stop
+//					and their owner, panics & leaks are known. Units get it by checking the framework instance for
+//					routines.IRoutines
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Derived the routine contexts from the context of the unit instance
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	apptypes "agnione/v1/src/appfm/types"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/routines"
+)
+
+// ROUTINE_STOP_TIMEOUT define the time WaitforClose waits for the managed routines, before they are reported as leaks
+const ROUTINE_STOP_TIMEOUT = 10 * time.Second
+
+// Go starts the function as a named routine of the unit instance. Context of the function is cancelled
+// when the unit instance is stopped. Routine still running after that is reported as leak.
+//
+// Returns nil if successful. Unless error
+func (app *AgniApp) Go(pOwner routines.IOwner, pName string, pRun routines.Func) error {
+
+	if app.routines == nil {
+		return errors.New("managed routines are not initialized")
+	}
+	return app.routines.Go(pOwner, pName, pRun)
+}
+
+// Routines_List returns the running & the last exited managed routines
+func (app *AgniApp) Routines_List() fmtypes.RoutineStats {
+
+	if app.routines == nil {
+		return fmtypes.RoutineStats{Running: make([]fmtypes.RoutineInfo, 0), Exited: make([]fmtypes.RoutineInfo, 0)}
+	}
+	return app.routines.Stats()
+}
+
+// routine_event logs the panic or the leak of a managed routine
+func (app *AgniApp) routine_event(pInfo fmtypes.RoutineInfo) {
+
+	_routine := "Routine " + pInfo.Name + " [" + strconv.FormatUint(pInfo.ID, 10) + "] of " + pInfo.Owner
+
+	if len(pInfo.Panic) > 0 {
+		app.Write2LogConsole(_routine+" panicked. "+pInfo.Panic, apptypes.LOG_ERROR)
+		return
+	}
+	app.Write2LogConsole(_routine+" is still running after its unit is stopped. possible leak", apptypes.LOG_WARN)
+}
+
+// routine_context returns the context of the unit instance for its routines, so that a routine is cancelled with the instance
+func (app *AgniApp) routine_context(pOwner routines.IOwner) context.Context {
+	return app.Unit_Context(pOwner)
+}
+
+// set_routine_owner sets the unit name of the unit instance for its routines
+func (app *AgniApp) set_routine_owner(pOwner routines.IOwner, pUname string) {
+
+	if app.routines != nil && pOwner != nil {
+		app.routines.Set_Owner(pOwner, pUname)
+	}
+}
+
+// cancel_routines cancels the routines of the stopped unit instance kept in the pool
+func (app *AgniApp) cancel_routines(pOwner routines.IOwner) {
+
+	if app.routines != nil && pOwner != nil {
+		app.routines.Cancel_Owner(pOwner)
+	}
+}
+
+// remove_routines cancels the routines of the unit instance removed from the pool
+func (app *AgniApp) remove_routines(pOwner routines.IOwner) {
+
+	if app.routines != nil && pOwner != nil {
+		app.routines.Remove_Owner(pOwner)
+	}
+}
+
+// close_routines cancels all the managed routines & waits for them up to ROUTINE_STOP_TIMEOUT
+func (app *AgniApp) close_routines() {
+
+	if app.routines == nil {
+		return
+	}
+
+	if _leaks := app.routines.Close(ROUTINE_STOP_TIMEOUT); len(_leaks) > 0 {
+		app.Write2LogConsole(strconv.Itoa(len(_leaks))+" managed routines did not stop in "+ROUTINE_STOP_TIMEOUT.String(), apptypes.LOG_WARN)
+	}
+}
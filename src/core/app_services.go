@@ -0,0 +1,85 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Service Registry Implementation
+//
+// Objective     :   Provide the request/reply service registry to the units, so that the units can call each other.
+//					Units get it by checking the framework instance for servicereg.IServices
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"agnione.appfm/src/servicereg"
+)
+
+// Register_Service registers the handler of the named service for the unit instance.
+// Each pool instance of the provider unit registers itself, calls are balanced over the started instances.
+//
+// Returns nil if successful. Unless error
+func (app *AgniApp) Register_Service(pProvider servicereg.IProvider, pName string, pHandler servicereg.Handler) error {
+
+	if app.services == nil {
+		return errors.New("service registry is not initialized")
+	}
+	return app.services.Register(pProvider, pName, pHandler)
+}
+
+// Unregister_Service removes the handler of the named service registered by the unit instance.
+// Handlers are removed by the framework too, when the instance is removed from the unit pool
+func (app *AgniApp) Unregister_Service(pProvider servicereg.IProvider, pName string) {
+
+	if app.services != nil {
+		app.services.Unregister(pProvider, pName)
+	}
+}
+
+// Call_Service calls the named service with the request and decodes the reply into pReply.
+// Returns error if the service has no provider, the provider is stopped, the handler fails or does not reply in pTimeout.
+//
+// Returns nil if successful. Unless error
+func (app *AgniApp) Call_Service(pCTX context.Context, pName string, pRequest any, pReply any, pTimeout time.Duration) error {
+
+	if app.services == nil {
+		return errors.New("service registry is not initialized")
+	}
+	return app.services.Call(pCTX, pName, pRequest, pReply, pTimeout)
+}
+
+// Services_List returns the registered services with the number of started provider instances
+func (app *AgniApp) Services_List() map[string]int {
+
+	if app.services == nil {
+		return make(map[string]int)
+	}
+	return app.services.Services()
+}
+
+// remove_provider removes the services of the unit instance removed from the pool
+func (app *AgniApp) remove_provider(pProvider servicereg.IProvider) {
+
+	if app.services != nil && pProvider != nil {
+		app.services.Remove_Provider(pProvider)
+	}
+}
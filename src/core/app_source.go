@@ -0,0 +1,262 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Configuration Source Implementation
+//
+// Objective     :   Fetch the app.config from the config source set in core.config at startup and on change.
+//					Fetched content is verified and saved as the local app.config, which is used as the
+//					cached copy when the config source is not reachable.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Validated the fetched configuration before it is saved & saved it atomically
+// Ajith de Silva				19/10/2026	Updated 	Documented the signature as the integrity check of the fetched content
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	apptypes "agnione/v1/src/appfm/types"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"agnione.appfm/src/configsource"
+	fmtypes "agnione.appfm/src/fmtypes"
+	autls "agnione.appfm/src/utils"
+)
+
+// Sync_App_Config fetches the application configuration from the config source set in core.config
+// and saves it as the local app.config. Called by the app shell before the framework is initialized.
+//
+// If the config source is not reachable, local app.config is used as the cached copy.
+// Returns nil if config source is disabled, or app.config is available. Unless returns the error
+func Sync_App_Config(pBase_Path *string, pApp_Path *string) error {
+
+	autls.Init_Secrets(pBase_Path)
+
+	_core_file := *pBase_Path + "config/core.config"
+	_config_ext, _err := autls.LoadCoreConfigurationExt(&_core_file)
+	if _err != nil || _config_ext.Core.Config_Source.Enable == 0 {
+		return nil
+	}
+
+	_settings := &_config_ext.Core.Config_Source
+	_config_file := app_config_path(*pApp_Path)
+
+	_source, _err := configsource.New(_settings)
+	if _err != nil {
+		return _err
+	}
+
+	println("reading application configuration from " + _source.Name())
+
+	_validate := func(pData []byte) error {
+		_, _err := validate_config_content(pData)
+		return _err
+	}
+
+	_changed, _err := fetch_app_config(_source, _settings, pBase_Path, &_config_file, _validate)
+	if _err != nil {
+		if autls.IsFileExist(&_config_file) {
+			println("failed to read application configuration from " + _source.Name() + ". " + _err.Error() +
+				"\nusing the cached " + _config_file)
+			return nil
+		}
+		return errors.New("failed to read application configuration from " + _source.Name() + ". " + _err.Error())
+	}
+
+	if _changed {
+		println("application configuration saved to " + _config_file)
+	} else {
+		println("application configuration is not changed since last read")
+	}
+
+	return nil
+}
+
+// start_config_source polls the config source for changes, if enabled in core.config with a poll interval.
+// Changed configuration is saved as the local app.config and reloaded.
+func (app *AgniApp) start_config_source() {
+
+	_core_ext := app.running_core_config_ext()
+	if _core_ext == nil || _core_ext.Core.Config_Source.Enable == 0 || _core_ext.Core.Config_Source.Poll_Interval <= 0 {
+		return
+	}
+
+	_settings := _core_ext.Core.Config_Source
+
+	_source, _err := configsource.New(&_settings)
+	if _err != nil {
+		app.Write2LogConsole("Failed to create config source. "+_err.Error(), apptypes.LOG_ERROR)
+		return
+	}
+
+	app.Write2LogConsole("Polling "+_source.Name()+" for configuration changes", apptypes.LOG_INFO)
+
+	app.Add_Routine()
+
+	go func() {
+		defer func() {
+			recover()
+			app.Remove_Routine()
+		}()
+
+		_ticker := time.NewTicker(time.Second * time.Duration(_settings.Poll_Interval))
+		defer _ticker.Stop()
+
+		_config_file := app.app_config_file()
+
+		for {
+			select {
+			case <-app.stopChan:
+				return
+			case <-_ticker.C:
+			}
+
+			if app.reload_requested {
+				continue /// framework is restarting
+			}
+
+			_event := &fmtypes.ConfigWatchEvent{
+				Event:  "config_source",
+				File:   _source.Name(),
+				Action: "reload units",
+				Status: "OK",
+				Time:   time.Now().Format(time.RFC3339),
+			}
+
+			_changed, _err := fetch_app_config(_source, &_settings, app.base_path, &_config_file, app.validate_reload_content)
+			if _err != nil {
+				app.Write2Log("Failed to read configuration from "+_source.Name()+". using the cached configuration. "+_err.Error(), apptypes.LOG_WARN)
+				continue
+			}
+
+			if !_changed {
+				continue
+			}
+
+			app.Write2LogConsole("Configuration changed in "+_source.Name()+". reloading", apptypes.LOG_INFO)
+
+			if _, _err = app.Reload_Config(); _err != nil {
+				_event.Status = "ERROR"
+				_event.Message = _err.Error()
+				app.Write2LogConsole("Configuration reload from "+_source.Name()+" failed. "+_err.Error(), apptypes.LOG_ERROR)
+			}
+
+			if _message, _err := json.Marshal(_event); _err == nil {
+				app.Send_Monitor_Message(_message)
+			}
+		}
+	}()
+}
+
+// fetch_app_config fetches the configuration from the source, verifies & validates it with pValidate and writes
+// it to the given file. Rejected content is not written, so the cached file & its version stay as they are.
+// File & the version state are replaced atomically.
+// Returns true,nil if the configuration is changed and saved. false,nil if not changed. Unless false,error
+func fetch_app_config(pSource configsource.IConfigSource, pSettings *fmtypes.ConfigSource, pBase_Path *string,
+	pConfig_File *string, pValidate func(pData []byte) error) (bool, error) {
+
+	_state_file := *pConfig_File + ".source"
+	_state := &fmtypes.ConfigSourceState{}
+
+	if _data, _err := autls.Get_File_Content(&_state_file); _err == nil {
+		json.Unmarshal(*_data, _state)
+	}
+
+	/// version is valid only for the same source & if the cached file exists
+	if _state.Source != pSource.Name() || !autls.IsFileExist(pConfig_File) {
+		_state.Version = ""
+	}
+
+	_timeout := pSettings.Timeout
+	if _timeout <= 0 {
+		_timeout = 10
+	}
+
+	_ctx, _cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(_timeout))
+	defer _cancel()
+
+	_content, _err := pSource.Fetch(_ctx, _state.Version)
+	if _err != nil {
+		return false, _err
+	}
+
+	if _content == nil {
+		return false, nil /// not modified
+	}
+
+	if _err = verify_config_content(_content, pSettings, pBase_Path); _err != nil {
+		return false, _err
+	}
+
+	if _err = pValidate(_content.Data); _err != nil {
+		return false, errors.New("invalid application configuration. " + _err.Error())
+	}
+
+	if _, _err = autls.WriteFileAtomic(pConfig_File, &_content.Data); _err != nil {
+		return false, _err
+	}
+
+	_state.Source = pSource.Name()
+	_state.Version = _content.Version
+	_state.Fetched = time.Now().Format(time.RFC3339)
+
+	/// if the state is not saved, the same version is fetched again on the next read
+	_data, _ := json.Marshal(_state)
+	autls.WriteFileAtomic(&_state_file, &_data)
+
+	return true, nil
+}
+
+// verify_config_content checks the checksum and the signature of the fetched content.
+// Checksum is given by the same source as the content, so it detects the truncated content only.
+// Signature verified with public_key is the integrity check, and required if public_key is set
+func verify_config_content(pContent *configsource.Content, pSettings *fmtypes.ConfigSource, pBase_Path *string) error {
+
+	if len(pContent.Checksum) > 0 {
+		if _err := autls.Verify_Checksum(pContent.Data, pContent.Checksum); _err != nil {
+			return _err
+		}
+	} else if pSettings.Require_Checksum == 1 {
+		return errors.New("config source did not provide a checksum")
+	}
+
+	if len(pSettings.Public_Key) == 0 {
+		return nil
+	}
+
+	_key_file := pSettings.Public_Key
+	if !filepath.IsAbs(_key_file) {
+		_key_file = *pBase_Path + _key_file
+	}
+
+	_key, _err := autls.Load_PublicKey(&_key_file)
+	if _err != nil {
+		return _err
+	}
+
+	if pContent.Signature == nil {
+		return errors.New("config source did not provide a signature")
+	}
+
+	return autls.Verify_Signature(pContent.Data, pContent.Signature, []ed25519.PublicKey{_key})
+}
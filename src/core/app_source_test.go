@@ -0,0 +1,142 @@
+package agni
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"agnione.appfm/src/configsource"
+	fmtypes "agnione.appfm/src/fmtypes"
+	autls "agnione.appfm/src/utils"
+)
+
+// source_response is the response of the config server of the fetch_app_config tests
+type source_response struct {
+	content   string
+	etag      string
+	checksum  string
+	signature []byte
+}
+
+// source_server serves the given response. ETag is checked against If-None-Match
+func source_server(t *testing.T, pResponse *source_response) *httptest.Server {
+
+	_server := httptest.NewServer(http.HandlerFunc(func(pWriter http.ResponseWriter, pRequest *http.Request) {
+		if len(pResponse.etag) > 0 && pRequest.Header.Get("If-None-Match") == pResponse.etag {
+			pWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+		pWriter.Header().Set("ETag", pResponse.etag)
+		if len(pResponse.checksum) > 0 {
+			pWriter.Header().Set("X-Config-Checksum", pResponse.checksum)
+		}
+		if pResponse.signature != nil {
+			pWriter.Header().Set("X-Config-Signature", base64.StdEncoding.EncodeToString(pResponse.signature))
+		}
+		pWriter.Write([]byte(pResponse.content))
+	}))
+	t.Cleanup(_server.Close)
+	return _server
+}
+
+func TestFetch_App_Config(t *testing.T) {
+
+	_public, _private, _ := ed25519.GenerateKey(rand.Reader)
+	_, _other, _ := ed25519.GenerateKey(rand.Reader)
+
+	_base := t.TempDir() + "/"
+	os.WriteFile(_base+"config_source.pub", []byte(base64.StdEncoding.EncodeToString(_public)), 0644)
+
+	_signed := func(pContent string, pKey ed25519.PrivateKey) *source_response {
+		return &source_response{content: pContent, etag: `"` + autls.SHA256_Hex([]byte(pContent)) + `"`,
+			checksum: "sha256:" + autls.SHA256_Hex([]byte(pContent)), signature: ed25519.Sign(pKey, []byte(pContent))}
+	}
+
+	_cases := []struct {
+		name       string
+		response   *source_response
+		public_key string
+		checksum   int8
+		changed    bool
+		error      string
+	}{
+		{name: "signed", response: _signed(`{"v":2}`, _private), public_key: "config_source.pub", changed: true},
+		{name: "not modified", response: _signed(`{"v":1}`, _private), public_key: "config_source.pub"},
+		{name: "bad signature", response: _signed(`{"v":2}`, _other), public_key: "config_source.pub",
+			error: "signature"},
+		{name: "tampered content", response: &source_response{content: `{"v":3}`, etag: `"v3"`,
+			checksum: "sha256:" + autls.SHA256_Hex([]byte(`{"v":3}`)), signature: ed25519.Sign(_private, []byte(`{"v":2}`))},
+			public_key: "config_source.pub", error: "signature"},
+		{name: "no signature", response: &source_response{content: `{"v":2}`, etag: `"v2"`},
+			public_key: "config_source.pub", error: "config source did not provide a signature"},
+		{name: "truncated", response: &source_response{content: `{"v":`, etag: `"v2"`,
+			checksum: "sha256:" + autls.SHA256_Hex([]byte(`{"v":2}`))}, error: "checksum"},
+		{name: "no checksum", response: &source_response{content: `{"v":2}`, etag: `"v2"`}, checksum: 1,
+			error: "config source did not provide a checksum"},
+		{name: "unsigned", response: &source_response{content: `{"v":2}`, etag: `"v2"`}, changed: true},
+		{name: "invalid", response: &source_response{content: `{"v":0}`, etag: `"v0"`},
+			error: "invalid application configuration. version 0"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			/// cached app.config of the version 1
+			_config_file := filepath.Join(t.TempDir(), "app.config")
+
+			_server := source_server(t, _case.response)
+			_settings := &fmtypes.ConfigSource{Type: "http", Location: _server.URL, Public_Key: _case.public_key,
+				Require_Checksum: _case.checksum}
+			_source, _ := configsource.New(_settings)
+
+			os.WriteFile(_config_file, []byte(`{"v":1}`), 0644)
+			os.WriteFile(_config_file+".source", []byte(`{"source":"`+_source.Name()+`","version":"\"`+
+				autls.SHA256_Hex([]byte(`{"v":1}`))+`\""}`), 0644)
+
+			_validate := func(pData []byte) error {
+				if strings.Contains(string(pData), `"v":0`) {
+					return errors.New("version 0")
+				}
+				return nil
+			}
+
+			_changed, _err := fetch_app_config(_source, _settings, &_base, &_config_file, _validate)
+
+			if len(_case.error) > 0 {
+				if _err == nil || !strings.Contains(_err.Error(), _case.error) {
+					t.Fatalf("expected error %q, got %v", _case.error, _err)
+				}
+			} else if _err != nil {
+				t.Fatal(_err)
+			}
+			if _changed != _case.changed {
+				t.Fatalf("expected changed %v, got %v", _case.changed, _changed)
+			}
+
+			/// rejected content is not written & the version stays as it is
+			_want := `{"v":1}`
+			if _changed {
+				_want = _case.response.content
+			}
+			if _got := read_file(_config_file); _got != _want {
+				t.Fatalf("expected app.config %s, got %s", _want, _got)
+			}
+			_state := fmtypes.ConfigSourceState{}
+			json.Unmarshal([]byte(read_file(_config_file+".source")), &_state)
+			if (_state.Version == _case.response.etag) != (_changed || _case.name == "not modified") {
+				t.Fatalf("unexpected version state %+v", _state)
+			}
+			if _files := temp_files(_config_file); len(_files) > 0 {
+				t.Fatalf("temp files are left %v", _files)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Unit Startup Implementation
+//
+// Objective     :   Initialize & start the unit instances concurrently, limited by "unit_startup" of core.config.
+//					Initialize & Start calls not returning in the startup timeout fail the instance, so that the
+//					framework startup continues.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Read the core configuration snapshot replaced by the plugins reload
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	apptypes "agnione/v1/src/appfm/types"
+)
+
+const DEFAULT_STARTUP_CONCURRENCY = 4 /// unit instances initialized & started at the same time
+const DEFAULT_STARTUP_TIMEOUT = 60    /// seconds for each Initialize & Start call of an unit instance
+
+// startup_concurrency returns the max unit instances initialized & started at the same time
+func (app *AgniApp) startup_concurrency() int {
+
+	if _core_ext := app.running_core_config_ext(); _core_ext != nil && _core_ext.Core.Unit_Startup.Concurrency > 0 {
+		return _core_ext.Core.Unit_Startup.Concurrency
+	}
+	return DEFAULT_STARTUP_CONCURRENCY
+}
+
+// startup_timeout returns the time for each Initialize & Start call of the unit.
+// "startup_timeout" of the unit in app.config overrides "unit_startup" of core.config
+func (app *AgniApp) startup_timeout(pUname string) time.Duration {
+
+	if _timeout := app.unit_ext(pUname).Startup_Timeout; _timeout > 0 {
+		return time.Duration(_timeout) * time.Second
+	}
+	if _core_ext := app.running_core_config_ext(); _core_ext != nil && _core_ext.Core.Unit_Startup.Timeout > 0 {
+		return time.Duration(_core_ext.Core.Unit_Startup.Timeout) * time.Second
+	}
+	return DEFAULT_STARTUP_TIMEOUT * time.Second
+}
+
+// set_unit_error sets the last load/start error of the unit
+func (app *AgniApp) set_unit_error(pUname string, pReason string) {
+
+	app.unit_errors_lock.Lock()
+	defer app.unit_errors_lock.Unlock()
+
+	if app.unit_errors != nil {
+		app.unit_errors[pUname] = pReason
+	}
+}
+
+// unit_error returns the last load/start error of the unit
+func (app *AgniApp) unit_error(pUname string) (string, bool) {
+
+	app.unit_errors_lock.Lock()
+	defer app.unit_errors_lock.Unlock()
+
+	_reason, _found := app.unit_errors[pUname]
+	return _reason, _found
+}
+
+// timeout_error is returned by call_with_timeout when the function does not return in time
+type timeout_error struct {
+	timeout time.Duration
+}
+
+func (e *timeout_error) Error() string {
+	return "did not return in " + e.timeout.String()
+}
+
+// call_with_timeout calls the function and waits for it up to the timeout.
+// If the function returns after the timeout, pLate is called with its result, to clean up.
+// Panic of the function is returned as error
+func call_with_timeout(pTimeout time.Duration, pCall func() error, pLate func(error)) error {
+
+	_lock := &sync.Mutex{}
+	_expired := false
+	_done := make(chan error, 1)
+
+	go func() {
+		var _err error
+
+		func() {
+			defer func() {
+				if _r := recover(); _r != nil {
+					_err = fmt.Errorf("panicked. %v", _r)
+				}
+			}()
+			_err = pCall()
+		}()
+
+		_lock.Lock()
+		if _expired {
+			_lock.Unlock()
+			if pLate != nil {
+				pLate(_err)
+			}
+			return
+		}
+		_done <- _err
+		_lock.Unlock()
+	}()
+
+	_timer := time.NewTimer(pTimeout)
+	defer _timer.Stop()
+
+	select {
+	case _err := <-_done:
+		return _err
+
+	case <-_timer.C:
+		_lock.Lock()
+		defer _lock.Unlock()
+
+		/// returned while the timer fired
+		select {
+		case _err := <-_done:
+			return _err
+		default:
+		}
+
+		_expired = true
+		return &timeout_error{timeout: pTimeout}
+	}
+}
+
+// load_instance creates, initializes & starts an instance of the unit and adds it to the pool.
+// Returns true if the instance is started
+func (app *AgniApp) load_instance(pUnitIndex int, pAppunit *apptypes.Appunit, pPool_Index int8) (_started bool) {
+
+	defer func() {
+		if _r := recover(); _r != nil {
+			app.set_unit_error(pAppunit.Uname, fmt.Sprintf("panicked. %v", _r))
+			app.Write2LogConsole(fmt.Sprintf("Recovered from Load_Unit panic of %s. %v", pAppunit.Uname, _r), apptypes.LOG_ERROR)
+			_started = false
+		}
+	}()
+
+	_instance := "(" + strconv.Itoa(int(pPool_Index)) + ") of [" + strconv.Itoa(int(pAppunit.PoolSize)) + "] - " + pAppunit.Uname
+
+	_appUnit, _plugins, _err := app.new_unit_instance(&pUnitIndex) /// Get the AgniOne Unit instance
+	if _err != nil {
+		app.set_unit_error(pAppunit.Uname, _err.Error())
+		app.Write2LogConsole("Failed to load AgniOne "+pAppunit.Uname+" - "+pAppunit.Path+". "+_err.Error(), apptypes.LOG_ERROR)
+		return false
+	}
+	app.set_routine_owner(_appUnit, pAppunit.Uname)
+	app.new_unit_context(_appUnit, pAppunit.Uname, int(pPool_Index))
+
+	/// resources of the instance returned after the timeout are released by the late call
+	_release := func() {
+		app.release_instance(_appUnit)
+		app.release_plugins(_plugins)
+	}
+
+	_timeout := app.startup_timeout(pAppunit.Uname)
+	_index := int(app.unit_sequence.Add(1) - 1)
+
+	app.Write2LogConsole("Loading "+_instance, apptypes.LOG_INFO)
+
+	_err = call_with_timeout(_timeout, func() error {
+		_, _err := _appUnit.Initialize(app, _index, pAppunit.Uname, pAppunit.Path, pAppunit.ConfigFile)
+		return _err
+	}, func(pErr error) {
+		if pErr == nil {
+			_appUnit.Deinitialize()
+		}
+		_release()
+		app.Write2LogConsole("Initialize of "+_instance+" returned after the timeout. instance is released", apptypes.LOG_WARN)
+	})
+
+	if _err != nil {
+		app.Write2LogConsole("Failed to initialize "+_instance+". "+_err.Error(), apptypes.LOG_ERROR)
+		app.set_unit_error(pAppunit.Uname, "failed to initialize. "+_err.Error())
+		if _, _timed_out := _err.(*timeout_error); !_timed_out {
+			_release()
+		}
+		return false
+	}
+
+	app.Write2LogConsole("Starting "+_instance+".............\nAppunit info -> "+_appUnit.Info().BuildGoVersion, apptypes.LOG_INFO)
+
+	_err = call_with_timeout(_timeout, func() error {
+		_, _err := _appUnit.Start()
+		return _err
+	}, func(pErr error) {
+		if pErr == nil && _appUnit.IsStarted() {
+			_appUnit.Stop()
+		}
+		_appUnit.Deinitialize()
+		_release()
+		app.Write2LogConsole("Start of "+_instance+" returned after the timeout. instance is released", apptypes.LOG_WARN)
+	})
+
+	if _err != nil {
+		app.Write2LogConsole("Failed to start "+_instance+". "+_err.Error(), apptypes.LOG_ERROR)
+		app.set_unit_error(pAppunit.Uname, "failed to start. "+_err.Error())
+		if _, _timed_out := _err.(*timeout_error); !_timed_out {
+			_appUnit.Deinitialize()
+			_release()
+		}
+		return false
+	}
+
+	/// All good. store the started AppUnit in the pool
+	app.units_lock.Lock()
+	app.appUnits = append(app.appUnits, _appUnit)
+	app.appunit_names = append(app.appunit_names, pAppunit.Uname)
+	app.units_lock.Unlock()
+
+	app.Write2LogConsole("Started ------ "+_instance+" successfully", apptypes.LOG_INFO)
+	return true
+}
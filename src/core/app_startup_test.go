@@ -0,0 +1,243 @@
+package agni
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"agnione/v1/src/aau/iappunit"
+	iappfw "agnione/v1/src/appfm/iappfw"
+	apptypes "agnione/v1/src/appfm/types"
+	lib "agnione/v1/src/lib"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/pluginreg"
+	"agnione.appfm/src/routines"
+	"agnione.appfm/src/scheduler"
+	"agnione.appfm/src/servicereg"
+	"agnione.appfm/src/unitctx"
+)
+
+func TestCall_With_Timeout(t *testing.T) {
+
+	_cases := []struct {
+		name  string
+		call  func() error
+		fails string
+		late  string
+	}{
+		{name: "returned", call: func() error { return nil }},
+		{name: "returned error", call: func() error { return errors.New("no database") }, fails: "no database"},
+		{name: "panic", call: func() error { panic("nil config") }, fails: "panicked. nil config"},
+		{name: "late success", call: func() error { time.Sleep(100 * time.Millisecond); return nil },
+			fails: "did not return in 20ms", late: "<nil>"},
+		{name: "late error", call: func() error { time.Sleep(100 * time.Millisecond); return errors.New("no database") },
+			fails: "did not return in 20ms", late: "no database"},
+		{name: "late panic", call: func() error { time.Sleep(100 * time.Millisecond); panic("nil config") },
+			fails: "did not return in 20ms", late: "panicked. nil config"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_late := make(chan string, 1)
+			_err := call_with_timeout(20*time.Millisecond, _case.call, func(pErr error) {
+				if pErr == nil {
+					_late <- "<nil>"
+					return
+				}
+				_late <- pErr.Error()
+			})
+
+			if len(_case.fails) == 0 && _err != nil {
+				t.Fatal(_err)
+			}
+			if len(_case.fails) > 0 && (_err == nil || _err.Error() != _case.fails) {
+				t.Fatalf("expected %q, got %v", _case.fails, _err)
+			}
+
+			if len(_case.late) == 0 {
+				select {
+				case _result := <-_late:
+					t.Fatalf("returned result %q is passed to the late call", _result)
+				default:
+				}
+				return
+			}
+
+			select {
+			case _result := <-_late:
+				if _result != _case.late {
+					t.Fatalf("expected late %q, got %q", _case.late, _result)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("late result is not passed")
+			}
+		})
+	}
+}
+
+func TestCall_With_Timeout_At_Expiry(t *testing.T) {
+
+	/// result returned when the timer fires is either returned or passed to the late call, never both or none
+	for _index := 0; _index < 200; _index++ {
+
+		_late := make(chan error, 2)
+		_err := call_with_timeout(time.Millisecond, func() error {
+			time.Sleep(time.Millisecond)
+			return nil
+		}, func(pErr error) { _late <- pErr })
+
+		_, _timed_out := _err.(*timeout_error)
+		if _err != nil && !_timed_out {
+			t.Fatal(_err)
+		}
+
+		if _timed_out {
+			select {
+			case <-_late:
+			case <-time.After(time.Second):
+				t.Fatal("result after the timeout is not passed to the late call")
+			}
+		}
+
+		/// expired flag is set with the lock, so the late call of a returned result is not pending
+		select {
+		case <-_late:
+			t.Fatal("late call is called for the returned result or twice")
+		default:
+		}
+	}
+}
+
+// startup_unit is an unit of the load_instance tests
+type startup_unit struct {
+	init_delay    time.Duration
+	init_panic    bool
+	start_delay   time.Duration
+	start_error   error
+	started       atomic.Bool
+	stopped       atomic.Int32
+	deinitialized atomic.Int32
+}
+
+func (u *startup_unit) New() any { return u }
+func (u *startup_unit) Initialize(pApp iappfw.IAgniApp, pIndex int, pName string, pPath string, pConfig string) (bool, error) {
+	time.Sleep(u.init_delay)
+	if u.init_panic {
+		panic("nil config")
+	}
+	return true, nil
+}
+func (u *startup_unit) Deinitialize() { u.deinitialized.Add(1) }
+func (u *startup_unit) Start() (bool, error) {
+	time.Sleep(u.start_delay)
+	if u.start_error != nil {
+		return false, u.start_error
+	}
+	u.started.Store(true)
+	return true, nil
+}
+func (u *startup_unit) Stop() {
+	u.stopped.Add(1)
+	u.started.Store(false)
+}
+func (u *startup_unit) IsStarted() bool               { return u.started.Load() }
+func (u *startup_unit) Info() *lib.BuildInfo          { return &lib.BuildInfo{} }
+func (u *startup_unit) Status() *apptypes.AppUnitInfo { return &apptypes.AppUnitInfo{} }
+
+var startup_units atomic.Int32
+
+// new_startup_app returns a framework instance with the given unit registered as static unit "startup".
+// Initialize & Start of the unit time out in 1 second
+func new_startup_app(pUnit iappunit.IAppUnit) *AgniApp {
+
+	_name := "startup_test_" + strconv.Itoa(int(startup_units.Add(1)))
+	RegisterUnit(_name, func() iappunit.IAppUnit { return pUnit })
+
+	return &AgniApp{
+		config_lock: &sync.RWMutex{},
+		appconfig: &apptypes.AppConfig{Appunits: []apptypes.Appunit{
+			{Uname: "startup", Enable: 1, Path: pluginreg.STATIC_PREFIX + _name, PoolSize: 1}}},
+		appconfig_ext:      &fmtypes.AppConfigExt{Appunits: []fmtypes.AppunitExt{{Uname: "startup", Startup_Timeout: 1}}},
+		units_lock:         &sync.RWMutex{},
+		unit_errors_lock:   &sync.Mutex{},
+		unit_errors:        make(map[string]string),
+		unit_contexts_lock: &sync.Mutex{},
+		unit_contexts:      make(map[unitctx.IOwner]*unit_context),
+		routines:           routines.New(nil, nil),
+		scheduler:          scheduler.New(nil),
+		services:           servicereg.New(),
+		plugin_pools:       pluginreg.NewPools(),
+	}
+}
+
+func TestLoad_Instance(t *testing.T) {
+
+	_cases := []struct {
+		name          string
+		unit          *startup_unit
+		started       bool
+		error         string
+		late          bool /// returned after the timeout
+		stopped       int32
+		deinitialized int32
+	}{
+		{name: "started", unit: &startup_unit{}, started: true},
+		{name: "initialize panic", unit: &startup_unit{init_panic: true},
+			error: "failed to initialize. panicked. nil config"},
+		{name: "start error", unit: &startup_unit{start_error: errors.New("port in use")},
+			error: "failed to start. port in use", deinitialized: 1},
+		{name: "late initialize", unit: &startup_unit{init_delay: 1200 * time.Millisecond},
+			error: "failed to initialize. did not return in 1s", late: true, deinitialized: 1},
+		{name: "late start", unit: &startup_unit{start_delay: 1200 * time.Millisecond},
+			error: "failed to start. did not return in 1s", late: true, stopped: 1, deinitialized: 1},
+		{name: "late start error", unit: &startup_unit{start_delay: 1200 * time.Millisecond, start_error: errors.New("port in use")},
+			error: "failed to start. did not return in 1s", late: true, deinitialized: 1},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_app := new_startup_app(_case.unit)
+			_appunit := _app.appconfig.Appunits[0]
+
+			if _started := _app.load_instance(0, &_appunit, 0); _started != _case.started {
+				t.Fatalf("expected started %v, got %v", _case.started, _started)
+			}
+			if _count := _app.unit_instance_count("startup"); (_count == 1) != _case.started {
+				t.Fatalf("unexpected instances in the pool %d", _count)
+			}
+
+			_reason, _ := _app.unit_error("startup")
+			if _reason != _case.error {
+				t.Fatalf("expected error %q, got %q", _case.error, _reason)
+			}
+
+			/// late result is cleaned up when the call returns
+			if _case.late {
+				_deadline := time.Now().Add(2 * time.Second)
+				for _case.unit.deinitialized.Load() == 0 && time.Now().Before(_deadline) {
+					time.Sleep(10 * time.Millisecond)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if _stopped := _case.unit.stopped.Load(); _stopped != _case.stopped {
+				t.Fatalf("expected %d Stop calls, got %d", _case.stopped, _stopped)
+			}
+			if _deinitialized := _case.unit.deinitialized.Load(); _deinitialized != _case.deinitialized {
+				t.Fatalf("expected %d Deinitialize calls, got %d", _case.deinitialized, _deinitialized)
+			}
+
+			/// context of a failed instance is released
+			_ctx := _app.Unit_Context(_case.unit)
+			if (_ctx.Err() == nil) != _case.started || unitctx.Unit_Name(_ctx) != "startup" {
+				t.Fatalf("unexpected context of the instance %q %v", unitctx.Unit_Name(_ctx), _ctx.Err())
+			}
+		})
+	}
+}
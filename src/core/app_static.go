@@ -0,0 +1,214 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Static Registration Implementation
+//
+// Objective     :   Register the units & plugins compiled into a custom main, so that they are resolved
+//					by static://<name> paths without plugin.Open, and run the framework from that main.
+//					Works with -race and in unit tests.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Added Run to run the framework from a custom main
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	iappunit "agnione/v1/src/aau/iappunit"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/pluginreg"
+	zutls "agnione.appfm/src/utils"
+)
+
+// RegisterUnit registers a compiled-in unit. Units with path static://<name> in app.config are created by the factory.
+// Should be called by the custom main before the framework is started.
+func RegisterUnit(pName string, pFactory func() iappunit.IAppUnit) {
+	pluginreg.Register_Static(pluginreg.UNIT_CATEGORY, pName, func() (any, error) {
+		return pFactory(), nil
+	})
+}
+
+// RegisterPlugin registers a compiled-in plugin of the given category & type.
+// Plugin entries of core.config with path static:// are created by the factory. Factory returns the plugin instance.
+// Should be called by the custom main before the framework is started.
+func RegisterPlugin(pCategory string, pType string, pFactory func() any) {
+	pluginreg.Register_Static(pCategory, pType, func() (any, error) {
+		return pFactory(), nil
+	})
+}
+
+// Run runs the framework instance until an interrupt signal is received. Instance is created again on a reload request.
+// Called by src/app.go, and by the custom mains after the compiled-in units & plugins are registered:
+//
+//	agni.RegisterUnit("demo_http", func() iappunit.IAppUnit { return &demohttp.Unit{} })
+//	if _err := agni.Run(main_path, app_path, log_path, rest_port, ws_port); _err != nil { ... }
+//
+// Returns nil when the framework is stopped. Unless the error of the initialization
+func Run(pMain_Path *string, pApp_Path *string, pLog_Path *string, pRest_Port *int, pWS_Port *int) error {
+
+	_os_pid := os.Getpid()
+
+	/// read config from config server and save it to the /config folder
+	if _err := Sync_App_Config(pMain_Path, pApp_Path); _err != nil {
+		println("error " + _err.Error())
+	}
+
+	for {
+		_reload, _err := run_instance(pMain_Path, pApp_Path, pLog_Path, pRest_Port, pWS_Port, &_os_pid)
+		if _err != nil {
+			return _err
+		}
+
+		/// if reload requested then reload the Agni
+		if !_reload {
+			break
+		}
+		println("Application reload requested.\r\n Reloading application....")
+		runtime.GC()
+	}
+
+	println("\nAgniOne Framework terminated.\n")
+	return nil
+}
+
+// run_instance creates, initializes & starts a framework instance and stops it on an interrupt signal.
+// Returns true if a reload is requested, the error of the initialization
+func run_instance(pMain_Path *string, pApp_Path *string, pLog_Path *string, pRest_Port *int, pWS_Port *int,
+	pOS_PID *int) (bool, error) {
+
+	/// create AgniOne App instance and initialize it
+	println("using app root path\t: " + *pMain_Path)
+	println("using app log path\t: " + *pLog_Path)
+	println("using app unit path\t: " + *pApp_Path + "\n")
+
+	println("\nInitialzing AgniOne ......")
+	_app := new(AgniApp)
+
+	/// create the cancellation application context
+	_ctx, _cancel := context.WithCancel(context.Background())
+	defer _cancel()
+
+	/// initialize the app framework using the parameters.
+	if _, _err := _app.Initialize(&_ctx, pOS_PID, pMain_Path, pApp_Path, pLog_Path, pRest_Port, pWS_Port); _err != nil {
+		return false, _err
+	}
+
+	println("Initializing AgniOne ............  DONE")
+
+	/* SIGNAL handling section */
+	_term := make(chan os.Signal, 1) // Handle sigterm and await terminate signal CTRL + C signal
+	signal.Notify(_term, os.Interrupt, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGSEGV, syscall.SIGABRT)
+	defer signal.Stop(_term)
+
+	println("Starting ::" + _app.Name() + " (" + _app.ID() + ") - " + _app.Version())
+
+	_app.Start() /// starts the application framework
+
+	println("Started :: " + _app.Name() + " (" + _app.ID() + ") - " + _app.Version())
+
+	<-_term // Blocks here until interrupt occur
+
+	_cancel() /// initiate the context Cancel
+	println("*********************************\nShutdown Signal Received\n*********************************")
+
+	println("Signalling the AgniOne Unit(s) to stop")
+	_app.Stop()                 /// call the stop method of the AgniOne Framework. This will trigger all routines in it to stop
+	time.Sleep(time.Second * 5) /// give some time to stop/cleanup routines
+
+	println("Flag all routines to stop.......")
+	_app.Terminate() // broadcast the main channel stopped message
+	println("Flag all routines to stop....... DONE")
+
+	println("Waiting for termination of routines.......")
+	_app.WaitforClose() // Wait until all routines are done
+	println("All routines terminated")
+	time.Sleep(time.Second * 1)
+	_reload := _app.Reload_Requested() /// read if reload requested flag set
+
+	/// clear the variables
+	println("Stopped :: " + _app.Name() + " (" + _app.ID() + ") - " + _app.Version() + "\n")
+	println("Cleaning the Agni environment")
+	_app.DeInitialize()
+
+	return _reload, nil
+}
+
+// plugin_path returns the file path of the plugin entry. static:// paths are not prefixed by the base path
+func (app *AgniApp) plugin_path(pPlugin *fmtypes.PluginConfig) string {
+	if pluginreg.Is_Static(pPlugin.Path) {
+		return pPlugin.Path + pPlugin.Name
+	}
+	return *app.base_path + pPlugin.Path + pPlugin.Name
+}
+
+// static_name returns the registry name of the static:// path. Type of the plugin entry if the path has no name
+func static_name(pPath string, pType string) string {
+	if _name := pluginreg.Static_Name(pPath); len(_name) > 0 {
+		return _name
+	}
+	return pType
+}
+
+// static_instance creates the instance of the compiled-in unit or plugin. Load is counted for the plugin inventory
+func static_instance(pCategory string, pName string, pPath string) (_instance any, _err error) {
+
+	defer func() {
+		if _r := recover(); _r != nil {
+			_err = errors.New("static " + pCategory + " " + pName + " panicked. " + fmt.Sprint(_r))
+		}
+		zutls.Record_Plugin_Load(pPath, _err)
+	}()
+
+	_factory, _found := pluginreg.Static(pCategory, pName)
+	if !_found {
+		return nil, errors.New("static " + pCategory + " " + pName + " is not registered")
+	}
+
+	if _instance, _err = _factory(); _err != nil {
+		return nil, _err
+	}
+	if _instance == nil {
+		return nil, errors.New("static " + pCategory + " " + pName + " factory returned nil")
+	}
+	return _instance, nil
+}
+
+// static_unit creates the instance of the compiled-in unit of the given static:// path
+func static_unit(pPath string) (iappunit.IAppUnit, error) {
+
+	_instance, _err := static_instance(pluginreg.UNIT_CATEGORY, pluginreg.Static_Name(pPath), pPath)
+	if _err != nil {
+		return nil, _err
+	}
+
+	_unit, _ok := _instance.(iappunit.IAppUnit)
+	if !_ok {
+		return nil, errors.New("static unit " + pluginreg.Static_Name(pPath) + " does not implement IAppUnit")
+	}
+	return _unit, nil
+}
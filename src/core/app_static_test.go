@@ -0,0 +1,135 @@
+package agni
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"agnione/v1/src/aau/iappunit"
+	apptypes "agnione/v1/src/appfm/types"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	"agnione.appfm/src/pluginreg"
+	zutls "agnione.appfm/src/utils"
+)
+
+func TestPlugin_Path(t *testing.T) {
+
+	_base := "/opt/agnione/"
+	_app := &AgniApp{base_path: &_base}
+
+	_cases := []struct {
+		name   string
+		plugin apptypes.PlugIn
+		path   string
+		static string
+	}{
+		{"file", apptypes.PlugIn{Type: "default", Path: "plugins/http/", Name: "default.so"}, "/opt/agnione/plugins/http/default.so", ""},
+		{"static name", apptypes.PlugIn{Type: "default", Path: "static://fast_http"}, "static://fast_http", "fast_http"},
+		{"static name in name", apptypes.PlugIn{Type: "default", Path: "static://", Name: "fast_http"}, "static://fast_http", "fast_http"},
+		{"static type", apptypes.PlugIn{Type: "default", Path: "static://"}, "static://", "default"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			_path := _app.plugin_path(&fmtypes.PluginConfig{PlugIn: _case.plugin})
+			if _path != _case.path {
+				t.Fatalf("expected path %q, got %q", _case.path, _path)
+			}
+			if pluginreg.Is_Static(_path) {
+				if _name := static_name(_path, _case.plugin.Type); _name != _case.static {
+					t.Fatalf("expected static name %q, got %q", _case.static, _name)
+				}
+			}
+		})
+	}
+}
+
+func TestStatic_Instance(t *testing.T) {
+
+	RegisterPlugin("static_test", "ok", func() any { return "instance" })
+	RegisterPlugin("static_test", "nil", func() any { return nil })
+	RegisterPlugin("static_test", "panics", func() any { panic("nil settings") })
+	pluginreg.Register_Static("static_test", "error", func() (any, error) { return nil, errors.New("port in use") })
+
+	_cases := []struct {
+		name  string
+		error string
+	}{
+		{"ok", ""},
+		{"nil", "static static_test nil factory returned nil"},
+		{"panics", "static static_test panics panicked. nil settings"},
+		{"error", "port in use"},
+		{"missing", "static static_test missing is not registered"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_path := "static://static_test_" + _case.name
+			_instance, _err := static_instance("static_test", _case.name, _path)
+
+			_got := ""
+			if _err != nil {
+				_got = _err.Error()
+			}
+			if _got != _case.error {
+				t.Fatalf("expected error %q, got %q", _case.error, _got)
+			}
+			if _err == nil && _instance != "instance" {
+				t.Fatalf("unexpected instance %v", _instance)
+			}
+
+			/// load is counted for the plugin inventory
+			_stats := zutls.Plugin_Load_Stats(&_path)
+			if (_stats.Load_Count == 1) != (_err == nil) || (_stats.Failed_Count == 1) != (_err != nil) {
+				t.Fatalf("unexpected load stats %+v", _stats)
+			}
+		})
+	}
+}
+
+func TestStatic_Unit(t *testing.T) {
+
+	RegisterUnit("static_unit_test", func() iappunit.IAppUnit { return &config_unit{} })
+	pluginreg.Register_Static(pluginreg.UNIT_CATEGORY, "static_not_unit_test", func() (any, error) { return "not a unit", nil })
+
+	if _unit, _err := static_unit("static://static_unit_test"); _err != nil || _unit == nil {
+		t.Fatalf("expected the unit instance, got %v", _err)
+	}
+
+	if _, _err := static_unit("static://static_not_unit_test"); _err == nil ||
+		_err.Error() != "static unit static_not_unit_test does not implement IAppUnit" {
+		t.Fatalf("unexpected error %v", _err)
+	}
+}
+
+func TestResolve_Requires(t *testing.T) {
+
+	RegisterPlugin("http", "static_requires_test", func() any { return &strings.Builder{} })
+
+	_app := new_plugins_app(map[string][]fmtypes.PluginConfig{
+		"http": {{PlugIn: apptypes.PlugIn{Type: "default", Enable: 1, Path: "static://static_requires_test"}}},
+	}, []fmtypes.AppunitExt{
+		{Uname: "api", Requires: []fmtypes.PluginRequirement{{Category: "HTTP"}}},
+		{Uname: "worker", Requires: []fmtypes.PluginRequirement{{Category: "http"}, {Category: "mq"}}},
+	})
+
+	_plugins, _err := _app.resolve_requires("api")
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	if _, _ok := _plugins["http/default"].(*strings.Builder); !_ok || len(_plugins) != 1 {
+		t.Fatalf("unexpected plugins %v", _plugins)
+	}
+
+	/// instances acquired before the failed requirement are released for reuse
+	if _plugins, _err = _app.resolve_requires("worker"); _err == nil || _plugins != nil {
+		t.Fatalf("expected error for the missing plugin, got %v", _plugins)
+	}
+
+	_stats := _app.Plugin_Stats()
+	if len(_stats) != 1 || _stats[0].In_Use != 1 || _stats[0].Idle != 1 {
+		t.Fatalf("expected 1 instance in use by api & 1 released, got %+v", _stats)
+	}
+}
@@ -25,6 +25,8 @@
 // Ajith de Silva				29/01/2024	Updated 	Defined functions with parameters & return values
 // Ajith de Silva				29/01/2024	Updated 	Updated the WSMonitor as library
 // Ajith de Silva				08/03/2024	Updated 	Optimized routine sync lock
+// Ajith de Silva				19/10/2026	Updated 	Counted the requests of the unit process in the framework
+// Ajith de Silva				19/10/2026	Updated 	Counted the managed routines
 //#################################################################################################################
 ///
 
@@ -34,6 +36,7 @@ import (
 	"agnione/v1/src/aau/iappunit"
 	atypes "agnione/v1/src/appfm/types"
 	"fmt"
+	"math"
 	"runtime"
 	"time"
 
@@ -47,7 +50,7 @@ func (app *AgniApp) Name() string {
 
 // ID returns the application name
 func (app *AgniApp) ID() string {
-	return app.appconfig.App.ID
+	return app.running_config().App.ID
 }
 
 
@@ -74,12 +77,16 @@ func (app *AgniApp) Memory_Usage() string {
 	return _mem_usage
 }
 
-// Routine_Count returns the number of routines currently running
+// Routine_Count returns the number of routines currently running, managed routines included
 func (app *AgniApp) Routine_Count() uint16 {
 	defer recover()
 	
 	app.routine_lock.RLock()
 	defer app.routine_lock.RUnlock()
+
+	if app.routines != nil {
+		return app.no_of_routines + uint16(min(app.routines.Count(), math.MaxUint16-int(app.no_of_routines)))
+	}
 	return app.no_of_routines
 }
 
@@ -106,6 +113,12 @@ func addUintInt64(x uint64, y int64) (uint64, bool) {
 // This function is useful to external modules to update his request handle count
 func (app *AgniApp) Add_Request_HandleCount() {
 	defer recover()
+
+	/// unit process counts in the framework
+	if app.unit_host != nil {
+		app.unit_host.Request_Handled()
+		return
+	}
 	
 	app.counter_lock.Lock()
 	defer app.counter_lock.Unlock()
@@ -118,6 +131,12 @@ func (app *AgniApp) Add_Request_HandleCount() {
 // This function is useful to external modules to update his request handle count
 func (app *AgniApp) Add_Request_Failed_Count() {
 	defer recover()
+
+	/// unit process counts in the framework
+	if app.unit_host != nil {
+		app.unit_host.Request_Failed()
+		return
+	}
 	
 	app.counter_lock.Lock()
 	defer app.counter_lock.Unlock()
@@ -144,18 +163,23 @@ func (app *AgniApp) update_units_info(pDoneChan chan bool) {
 	defer recover()
 	
 	var _unit iappunit.IAppUnit
-	var _index int
 	
 	defer func ()  {
 		_unit=nil
+		pDoneChan<- true
 	}() 
 	
+	app.units_lock.RLock()
+	defer app.units_lock.RUnlock()
 
-	for _index,_unit=range app.appUnits{
-		app.appunit_info[_index]=*_unit.Status()
+	/// unit pool could be resized by a configuration reload. re-create the info holder from the pool
+	_info:=make([]atypes.AppUnitInfo, 0, len(app.appUnits))
+	for _,_unit=range app.appUnits{
+		if _unit!=nil{
+			_info=append(_info, *_unit.Status())
+		}
 	}
-	
-	pDoneChan<- true
+	app.appunit_info=_info
 }
 
 func (app *AgniApp) read_memory_usage(pDoneChan chan bool){
@@ -0,0 +1,127 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Unit Context Implementation
+//
+// Objective     :   Provide each unit instance its own context, cancelled when the instance is stopped, instead of
+//					the shared Is_Interrupted channel. Units get it by checking the framework instance for
+//					unitctx.IUnitContext
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Returned the cancelled context to the released unit instances
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"context"
+
+	"agnione/v1/src/aau/iappunit"
+
+	"agnione.appfm/src/unitctx"
+)
+
+// MAX_RELEASED_CONTEXTS define the number of the last released unit instances whose cancelled context is kept
+const MAX_RELEASED_CONTEXTS = 100
+
+// unit_context holds the context of an unit instance
+type unit_context struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	released bool /// instance is removed from the pool
+}
+
+// Unit_Context returns the context of the unit instance. It is cancelled when the instance is stopped
+// by Unit_Stop, a reload or a scale down, and when the framework stops.
+// Instances removed from the pool get their cancelled context, so that the work started after the stop is cancelled.
+// Returns the application context if the instance was never of the unit pool
+func (app *AgniApp) Unit_Context(pOwner unitctx.IOwner) context.Context {
+
+	app.unit_contexts_lock.Lock()
+	defer app.unit_contexts_lock.Unlock()
+
+	if _context, _found := app.unit_contexts[pOwner]; _found {
+		return _context.ctx
+	}
+	return app.app_context()
+}
+
+// app_context returns the application context. Background context if it is not set
+func (app *AgniApp) app_context() context.Context {
+
+	if app.ctx != nil && *app.ctx != nil {
+		return *app.ctx
+	}
+	return context.Background()
+}
+
+// new_unit_context creates the context of the unit instance. Called before the instance is initialized
+func (app *AgniApp) new_unit_context(pOwner unitctx.IOwner, pUname string, pPool_Index int) {
+
+	_ctx, _cancel := unitctx.New(app.app_context(), pUname, pPool_Index)
+
+	app.unit_contexts_lock.Lock()
+	defer app.unit_contexts_lock.Unlock()
+
+	app.unit_contexts[pOwner] = &unit_context{ctx: _ctx, cancel: _cancel}
+}
+
+// cancel_unit_context cancels the context of the stopping unit instance. Instance may stay in the pool
+func (app *AgniApp) cancel_unit_context(pOwner unitctx.IOwner) {
+
+	app.unit_contexts_lock.Lock()
+	defer app.unit_contexts_lock.Unlock()
+
+	if _context, _found := app.unit_contexts[pOwner]; _found {
+		_context.cancel()
+	}
+}
+
+// remove_unit_context cancels the context of the unit instance removed from the pool.
+// Cancelled context is kept for the last MAX_RELEASED_CONTEXTS instances, so that they do not get the application context
+func (app *AgniApp) remove_unit_context(pOwner unitctx.IOwner) {
+
+	app.unit_contexts_lock.Lock()
+	defer app.unit_contexts_lock.Unlock()
+
+	_context, _found := app.unit_contexts[pOwner]
+	if !_found || _context.released {
+		return
+	}
+	_context.cancel()
+	_context.released = true
+	app.released_contexts = append(app.released_contexts, pOwner)
+
+	/// oldest released instances are forgotten
+	for len(app.released_contexts) > MAX_RELEASED_CONTEXTS {
+		_oldest := app.released_contexts[0]
+		app.released_contexts = app.released_contexts[1:]
+		if _context, _found := app.unit_contexts[_oldest]; _found && _context.released {
+			delete(app.unit_contexts, _oldest)
+		}
+	}
+}
+
+// release_instance releases the services, jobs, routines & context of the unit instance removed from the pool
+func (app *AgniApp) release_instance(pInstance iappunit.IAppUnit) {
+
+	app.remove_provider(pInstance)
+	app.remove_jobs(pInstance)
+	app.remove_routines(pInstance)
+	app.remove_unit_context(pInstance)
+}
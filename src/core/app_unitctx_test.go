@@ -0,0 +1,115 @@
+package agni
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"agnione.appfm/src/routines"
+	"agnione.appfm/src/unitctx"
+)
+
+// context_owner is an unit instance of the unit context tests
+type context_owner struct {
+	id int
+}
+
+func (o *context_owner) IsStarted() bool { return true }
+
+// app_key is the key of the value set in the application context of the tests
+type app_key struct{}
+
+// new_context_app returns a framework instance with the unit contexts only
+func new_context_app() *AgniApp {
+
+	_ctx := context.WithValue(context.Background(), app_key{}, "app")
+	return &AgniApp{
+		ctx:                &_ctx,
+		unit_contexts_lock: &sync.Mutex{},
+		unit_contexts:      make(map[unitctx.IOwner]*unit_context),
+	}
+}
+
+func TestUnit_Context(t *testing.T) {
+
+	_app := new_context_app()
+	_stopped, _released, _running, _unknown := &context_owner{1}, &context_owner{2}, &context_owner{3}, &context_owner{4}
+
+	for _index, _owner := range []*context_owner{_stopped, _released, _running} {
+		_app.new_unit_context(_owner, "orders", _index)
+	}
+	_app.cancel_unit_context(_stopped)
+	_app.remove_unit_context(_released)
+
+	_cases := []struct {
+		name      string
+		owner     unitctx.IOwner
+		unit      string
+		cancelled bool
+	}{
+		{name: "running", owner: _running, unit: "orders"},
+		{name: "stopped in the pool", owner: _stopped, unit: "orders", cancelled: true},
+		{name: "released", owner: _released, unit: "orders", cancelled: true},
+		{name: "never in the pool", owner: _unknown},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_ctx := _app.Unit_Context(_case.owner)
+			if _name := unitctx.Unit_Name(_ctx); _name != _case.unit {
+				t.Fatalf("expected unit %q, got %q", _case.unit, _name)
+			}
+			if (_ctx.Err() != nil) != _case.cancelled {
+				t.Fatalf("expected cancelled %v, got %v", _case.cancelled, _ctx.Err())
+			}
+			if len(_case.unit) == 0 && _ctx.Value(app_key{}) != "app" {
+				t.Fatal("application context is not returned")
+			}
+		})
+	}
+}
+
+func TestUnit_Context_Released_Routines(t *testing.T) {
+
+	_app := new_context_app()
+	_app.routines = routines.New(nil, _app.routine_context)
+	_owner := &context_owner{1}
+
+	_app.new_unit_context(_owner, "orders", 0)
+	_app.remove_unit_context(_owner)
+
+	/// routine started by the released instance does not outlive it
+	_done := make(chan error, 1)
+	if _err := _app.Go(_owner, "late", func(pCTX context.Context) { _done <- pCTX.Err() }); _err != nil {
+		t.Fatal(_err)
+	}
+	if _err := <-_done; _err == nil {
+		t.Fatal("routine of the released instance is not cancelled")
+	}
+}
+
+func TestUnit_Context_Released_Limit(t *testing.T) {
+
+	_app := new_context_app()
+	_owners := make([]*context_owner, MAX_RELEASED_CONTEXTS+1)
+	for _index := range _owners {
+		_owners[_index] = &context_owner{_index}
+		_app.new_unit_context(_owners[_index], "orders_"+strconv.Itoa(_index), _index)
+		_app.remove_unit_context(_owners[_index])
+	}
+
+	/// released twice is kept once
+	_app.remove_unit_context(_owners[MAX_RELEASED_CONTEXTS])
+
+	if _count := len(_app.unit_contexts); _count != MAX_RELEASED_CONTEXTS {
+		t.Fatalf("expected %d kept contexts, got %d", MAX_RELEASED_CONTEXTS, _count)
+	}
+	if _ctx := _app.Unit_Context(_owners[0]); unitctx.Unit_Name(_ctx) != "" {
+		t.Fatal("context of the oldest released instance is kept")
+	}
+	if _ctx := _app.Unit_Context(_owners[1]); _ctx.Err() == nil || unitctx.Unit_Name(_ctx) != "orders_1" {
+		t.Fatal("context of a released instance is not kept")
+	}
+}
@@ -0,0 +1,107 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Unit Process Implementation
+//
+// Objective     :   Run an unit with "isolation": "process" in the child process started by the framework.
+//					Log entries, request counts & monitor messages of the unit are sent to the framework.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Created the context of the unit in the unit process
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+
+	apptypes "agnione/v1/src/appfm/types"
+
+	"agnione.appfm/src/unitctx"
+	"agnione.appfm/src/unithost"
+)
+
+// Run_Unit_Host runs the unit of the given name for the framework listening on the socket.
+// Called by the app shell of the unit process. Configurations, secrets & plugins are loaded by the unit process,
+// logger & monitors are of the framework. Returns when the framework closes the connection.
+func Run_Unit_Host(pBase_Path *string, pApp_Config *string, pSocket string, pUname string) error {
+
+	app := new(AgniApp)
+
+	_ctx, _cancel := context.WithCancel(context.Background())
+	defer _cancel()
+
+	_pid := os.Getpid()
+	app.ctx = &_ctx
+	app.id = &_pid
+
+	_control, _host, _err := unithost.Dial(pSocket)
+	if _err != nil {
+		return _err
+	}
+	defer _host.Close()
+
+	app.unit_host = _host /// log entries are written to the framework log from here
+
+	if _err = app.init_instance(pBase_Path, pApp_Config); _err != nil {
+		_control.Close()
+		return _err
+	}
+
+	_index := slices.IndexFunc(app.running_config().Appunits, func(pUnit apptypes.Appunit) bool {
+		return pUnit.Uname == pUname
+	})
+	if _index < 0 {
+		_control.Close()
+		return errors.New("unit " + pUname + " is not found in app.config")
+	}
+
+	_unit, _plugins, _err := app.new_unit_instance(&_index)
+	if _err != nil {
+		_control.Close()
+		app.Write2Log("Unit process of "+pUname+" "+_err.Error(), apptypes.LOG_ERROR)
+		return _err
+	}
+
+	app.new_unit_context(_unit, pUname, unitctx.NO_POOL_INDEX) /// pool index is of the framework. cancelled when the process ends
+
+	app.Write2Log("Unit process of "+pUname+" loaded the unit. pid "+strconv.Itoa(_pid), apptypes.LOG_INFO)
+
+	_service := unithost.NewUnitService(app, _unit)
+	_err = unithost.Serve(_control, _service)
+
+	/// framework closed the connection without deinitializing the unit
+	select {
+	case <-_service.Done():
+	default:
+		if _unit.IsStarted() {
+			_unit.Stop()
+		}
+		_unit.Deinitialize()
+	}
+
+	app.release_plugins(_plugins)
+	app.plugin_pools.Close_All()
+	close(app.stopChan)
+
+	return _err
+}
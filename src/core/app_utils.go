@@ -25,6 +25,7 @@
 // Ajith de Silva				29/01/2024	Updated 	Defined functions with parameters & return values
 // Ajith de Silva				29/01/2024	Updated 	Updated the WSMonitor as library
 // Ajith de Silva				03/04/2024	Added	 	Execute_Command method to execute the OS command
+// Ajith de Silva				19/10/2026	Added	 	Get_Config_Content method to read the config with resolved secrets
 //#################################################################################################################
 //
 
@@ -32,6 +33,8 @@ package agni
 
 import (
 	apptypes "agnione/v1/src/appfm/types"
+	"encoding/json"
+	"errors"
 	"os"
 
 	autls "agnione.appfm/src/utils"
@@ -51,6 +54,42 @@ func (app *AgniApp) Get_File_Content(pFileName *string) (*[]byte, error) {
 	return autls.Get_File_Content(pFileName)
 }
 
+// Get_Config_Content returns the content of the given JSON configuration file with the secret references resolved.
+// Units & plugins should read their configuration via this method to use env://, secret://file & secret://local values.
+//
+// Returns file content []bytes,nil if successful.
+//
+// Unless returns nil and error
+func (app *AgniApp) Get_Config_Content(pFileName *string) (*[]byte, error) {
+	_data, _err := autls.Read_Config_Content(pFileName)
+	if _err != nil {
+		return nil, _err
+	}
+	return &_data, nil
+}
+
+// Encrypt_Secrets_File encrypts the name:value pairs of the given plain JSON file into the local secrets file.
+// Key file is created with a new random key, if not exists. Called by the app shell with --encrypt_secrets.
+//
+// Returns the local secrets file & key file,nil if successful. Unless returns error
+func Encrypt_Secrets_File(pBase_Path *string, pPlain_File *string) (string, string, error) {
+
+	autls.Init_Secrets(pBase_Path)
+
+	_data, _err := autls.Get_File_Content(pPlain_File)
+	if _err != nil {
+		return "", "", _err
+	}
+
+	_secrets := make(map[string]string)
+	if _err = json.Unmarshal(*_data, &_secrets); _err != nil {
+		return "", "", errors.New("invalid secrets file " + *pPlain_File + ". JSON object of name:value pairs is required")
+	}
+
+	_secrets_file, _key_file := autls.Secrets_Files()
+	return _secrets_file, _key_file, autls.Encrypt_Secrets(_secrets, _secrets_file, _key_file)
+}
+
 // GetFilePtr returns the file pointer of the given file.
 //
 // Returns valid file pointer and nil if successful.
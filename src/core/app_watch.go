@@ -0,0 +1,300 @@
+//
+//#################################################################################################################
+// Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+//
+// Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+//						Licensed under the Apache License, Version 2.0 (the "License");
+//						you may not use this file except in compliance with the License.
+//						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+//
+//						Unless required by applicable law or agreed to in writing, software
+//						distributed under the License is distributed on an "AS IS" BASIS,
+//						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//						See the License for the specific language governing permissions and
+//						limitations under the License.
+//
+// Class/module  :   AgniOne Application Framework - Core Configuration Watch Implementation
+//
+// Objective     :   Watch app.config, core.config, apikeys.config and the unit config files for changes.
+//					Changes are debounced, validated and the matching reload is triggered.
+//					Outcome is logged and broadcast via web socket monitoring.
+//################################################################################################################
+// Author                        Date        Action      Description
+//------------------------------------------------------------------------------------------------------
+// Ajith de Silva				19/10/2026	Created 	Created the initial version
+// Ajith de Silva				19/10/2026	Updated 	Replaced the core configuration on the plugins reload instead of changing it
+//#################################################################################################################
+//
+
+package agni
+
+import (
+	apptypes "agnione/v1/src/appfm/types"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+	autls "agnione.appfm/src/utils"
+)
+
+// DEFAULT_WATCH_DEBOUNCE define the default milliseconds to wait for the config changes to settle
+const DEFAULT_WATCH_DEBOUNCE int = 1000
+
+// core_config_file returns the full path of the core configuration file
+func (app *AgniApp) core_config_file() string {
+	return *app.base_path + "config/core.config"
+}
+
+// running_core_config returns the running core configuration. The configuration is replaced, not modified,
+// by the plugins reload, so the returned pointer is used as a snapshot
+func (app *AgniApp) running_core_config() *apptypes.FMConfig {
+
+	app.config_lock.RLock()
+	defer app.config_lock.RUnlock()
+
+	return app.coreconfig
+}
+
+// running_core_config_ext returns the framework settings of the running core configuration
+func (app *AgniApp) running_core_config_ext() *fmtypes.FMConfigExt {
+
+	app.config_lock.RLock()
+	defer app.config_lock.RUnlock()
+
+	return app.coreconfig_ext
+}
+
+// set_running_core_config replaces the running core configuration & its framework settings
+func (app *AgniApp) set_running_core_config(pConfig *apptypes.FMConfig, pConfig_Ext *fmtypes.FMConfigExt) {
+
+	app.config_lock.Lock()
+	defer app.config_lock.Unlock()
+
+	app.coreconfig = pConfig
+	app.coreconfig_ext = pConfig_Ext
+}
+
+// apikeys_config_file returns the full path of the api keys file
+func (app *AgniApp) apikeys_config_file() string {
+	return *app.base_path + "config/apikeys.config"
+}
+
+// abs_path returns the absolute path of the given file. Returns the given path if it can not be resolved.
+func abs_path(pFileName string) string {
+	if _abs, _err := filepath.Abs(pFileName); _err == nil {
+		return _abs
+	}
+	return pFileName
+}
+
+// watched_config_files returns the configuration files to be watched
+func (app *AgniApp) watched_config_files() []string {
+
+	_files := []string{app.app_config_file(), app.core_config_file(), app.apikeys_config_file()}
+
+	for _, _unit := range app.running_config().Appunits {
+		if _unit.Enable != 0 && len(_unit.ConfigFile) > 0 {
+			_files = append(_files, _unit.ConfigFile)
+		}
+	}
+
+	return _files
+}
+
+// start_config_watch starts watching the configuration files, if enabled in core.config
+func (app *AgniApp) start_config_watch() {
+
+	_core_ext := app.running_core_config_ext()
+	if _core_ext == nil || _core_ext.Core.Config_Watch.Enable == 0 {
+		return
+	}
+
+	_settings := _core_ext.Core.Config_Watch
+
+	_debounce := _settings.Debounce
+	if _debounce <= 0 {
+		_debounce = DEFAULT_WATCH_DEBOUNCE
+	}
+
+	app.config_watcher = autls.NewFileWatcher(time.Second * time.Duration(_settings.Poll_Interval))
+	app.config_watcher.Set_Files(app.watched_config_files())
+
+	if app.config_watcher.Start() {
+		app.Write2LogConsole("Watching configuration files for changes using inotify", apptypes.LOG_INFO)
+	} else {
+		app.Write2LogConsole("Watching configuration files for changes using polling", apptypes.LOG_INFO)
+	}
+
+	app.Add_Routine()
+	go app.config_watch_loop(time.Millisecond * time.Duration(_debounce))
+}
+
+// config_watch_loop collects the changed files and handles them when changes are settled for the debounce period
+func (app *AgniApp) config_watch_loop(pDebounce time.Duration) {
+
+	_watcher := app.config_watcher
+	_pending := make(map[string]bool)
+
+	_timer := time.NewTimer(pDebounce)
+	_timer.Stop()
+
+	defer func() {
+		if _r := recover(); _r != nil {
+			app.Write2LogConsole("Configuration watch stopped with panic", apptypes.LOG_ERROR)
+		}
+		_timer.Stop()
+		_watcher.Stop()
+		app.Remove_Routine()
+	}()
+
+	for {
+		select {
+		case <-app.stopChan:
+			return
+
+		case _file := <-_watcher.Events:
+			_pending[_file] = true
+			_timer.Reset(pDebounce)
+
+		case <-_timer.C:
+			if app.reload_requested {
+				/// framework is restarting. changes are loaded with the restart
+				clear(_pending)
+				continue
+			}
+
+			for _file := range _pending {
+				app.handle_config_change(_file)
+			}
+			clear(_pending)
+
+			/// unit config files could be changed with app.config
+			_watcher.Set_Files(app.watched_config_files())
+		}
+	}
+}
+
+// handle_config_change validates the changed file and triggers the matching reload
+func (app *AgniApp) handle_config_change(pFileName string) {
+
+	_event := &fmtypes.ConfigWatchEvent{
+		Event:  "config_changed",
+		File:   pFileName,
+		Status: "OK",
+		Time:   time.Now().Format(time.RFC3339),
+	}
+
+	var _err error
+
+	switch pFileName {
+	case abs_path(app.app_config_file()):
+		_event.Action = "reload units"
+		_, _err = app.Reload_Config()
+
+	case abs_path(app.core_config_file()):
+		_event.Action, _err = app.reload_core_config()
+
+	case abs_path(app.apikeys_config_file()):
+		_event.Action = "reload api keys"
+		if app.HTTPMonitor != nil {
+			_, _err = app.HTTPMonitor.Reload_APIKeys()
+		}
+
+	default:
+		_event.Action, _err = app.reload_unit_config(pFileName)
+	}
+
+	if _err != nil {
+		_event.Status = "ERROR"
+		_event.Message = _err.Error()
+		app.Write2LogConsole("Configuration change "+pFileName+" -> "+_event.Action+" failed. "+_err.Error(), apptypes.LOG_ERROR)
+	} else {
+		app.Write2LogConsole("Configuration change "+pFileName+" -> "+_event.Action, apptypes.LOG_INFO)
+	}
+
+	if _message, _err := json.Marshal(_event); _err == nil {
+		app.Send_Monitor_Message(_message)
+	}
+}
+
+// reload_core_config validates the changed core.config.
+// If only the plugins are changed, they are used on the next plugin request. Unless framework is restarted.
+// Returns the action taken
+func (app *AgniApp) reload_core_config() (string, error) {
+
+	_file := app.core_config_file()
+	_newConfig, _err := app.LoadCoreConfiguration(&_file)
+	if _err != nil {
+		return "rejected", _err
+	}
+
+	_running := app.running_core_config()
+	_core := &_running.Core
+	_new := &_newConfig.Core
+
+	if _core.Log == _new.Log &&
+		_core.HTTPMonitor.Host == _new.HTTPMonitor.Host && _core.HTTPMonitor.Enable == _new.HTTPMonitor.Enable &&
+		_core.WSMonitor.Host == _new.WSMonitor.Host && _core.WSMonitor.Enable == _new.WSMonitor.Enable {
+
+		/// copies with the new plugins replace the running configuration. readers keep their snapshot
+		_config := *_running
+		_config.Plugins = _newConfig.Plugins
+
+		_config_ext := fmtypes.FMConfigExt{}
+		if _running_ext := app.running_core_config_ext(); _running_ext != nil {
+			_config_ext = *_running_ext
+		}
+		if _newExt, _err := autls.LoadCoreConfigurationExt(&_file); _err == nil {
+			_config_ext.Plugins = _newExt.Plugins
+		}
+
+		app.set_running_core_config(&_config, &_config_ext)
+		return "reload plugins", nil
+	}
+
+	app.reload_requested = true
+	app.request_restart()
+	return "restart framework", nil
+}
+
+// reload_unit_config validates the changed unit config file and restarts the units using it
+// Returns the action taken
+func (app *AgniApp) reload_unit_config(pFileName string) (string, error) {
+
+	_data, _err := autls.Get_File_Content(&pFileName)
+	if _err != nil {
+		return "rejected", _err
+	}
+
+	/// unit config format is up to the unit. JSON content is checked for the syntax
+	_content := strings.TrimSpace(string(*_data))
+	if (strings.HasPrefix(_content, "{") || strings.HasPrefix(_content, "[")) && !json.Valid(*_data) {
+		return "rejected", errors.New("invalid JSON content")
+	}
+
+	app.reload_lock.Lock()
+	defer app.reload_lock.Unlock()
+
+	_restarted := make([]string, 0)
+
+	_config := app.running_config()
+	for _index := range _config.Appunits {
+		_unit := &_config.Appunits[_index]
+		if _unit.Enable == 0 || len(_unit.ConfigFile) == 0 || abs_path(_unit.ConfigFile) != pFileName {
+			continue
+		}
+
+		app.stop_unit_instances(_unit.Uname, -1)
+		app.Load_AppUnit(&_index, _unit)
+		_restarted = append(_restarted, _unit.Uname)
+	}
+
+	if len(_restarted) == 0 {
+		return "ignored", nil
+	}
+
+	return "restart units " + strings.Join(_restarted, ","), nil
+}
@@ -0,0 +1,440 @@
+// eventbus package provides the in-process publish/subscribe event bus shared by the units of the framework instance
+//
+// Topics are dot separated names (eg: orders.created.eu). Subscriptions can use wildcards:
+//   - "*" matches one topic segment.  orders.*.eu matches orders.created.eu
+//   - "#" as the last segment matches the rest of the topic. orders.# matches orders, orders.created & orders.created.eu
+//
+// Each subscription has its own bounded queue, delivered to the handler in order by one routine.
+// When the queue is full, the event is dropped for the subscription (POLICY_DROP),
+// or the publisher waits until the queue has space or the publish context is done (POLICY_BLOCK).
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   eventbus
+
+	Objective     :   In-process event bus with topics, JSON payloads, bounded subscriber queues and wildcards
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// DEFAULT_QUEUE_SIZE define the max events waiting in a subscription queue, if queue size is not given
+const DEFAULT_QUEUE_SIZE int = 1000
+
+// Policy defines what the bus does when the queue of a subscription is full
+type Policy string
+
+const (
+	POLICY_DROP  Policy = "drop"  /// event is dropped for the subscription & counted as dropped
+	POLICY_BLOCK Policy = "block" /// publisher waits for the queue, until the publish context is done
+)
+
+const (
+	WILDCARD_ONE  = "*" /// matches one topic segment
+	WILDCARD_REST = "#" /// last segment only. matches the rest of the topic
+)
+
+// Event holds an event published to a topic
+type Event struct {
+	ID        string          `json:"id"`
+	Topic     string          `json:"topic"`
+	Payload   json.RawMessage `json:"payload"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Decode decodes the JSON payload of the event into the given value
+func (e *Event) Decode(pValue any) error {
+	if len(e.Payload) == 0 {
+		return errors.New("event " + e.ID + " has no payload")
+	}
+	return json.Unmarshal(e.Payload, pValue)
+}
+
+// Handler is called for every event delivered to a subscription
+type Handler func(pEvent *Event)
+
+// SubscribeOptions holds the subscription options
+type SubscribeOptions struct {
+	Queue_Size int    `json:"queue_size"` /// max events waiting for the handler. default queue size of the bus if <= 0
+	Policy     Policy `json:"policy"`     /// policy of the full queue. default policy of the bus if empty
+}
+
+// ISubscription is a subscription to a topic pattern
+type ISubscription interface {
+	Pattern() string
+	Unsubscribe() error /// stops the deliveries. queued events are dropped
+}
+
+// IEvents defines the event bus functions of the framework instance.
+// Units check the framework instance for it:
+//
+//	if _events, _ok := pApp.(eventbus.IEvents); _ok { ... }
+type IEvents interface {
+	Publish_Event(pCTX context.Context, pTopic string, pPayload any) error
+	Subscribe_Events(pPattern string, pOptions SubscribeOptions, pHandler Handler) (ISubscription, error)
+}
+
+// Bus holds the subscriptions and the counters of the event bus
+type Bus struct {
+	lock          *sync.RWMutex
+	subscriptions map[*subscription]bool
+	queue_size    int
+	policy        Policy
+	mirror        func(*Event) /// called with every published event. nil if not mirrored
+	sequence      atomic.Uint64
+	published     atomic.Uint64
+	unrouted      atomic.Uint64 /// published events without a matching subscription
+	delivered     atomic.Uint64
+	dropped       atomic.Uint64
+	closed        bool
+}
+
+// New creates a new bus with the default queue size & policy of the subscriptions
+func New(pQueue_Size int, pPolicy Policy) (*Bus, error) {
+
+	if pQueue_Size <= 0 {
+		pQueue_Size = DEFAULT_QUEUE_SIZE
+	}
+	if len(pPolicy) == 0 {
+		pPolicy = POLICY_DROP
+	}
+	if pPolicy != POLICY_DROP && pPolicy != POLICY_BLOCK {
+		return nil, errors.New("unsupported event bus policy " + string(pPolicy))
+	}
+
+	return &Bus{lock: &sync.RWMutex{}, subscriptions: make(map[*subscription]bool), queue_size: pQueue_Size, policy: pPolicy}, nil
+}
+
+// Set_Mirror sets the function called with every published event. nil to stop mirroring
+func (b *Bus) Set_Mirror(pMirror func(*Event)) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.mirror = pMirror
+}
+
+// Publish publishes the payload to the topic. Payload is marshalled to JSON, unless it is []byte or json.RawMessage of JSON.
+// Returns the error of the full queues with POLICY_BLOCK, when the context is done before the event is queued
+func (b *Bus) Publish(pCTX context.Context, pTopic string, pPayload any) error {
+
+	if pCTX == nil {
+		pCTX = context.Background()
+	}
+	if _err := pCTX.Err(); _err != nil {
+		return _err
+	}
+
+	if _err := valid_topic(pTopic, false); _err != nil {
+		return _err
+	}
+
+	_payload, _err := to_payload(pPayload)
+	if _err != nil {
+		return _err
+	}
+
+	b.lock.RLock()
+	if b.closed {
+		b.lock.RUnlock()
+		return errors.New("event bus is closed")
+	}
+	_mirror := b.mirror
+	_targets := make([]*subscription, 0, len(b.subscriptions))
+	for _subscription := range b.subscriptions {
+		if match(_subscription.segments, pTopic) {
+			_targets = append(_targets, _subscription)
+		}
+	}
+	b.lock.RUnlock()
+
+	_event := &Event{ID: strconv.FormatUint(b.sequence.Add(1), 10), Topic: pTopic, Payload: _payload, Timestamp: time.Now()}
+
+	b.published.Add(1)
+	if len(_targets) == 0 {
+		b.unrouted.Add(1)
+	}
+
+	if _mirror != nil {
+		_mirror(_event)
+	}
+
+	var _failed error
+	for _, _subscription := range _targets {
+		if _err := _subscription.push(pCTX, _event); _err != nil {
+			_failed = _err
+		}
+	}
+	return _failed
+}
+
+// Subscribe subscribes the handler to the topic pattern
+func (b *Bus) Subscribe(pPattern string, pOptions SubscribeOptions, pHandler Handler) (ISubscription, error) {
+
+	if pHandler == nil {
+		return nil, errors.New("handler is not given")
+	}
+
+	if _err := valid_topic(pPattern, true); _err != nil {
+		return nil, _err
+	}
+
+	if pOptions.Queue_Size <= 0 {
+		pOptions.Queue_Size = b.queue_size
+	}
+	if len(pOptions.Policy) == 0 {
+		pOptions.Policy = b.policy
+	}
+	if pOptions.Policy != POLICY_DROP && pOptions.Policy != POLICY_BLOCK {
+		return nil, errors.New("unsupported event bus policy " + string(pOptions.Policy))
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return nil, errors.New("event bus is closed")
+	}
+
+	_subscription := &subscription{
+		bus:      b,
+		pattern:  pPattern,
+		segments: strings.Split(pPattern, "."),
+		options:  pOptions,
+		handler:  pHandler,
+		queue:    make(chan *Event, pOptions.Queue_Size),
+		stop:     make(chan struct{}),
+	}
+
+	b.subscriptions[_subscription] = true
+	go _subscription.deliver()
+
+	return _subscription, nil
+}
+
+// Stats returns the counters of the bus and its subscriptions
+func (b *Bus) Stats() fmtypes.EventBusStats {
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	_stats := fmtypes.EventBusStats{Published: b.published.Load(), Unrouted: b.unrouted.Load(),
+		Delivered: b.delivered.Load(), Dropped: b.dropped.Load(),
+		Subscriptions: make([]fmtypes.EventSubscriptionStats, 0, len(b.subscriptions))}
+
+	for _subscription := range b.subscriptions {
+		_stats.Subscriptions = append(_stats.Subscriptions, fmtypes.EventSubscriptionStats{
+			Pattern: _subscription.pattern, Policy: string(_subscription.options.Policy),
+			Queue_Size: _subscription.options.Queue_Size, Queued: len(_subscription.queue),
+			Delivered: _subscription.delivered.Load(), Dropped: _subscription.dropped.Load()})
+	}
+	return _stats
+}
+
+// Close unsubscribes all the subscriptions. Bus does not accept events after Close
+func (b *Bus) Close() {
+
+	b.lock.Lock()
+	b.closed = true
+	_subscriptions := make([]*subscription, 0, len(b.subscriptions))
+	for _subscription := range b.subscriptions {
+		_subscriptions = append(_subscriptions, _subscription)
+	}
+	b.lock.Unlock()
+
+	for _, _subscription := range _subscriptions {
+		_subscription.Unsubscribe()
+	}
+}
+
+// subscription is a subscription of a handler to a topic pattern
+type subscription struct {
+	bus       *Bus
+	pattern   string
+	segments  []string
+	options   SubscribeOptions
+	handler   Handler
+	queue     chan *Event
+	stop      chan struct{}
+	stopped   atomic.Bool
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+func (s *subscription) Pattern() string { return s.pattern }
+
+// push adds the event to the queue by the policy of the subscription
+func (s *subscription) push(pCTX context.Context, pEvent *Event) error {
+
+	select {
+	case s.queue <- pEvent:
+		return nil
+	case <-s.stop:
+		return nil
+	default:
+	}
+
+	if s.options.Policy == POLICY_BLOCK {
+		select {
+		case s.queue <- pEvent:
+			return nil
+		case <-s.stop:
+			return nil
+		case <-pCTX.Done():
+		}
+	}
+
+	s.dropped.Add(1)
+	s.bus.dropped.Add(1)
+
+	if s.options.Policy == POLICY_BLOCK {
+		return errors.New("event " + pEvent.ID + " is not queued for " + s.pattern + ". " + pCTX.Err().Error())
+	}
+	return nil
+}
+
+// deliver calls the handler with the queued events until the subscription is stopped
+func (s *subscription) deliver() {
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case _event := <-s.queue:
+			s.handle(_event)
+		}
+	}
+}
+
+// handle calls the handler with the event. Panic of the handler is recovered, the event is counted as dropped
+func (s *subscription) handle(pEvent *Event) {
+
+	defer func() {
+		if _r := recover(); _r != nil {
+			s.dropped.Add(1)
+			s.bus.dropped.Add(1)
+		}
+	}()
+
+	s.handler(pEvent)
+
+	s.delivered.Add(1)
+	s.bus.delivered.Add(1)
+}
+
+// Unsubscribe stops the deliveries. Event in the handler is completed, queued events are dropped
+func (s *subscription) Unsubscribe() error {
+
+	if s.stopped.Swap(true) {
+		return nil
+	}
+
+	s.bus.lock.Lock()
+	delete(s.bus.subscriptions, s)
+	s.bus.lock.Unlock()
+
+	/// not waiting for the handler, as Unsubscribe can be called by the handler
+	close(s.stop)
+	return nil
+}
+
+// valid_topic checks the topic or the subscription pattern
+func valid_topic(pTopic string, pPattern bool) error {
+
+	if len(pTopic) == 0 {
+		return errors.New("topic is not given")
+	}
+
+	_segments := strings.Split(pTopic, ".")
+	for _index, _segment := range _segments {
+		switch {
+		case len(_segment) == 0:
+			return errors.New("topic " + pTopic + " has an empty segment")
+		case !pPattern && (strings.Contains(_segment, WILDCARD_ONE) || strings.Contains(_segment, WILDCARD_REST)):
+			return errors.New("topic " + pTopic + " of the event can not have wildcards")
+		case _segment == WILDCARD_REST && _index != len(_segments)-1:
+			return errors.New("wildcard " + WILDCARD_REST + " should be the last segment of " + pTopic)
+		case _segment != WILDCARD_ONE && _segment != WILDCARD_REST &&
+			(strings.Contains(_segment, WILDCARD_ONE) || strings.Contains(_segment, WILDCARD_REST)):
+			return errors.New("wildcards should be whole segments of " + pTopic)
+		}
+	}
+	return nil
+}
+
+// match checks whether the topic matches the segments of the subscription pattern
+func match(pSegments []string, pTopic string) bool {
+
+	_topic := strings.Split(pTopic, ".")
+
+	for _index, _segment := range pSegments {
+		if _segment == WILDCARD_REST {
+			return true
+		}
+		if _index >= len(_topic) {
+			return false
+		}
+		if _segment != WILDCARD_ONE && _segment != _topic[_index] {
+			return false
+		}
+	}
+	return len(pSegments) == len(_topic)
+}
+
+// to_payload returns the JSON of the payload
+func to_payload(pPayload any) (json.RawMessage, error) {
+
+	switch _payload := pPayload.(type) {
+	case nil:
+		return nil, nil
+	case json.RawMessage:
+		if !json.Valid(_payload) {
+			return nil, errors.New("payload is not valid JSON")
+		}
+		return _payload, nil
+	case []byte:
+		if !json.Valid(_payload) {
+			return nil, errors.New("payload is not valid JSON")
+		}
+		return _payload, nil
+	}
+
+	_data, _err := json.Marshal(pPayload)
+	if _err != nil {
+		return nil, errors.New("payload is not JSON serializable. " + _err.Error())
+	}
+	return _data, nil
+}
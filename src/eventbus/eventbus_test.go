@@ -0,0 +1,337 @@
+package eventbus
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+
+	_cases := []struct {
+		pattern string
+		topic   string
+		matches bool
+	}{
+		{"orders.created", "orders.created", true},
+		{"orders.created", "orders.updated", false},
+		{"orders.created", "orders.created.eu", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders", false},
+		{"orders.*", "orders.created.eu", false},
+		{"orders.*.eu", "orders.created.eu", true},
+		{"orders.*.eu", "orders.created.us", false},
+		{"*.created", "invoices.created", true},
+		{"orders.#", "orders", true},
+		{"orders.#", "orders.created", true},
+		{"orders.#", "orders.created.eu", true},
+		{"orders.#", "invoices.created", false},
+		{"#", "orders.created.eu", true},
+		{"*.*.#", "orders", false},
+		{"*.*.#", "orders.created", true},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.pattern+" "+_case.topic, func(t *testing.T) {
+			if _matches := match(strings.Split(_case.pattern, "."), _case.topic); _matches != _case.matches {
+				t.Fatalf("expected %v, got %v", _case.matches, _matches)
+			}
+		})
+	}
+}
+
+func TestValid_Topic(t *testing.T) {
+
+	_cases := []struct {
+		topic   string
+		pattern bool
+		fails   string
+	}{
+		{topic: "orders.created"},
+		{topic: "orders.*.eu", pattern: true},
+		{topic: "orders.#", pattern: true},
+		{topic: "", fails: "topic is not given"},
+		{topic: "orders..created", fails: "has an empty segment"},
+		{topic: "orders.", fails: "has an empty segment"},
+		{topic: "orders.*", fails: "can not have wildcards"},
+		{topic: "orders.#.eu", pattern: true, fails: "should be the last segment"},
+		{topic: "orders.cre*", pattern: true, fails: "should be whole segments"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.topic, func(t *testing.T) {
+
+			_err := valid_topic(_case.topic, _case.pattern)
+			if len(_case.fails) == 0 {
+				if _err != nil {
+					t.Fatal(_err)
+				}
+				return
+			}
+			if _err == nil || !strings.Contains(_err.Error(), _case.fails) {
+				t.Fatalf("expected %q, got %v", _case.fails, _err)
+			}
+		})
+	}
+}
+
+// collector keeps the topics of the delivered events
+type collector struct {
+	lock   *sync.Mutex
+	topics []string
+	done   chan struct{}
+	count  int
+}
+
+// new_collector returns a collector that closes done after the given number of events
+func new_collector(pCount int) *collector {
+	return &collector{lock: &sync.Mutex{}, done: make(chan struct{}), count: pCount}
+}
+
+func (c *collector) handle(pEvent *Event) {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.topics = append(c.topics, pEvent.Topic)
+	if len(c.topics) == c.count {
+		close(c.done)
+	}
+}
+
+// wait waits for the events & returns the topics
+func (c *collector) wait(t *testing.T) string {
+
+	select {
+	case <-c.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("events are not delivered")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return strings.Join(c.topics, ",")
+}
+
+func TestBus_Publish_Wildcards(t *testing.T) {
+
+	_bus, _ := New(0, "")
+	defer _bus.Close()
+
+	_eu := new_collector(2)
+	_all := new_collector(4)
+	_bus.Subscribe("orders.*.eu", SubscribeOptions{}, _eu.handle)
+	_bus.Subscribe("orders.#", SubscribeOptions{}, _all.handle)
+
+	for _, _topic := range []string{"orders.created.eu", "orders.created.us", "invoices.created.eu", "orders.paid.eu", "orders"} {
+		if _err := _bus.Publish(context.Background(), _topic, map[string]any{"id": 1}); _err != nil {
+			t.Fatal(_err)
+		}
+	}
+
+	if _topics := _eu.wait(t); _topics != "orders.created.eu,orders.paid.eu" {
+		t.Fatalf("unexpected events of orders.*.eu %s", _topics)
+	}
+	if _topics := _all.wait(t); _topics != "orders.created.eu,orders.created.us,orders.paid.eu,orders" {
+		t.Fatalf("unexpected events of orders.# %s", _topics)
+	}
+
+	_stats := _bus.Stats()
+	if _stats.Published != 5 || _stats.Unrouted != 1 || _stats.Delivered != 6 || _stats.Dropped != 0 {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+}
+
+func TestBus_Payload(t *testing.T) {
+
+	_bus, _ := New(0, "")
+	defer _bus.Close()
+
+	_received := make(chan *Event, 1)
+	_bus.Subscribe("orders.created", SubscribeOptions{}, func(pEvent *Event) { _received <- pEvent })
+
+	if _err := _bus.Publish(nil, "orders.created", []byte(`{"id":7}`)); _err != nil {
+		t.Fatal(_err)
+	}
+	_value := struct {
+		ID int `json:"id"`
+	}{}
+	if _err := (<-_received).Decode(&_value); _err != nil || _value.ID != 7 {
+		t.Fatalf("unexpected payload %+v, %v", _value, _err)
+	}
+
+	if _err := _bus.Publish(nil, "orders.created", []byte(`{"id":`)); _err == nil {
+		t.Fatal("invalid JSON payload is published")
+	}
+	if _err := _bus.Publish(nil, "orders.created", func() {}); _err == nil {
+		t.Fatal("not serializable payload is published")
+	}
+}
+
+// blocked_subscription subscribes a handler that waits for release. entered receives each event in the handler
+func blocked_subscription(t *testing.T, pBus *Bus, pPolicy Policy) (chan string, chan struct{}) {
+
+	_entered := make(chan string, 10)
+	_release := make(chan struct{})
+
+	_, _err := pBus.Subscribe("jobs.#", SubscribeOptions{Queue_Size: 1, Policy: pPolicy}, func(pEvent *Event) {
+		_entered <- pEvent.Topic
+		<-_release
+	})
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	return _entered, _release
+}
+
+func TestBus_Drop_Policy(t *testing.T) {
+
+	_bus, _ := New(0, POLICY_DROP)
+	defer _bus.Close()
+
+	_entered, _release := blocked_subscription(t, _bus, POLICY_DROP)
+
+	_bus.Publish(nil, "jobs.1", nil)
+	<-_entered /// handler has the first event. queue is empty
+
+	_bus.Publish(nil, "jobs.2", nil) /// queued
+	if _err := _bus.Publish(nil, "jobs.3", nil); _err != nil {
+		t.Fatalf("dropped event should not fail the publish: %v", _err)
+	}
+
+	close(_release)
+	if _topic := <-_entered; _topic != "jobs.2" {
+		t.Fatalf("unexpected event %s", _topic)
+	}
+
+	select {
+	case _topic := <-_entered:
+		t.Fatalf("dropped event %s is delivered", _topic)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_stats := _bus.Stats()
+	if _stats.Dropped != 1 || _stats.Subscriptions[0].Dropped != 1 || _stats.Subscriptions[0].Policy != string(POLICY_DROP) {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+}
+
+func TestBus_Block_Policy(t *testing.T) {
+
+	_bus, _ := New(0, POLICY_DROP)
+	defer _bus.Close()
+
+	_entered, _release := blocked_subscription(t, _bus, POLICY_BLOCK)
+
+	_bus.Publish(nil, "jobs.1", nil)
+	<-_entered
+	_bus.Publish(nil, "jobs.2", nil)
+
+	/// queue is full. publisher waits until the context is done
+	_ctx, _cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer _cancel()
+	_err := _bus.Publish(_ctx, "jobs.3", nil)
+	if _err == nil || !strings.Contains(_err.Error(), "is not queued for jobs.#") {
+		t.Fatalf("expected not queued error, got %v", _err)
+	}
+
+	/// publisher waits until the queue has space
+	_published := make(chan error, 1)
+	go func() { _published <- _bus.Publish(context.Background(), "jobs.4", nil) }()
+
+	select {
+	case <-_published:
+		t.Fatal("publish did not wait for the full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(_release)
+	if _err = <-_published; _err != nil {
+		t.Fatal(_err)
+	}
+	if _topics := []string{<-_entered, <-_entered}; _topics[0] != "jobs.2" || _topics[1] != "jobs.4" {
+		t.Fatalf("unexpected events %v", _topics)
+	}
+
+	if _stats := _bus.Stats(); _stats.Dropped != 1 {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+}
+
+func TestBus_Handler_Panic(t *testing.T) {
+
+	_bus, _ := New(0, "")
+	defer _bus.Close()
+
+	_done := make(chan struct{})
+	_bus.Subscribe("jobs.*", SubscribeOptions{}, func(pEvent *Event) {
+		if pEvent.Topic == "jobs.done" {
+			close(_done)
+			return
+		}
+		panic("handler failed")
+	})
+
+	_bus.Publish(nil, "jobs.failed", nil)
+	_bus.Publish(nil, "jobs.done", nil)
+	<-_done
+
+	/// counters are updated after the handler returns
+	_deadline := time.Now().Add(time.Second)
+	for _bus.Stats().Delivered == 0 && time.Now().Before(_deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if _stats := _bus.Stats(); _stats.Dropped != 1 || _stats.Delivered != 1 {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+}
+
+func TestBus_Unsubscribe_Close(t *testing.T) {
+
+	_bus, _ := New(0, "")
+
+	_received := make(chan string, 10)
+	_subscription, _ := _bus.Subscribe("jobs.*", SubscribeOptions{}, func(pEvent *Event) { _received <- pEvent.Topic })
+
+	_bus.Publish(nil, "jobs.1", nil)
+	<-_received
+
+	_subscription.Unsubscribe()
+	_subscription.Unsubscribe()
+	_bus.Publish(nil, "jobs.2", nil)
+
+	select {
+	case _topic := <-_received:
+		t.Fatalf("event %s is delivered after Unsubscribe", _topic)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _stats := _bus.Stats(); len(_stats.Subscriptions) != 0 || _stats.Unrouted != 1 {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+
+	_bus.Close()
+	if _err := _bus.Publish(nil, "jobs.3", nil); _err == nil {
+		t.Fatal("event is published to the closed bus")
+	}
+	if _, _err := _bus.Subscribe("jobs.*", SubscribeOptions{}, func(*Event) {}); _err == nil {
+		t.Fatal("closed bus accepted a subscription")
+	}
+}
+
+func TestNew_Policy(t *testing.T) {
+
+	if _, _err := New(0, "retry"); _err == nil {
+		t.Fatal("unsupported policy is accepted")
+	}
+
+	_bus, _ := New(0, "")
+	defer _bus.Close()
+	if _, _err := _bus.Subscribe("jobs", SubscribeOptions{Policy: "retry"}, func(*Event) {}); _err == nil {
+		t.Fatal("unsupported subscription policy is accepted")
+	}
+	if _, _err := _bus.Subscribe("jobs", SubscribeOptions{}, nil); _err == nil {
+		t.Fatal("subscription without handler is accepted")
+	}
+}
@@ -0,0 +1,393 @@
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   execunit - unit
+
+	Objective     :   Map the IAppUnit lifecycle of the framework onto an unit executable
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+	Ajith de Silva		19/10/2026	Updated 	Started the verified copy of the executable
+
+#########################################################################################
+*/
+package execunit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	iappfw "agnione/v1/src/appfm/iappfw"
+	apptypes "agnione/v1/src/appfm/types"
+	lib "agnione/v1/src/lib"
+
+	zutls "agnione.appfm/src/utils"
+)
+
+// UNIT_TYPE define the unit type of the exec units in app.config
+const UNIT_TYPE = "exec"
+
+const INITIALIZE_TIMEOUT = 30 * time.Second /// time for the unit to reply to initialize, start & stop
+const CALL_TIMEOUT = 5 * time.Second        /// time for the unit to reply to status
+const EXIT_TIMEOUT = 10 * time.Second       /// time for the unit to exit after deinitialize, before it is killed
+
+// ICounters defines the request counters of the framework updated by the counter notifications
+type ICounters interface {
+	Add_Request_HandleCount()
+	Add_Request_Failed_Count()
+}
+
+// Unit runs an unit executable. It is kept in the unit pool of the framework as the unit instance
+type Unit struct {
+	command string
+	args    []string
+	env     []string
+	verify  func(*string) (string, error)
+	run     string /// verified copy of the command that is started
+
+	app    iappfw.IAgniApp
+	lock   *sync.Mutex
+	cmd    *exec.Cmd
+	conn   *Conn
+	stdin  io.WriteCloser
+	exited chan struct{}
+
+	name     string
+	started  bool
+	closing  bool
+	handled  uint64
+	failed   uint64
+	routines uint16
+	info     lib.BuildInfo
+}
+
+// New creates the unit of the given executable. pEnv entries are KEY=VALUE, added to the framework environment.
+// pVerify is called with the executable before it is started and returns the verified file to start. nil to skip
+func New(pCommand string, pArgs []string, pEnv []string, pVerify func(*string) (string, error)) *Unit {
+	return &Unit{command: pCommand, args: pArgs, env: pEnv, verify: pVerify, lock: &sync.Mutex{},
+		info: lib.BuildInfo{BuildGoVersion: UNIT_TYPE}}
+}
+
+// New returns a new unit of the same executable
+func (u *Unit) New() any {
+	return New(u.command, u.args, u.env, u.verify)
+}
+
+// Initialize starts the unit executable and sends initialize
+func (u *Unit) Initialize(pApp iappfw.IAgniApp, pIndex int, pName string, pPath string, pConfig_File string) (_ok bool, _err error) {
+
+	u.app = pApp
+	u.name = pName
+
+	defer func() {
+		zutls.Record_Plugin_Load(u.command, _err)
+	}()
+
+	u.run = u.command
+	if u.verify != nil {
+		if u.run, _err = u.verify(&u.command); _err != nil {
+			return false, _err
+		}
+	}
+
+	if _err = u.launch(); _err != nil {
+		return false, _err
+	}
+
+	_result := InitializeResult{}
+	_params := InitializeParams{Index: pIndex, Name: pName, Path: pPath, Config_File: pConfig_File}
+	if _err = u.conn.Call(METHOD_INITIALIZE, _params, &_result, INITIALIZE_TIMEOUT); _err != nil {
+		u.end()
+		return false, _err
+	}
+
+	if _result.Info != nil {
+		u.lock.Lock()
+		u.info = lib.BuildInfo{Version: _result.Info.Version, Time: _result.Info.Time, User: _result.Info.User,
+			BuildGoVersion: _result.Info.Go_Version}
+		if len(u.info.BuildGoVersion) == 0 {
+			u.info.BuildGoVersion = UNIT_TYPE
+		}
+		u.lock.Unlock()
+	}
+
+	return true, nil
+}
+
+// Start sends start to the unit executable
+func (u *Unit) Start() (bool, error) {
+
+	_conn := u.current()
+	if _conn == nil {
+		return false, errors.New("unit executable of " + u.name + " is not running")
+	}
+
+	if _err := _conn.Call(METHOD_START, struct{}{}, nil, INITIALIZE_TIMEOUT); _err != nil {
+		return false, _err
+	}
+
+	u.lock.Lock()
+	u.started = true
+	u.lock.Unlock()
+
+	return true, nil
+}
+
+// Stop sends stop to the unit executable. Executable is kept running until Deinitialize
+func (u *Unit) Stop() {
+
+	u.lock.Lock()
+	u.started = false
+	u.lock.Unlock()
+
+	if _conn := u.current(); _conn != nil {
+		if _err := _conn.Call(METHOD_STOP, struct{}{}, nil, INITIALIZE_TIMEOUT); _err != nil {
+			u.app.Write2Log("Exec unit "+u.name+" "+_err.Error(), apptypes.LOG_WARN)
+		}
+	}
+}
+
+// IsStarted checks whether the unit is started and the executable is running
+func (u *Unit) IsStarted() bool {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	return u.started && u.conn != nil
+}
+
+// Info returns the build info sent by the unit executable on initialize
+func (u *Unit) Info() *lib.BuildInfo {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	_info := u.info
+	return &_info
+}
+
+// Status returns the status of the unit with the request counts sent by the unit executable
+func (u *Unit) Status() *apptypes.AppUnitInfo {
+
+	if _conn := u.current(); _conn != nil {
+		_result := StatusResult{}
+		if _err := _conn.Call(METHOD_STATUS, struct{}{}, &_result, CALL_TIMEOUT); _err == nil {
+			u.lock.Lock()
+			u.routines = _result.Routines
+			u.lock.Unlock()
+		}
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	_status := &apptypes.AppUnitInfo{Req_Handled: u.handled, Req_Failed: u.failed, Routines: u.routines}
+	_status.Info.Name = u.name
+	_status.Info.BuildGoVersion = u.info.BuildGoVersion
+	return _status
+}
+
+// Deinitialize sends deinitialize to the unit executable and waits for it to exit. Executable is killed if it does not exit in time
+func (u *Unit) Deinitialize() {
+
+	u.lock.Lock()
+	u.closing = true
+	_conn := u.conn
+	u.lock.Unlock()
+
+	if _conn != nil {
+		if _err := _conn.Call(METHOD_DEINITIALIZE, struct{}{}, nil, EXIT_TIMEOUT); _err != nil {
+			u.app.Write2Log("Exec unit "+u.name+" "+_err.Error(), apptypes.LOG_WARN)
+		}
+	}
+
+	u.end()
+}
+
+// current returns the connection of the running executable. nil if it is not running
+func (u *Unit) current() *Conn {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	return u.conn
+}
+
+// launch starts the unit executable and connects to its stdin & stdout
+func (u *Unit) launch() error {
+
+	_cmd := exec.Command(u.run, u.args...)
+	_cmd.Env = append(os.Environ(), u.env...)
+	_cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true} /// interrupt of the framework terminal is not sent to the unit
+
+	_stdin, _err := _cmd.StdinPipe()
+	if _err != nil {
+		return _err
+	}
+	_stdout, _err := _cmd.StdoutPipe()
+	if _err != nil {
+		return _err
+	}
+	_stderr, _err := _cmd.StderrPipe()
+	if _err != nil {
+		return _err
+	}
+
+	if _err = _cmd.Start(); _err != nil {
+		return errors.New("failed to start " + u.command + ". " + _err.Error())
+	}
+
+	_conn := NewConn(_stdout, _stdin, u.notify)
+	_exited := make(chan struct{})
+
+	u.lock.Lock()
+	u.cmd, u.conn, u.stdin, u.exited = _cmd, _conn, _stdin, _exited
+	u.lock.Unlock()
+
+	u.app.Write2Log("Exec unit "+u.name+" started "+u.command+". pid "+strconv.Itoa(_cmd.Process.Pid), apptypes.LOG_INFO)
+
+	_stderr_done := make(chan struct{})
+	go func() {
+		defer close(_stderr_done)
+
+		_scanner := bufio.NewScanner(_stderr)
+		for _scanner.Scan() {
+			u.app.Write2Log("Exec unit "+u.name+" "+_scanner.Text(), apptypes.LOG_ERROR)
+		}
+	}()
+
+	/// pipes are read to the end before Wait closes them
+	go func() {
+		<-_conn.Closed()
+		<-_stderr_done
+		_cmd.Wait()
+
+		u.lock.Lock()
+		_closing := u.closing
+		u.started = false
+		u.conn = nil
+		u.lock.Unlock()
+
+		if !_closing {
+			u.app.Write2Log("Exec unit "+u.name+" exited. "+_cmd.ProcessState.String(), apptypes.LOG_ERROR)
+		}
+		close(_exited)
+	}()
+
+	return nil
+}
+
+// end closes the stdin of the unit executable and waits for it to exit. Killed with its process group if it does not exit in time
+func (u *Unit) end() {
+
+	u.lock.Lock()
+	_cmd, _stdin, _exited := u.cmd, u.stdin, u.exited
+	u.cmd, u.stdin = nil, nil
+	u.closing = true
+	u.lock.Unlock()
+
+	if _cmd == nil {
+		return
+	}
+
+	_stdin.Close()
+
+	select {
+	case <-_exited:
+	case <-time.After(EXIT_TIMEOUT):
+		u.app.Write2Log("Exec unit "+u.name+" did not exit in "+EXIT_TIMEOUT.String()+". killing it", apptypes.LOG_WARN)
+		if _err := syscall.Kill(-_cmd.Process.Pid, syscall.SIGKILL); _err != nil {
+			_cmd.Process.Kill()
+		}
+		<-_exited
+	}
+}
+
+// notify handles the notifications of the unit executable
+func (u *Unit) notify(pMethod string, pParams json.RawMessage) {
+
+	switch pMethod {
+	case METHOD_LOG:
+		_params := LogParams{}
+		if _err := json.Unmarshal(pParams, &_params); _err == nil {
+			u.app.Write2Log(_params.Entry, log_level(_params.Level))
+		}
+
+	case METHOD_COUNTER:
+		_params := CounterParams{Count: 1}
+		if _err := json.Unmarshal(pParams, &_params); _err != nil {
+			return
+		}
+
+		u.lock.Lock()
+		switch _params.Name {
+		case "handled":
+			u.handled += _params.Count
+		case "failed":
+			u.failed += _params.Count
+		default:
+			u.lock.Unlock()
+			return
+		}
+		u.lock.Unlock()
+
+		/// requests of the unit are counted in the framework too
+		if _counters, _ok := u.app.(ICounters); _ok {
+			for _count := uint64(0); _count < _params.Count; _count++ {
+				if _params.Name == "handled" {
+					_counters.Add_Request_HandleCount()
+				} else {
+					_counters.Add_Request_Failed_Count()
+				}
+			}
+		}
+
+	case METHOD_MONITOR:
+		_params := MonitorParams{}
+		if _err := json.Unmarshal(pParams, &_params); _err == nil && len(_params.Message) > 0 {
+			u.app.Send_Monitor_Message(_params.Message)
+		}
+
+	default:
+		u.app.Write2Log("Exec unit "+u.name+" sent unknown notification "+pMethod, apptypes.LOG_DEBUG)
+	}
+}
+
+// log_level returns the log level of the level name. info if unknown
+func log_level(pLevel string) apptypes.LogLevel {
+
+	switch strings.ToLower(pLevel) {
+	case "debug":
+		return apptypes.LOG_DEBUG
+	case "warn", "warning":
+		return apptypes.LOG_WARN
+	case "error":
+		return apptypes.LOG_ERROR
+	}
+	return apptypes.LOG_INFO
+}
@@ -0,0 +1,291 @@
+// execunit package runs the units written as external executables.
+//
+// Unit executable talks to the framework with line-delimited JSON-RPC 2.0 over its stdin & stdout.
+// Each message is one JSON object on one line. stderr of the executable is written to the framework log.
+//
+// Framework -> unit requests. Unit replies with the same id, with "result" or "error":
+//   - initialize   {"index":0, "name":"<uname>", "path":"<path>", "config_file":"<file>"}
+//     result may have "info": {"version", "time", "user", "go_version"} shown as the unit build info
+//   - start        {}
+//   - stop         {}
+//   - status       {} result {"started":true, "routines":1}
+//   - deinitialize {} stdin is closed after the reply. unit should exit
+//
+// Unit -> framework notifications, without id:
+//   - log      {"entry":"<text>", "level":"debug|info|warn|error"}
+//   - counter  {"name":"handled|failed", "count":1}
+//   - monitor  {"message":<any JSON>} broadcast via web socket monitoring
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   execunit - protocol
+
+	Objective     :   Define the stdio JSON-RPC protocol of the exec units
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package execunit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JSONRPC_VERSION define the JSON-RPC version of the messages
+const JSONRPC_VERSION = "2.0"
+
+// MAX_MESSAGE_SIZE define the max size of a message line of the unit
+const MAX_MESSAGE_SIZE = 4 * 1024 * 1024
+
+const (
+	METHOD_INITIALIZE   = "initialize"
+	METHOD_START        = "start"
+	METHOD_STOP         = "stop"
+	METHOD_STATUS       = "status"
+	METHOD_DEINITIALIZE = "deinitialize"
+
+	METHOD_LOG     = "log"
+	METHOD_COUNTER = "counter"
+	METHOD_MONITOR = "monitor"
+)
+
+// ERROR_METHOD_NOT_FOUND define the JSON-RPC error code of the unknown methods
+const ERROR_METHOD_NOT_FOUND = -32601
+
+// Message holds a JSON-RPC request, notification or response
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError holds the error of a JSON-RPC response
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return e.Message + " (" + strconv.Itoa(e.Code) + ")"
+}
+
+// InitializeParams holds the params of the initialize request
+type InitializeParams struct {
+	Index       int    `json:"index"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Config_File string `json:"config_file"`
+}
+
+// InitializeResult holds the result of the initialize request
+type InitializeResult struct {
+	Info *UnitInfo `json:"info"`
+}
+
+// UnitInfo holds the build info of the unit executable
+type UnitInfo struct {
+	Version    string `json:"version"`
+	Time       string `json:"time"`
+	User       string `json:"user"`
+	Go_Version string `json:"go_version"`
+}
+
+// StatusResult holds the result of the status request
+type StatusResult struct {
+	Started  bool   `json:"started"`
+	Routines uint16 `json:"routines"`
+}
+
+// LogParams holds the params of the log notification
+type LogParams struct {
+	Entry string `json:"entry"`
+	Level string `json:"level"`
+}
+
+// CounterParams holds the params of the counter notification
+type CounterParams struct {
+	Name  string `json:"name"`
+	Count uint64 `json:"count"`
+}
+
+// MonitorParams holds the params of the monitor notification
+type MonitorParams struct {
+	Message json.RawMessage `json:"message"`
+}
+
+// Conn is the JSON-RPC connection to the unit executable.
+// Requests are sent with Call. Notifications of the unit are passed to the handler, in order.
+type Conn struct {
+	writer  io.Writer
+	lock    *sync.Mutex
+	next_id int64
+	pending map[int64]chan *Message
+	closed  chan struct{}
+	err     error
+}
+
+// NewConn creates the connection on the stdout (pReader) & stdin (pWriter) of the unit executable.
+// Messages are read until pReader is closed. pHandler is called with each notification of the unit
+func NewConn(pReader io.Reader, pWriter io.Writer, pHandler func(pMethod string, pParams json.RawMessage)) *Conn {
+
+	_conn := &Conn{writer: pWriter, lock: &sync.Mutex{}, pending: make(map[int64]chan *Message), closed: make(chan struct{})}
+	go _conn.read(pReader, pHandler)
+	return _conn
+}
+
+// Closed is closed when the unit executable closes its stdout
+func (c *Conn) Closed() <-chan struct{} {
+	return c.closed
+}
+
+// Call sends the request and waits for the response within the timeout. pResult may be nil
+func (c *Conn) Call(pMethod string, pParams any, pResult any, pTimeout time.Duration) error {
+
+	_params, _err := json.Marshal(pParams)
+	if _err != nil {
+		return _err
+	}
+
+	c.lock.Lock()
+	if c.err != nil {
+		c.lock.Unlock()
+		return c.err
+	}
+	c.next_id++
+	_id := c.next_id
+	_reply := make(chan *Message, 1)
+	c.pending[_id] = _reply
+	_err = c.write(&Message{JSONRPC: JSONRPC_VERSION, ID: &_id, Method: pMethod, Params: _params})
+	c.lock.Unlock()
+
+	if _err != nil {
+		c.remove(_id)
+		return errors.New(pMethod + " failed to send. " + _err.Error())
+	}
+
+	select {
+	case _response := <-_reply:
+		if _response.Error != nil {
+			return errors.New(pMethod + " failed. " + _response.Error.Error())
+		}
+		if pResult != nil && len(_response.Result) > 0 {
+			if _err = json.Unmarshal(_response.Result, pResult); _err != nil {
+				return errors.New(pMethod + " returned an invalid result. " + _err.Error())
+			}
+		}
+		return nil
+
+	case <-c.closed:
+		return errors.New(pMethod + " failed. unit executable closed the connection")
+
+	case <-time.After(pTimeout):
+		c.remove(_id)
+		return errors.New(pMethod + " did not reply within " + pTimeout.String())
+	}
+}
+
+// write writes the message as one line. Called with the lock
+func (c *Conn) write(pMessage *Message) error {
+
+	_data, _err := json.Marshal(pMessage)
+	if _err != nil {
+		return _err
+	}
+	_, _err = c.writer.Write(append(_data, '\n'))
+	return _err
+}
+
+// remove removes the pending request of the given id
+func (c *Conn) remove(pID int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.pending, pID)
+}
+
+// read reads the messages of the unit until the reader is closed
+func (c *Conn) read(pReader io.Reader, pHandler func(pMethod string, pParams json.RawMessage)) {
+
+	_scanner := bufio.NewScanner(pReader)
+	_scanner.Buffer(make([]byte, 64*1024), MAX_MESSAGE_SIZE)
+
+	for _scanner.Scan() {
+		_line := _scanner.Bytes()
+		if len(_line) == 0 {
+			continue
+		}
+
+		_message := &Message{}
+		if _err := json.Unmarshal(_line, _message); _err != nil {
+			/// plain output of the unit is kept as a log entry
+			pHandler(METHOD_LOG, json.RawMessage(must_json(LogParams{Entry: string(_line), Level: "info"})))
+			continue
+		}
+
+		switch {
+		case len(_message.Method) > 0 && _message.ID == nil:
+			pHandler(_message.Method, _message.Params)
+
+		case len(_message.Method) > 0:
+			/// framework serves no requests
+			c.lock.Lock()
+			c.write(&Message{JSONRPC: JSONRPC_VERSION, ID: _message.ID,
+				Error: &RPCError{Code: ERROR_METHOD_NOT_FOUND, Message: "method " + _message.Method + " is not found"}})
+			c.lock.Unlock()
+
+		case _message.ID != nil:
+			c.lock.Lock()
+			_reply, _found := c.pending[*_message.ID]
+			delete(c.pending, *_message.ID)
+			c.lock.Unlock()
+
+			if _found {
+				_reply <- _message
+			}
+		}
+	}
+
+	c.lock.Lock()
+	c.err = errors.New("unit executable closed the connection")
+	if _err := _scanner.Err(); _err != nil {
+		c.err = errors.New("unit executable connection failed. " + _err.Error())
+	}
+	c.lock.Unlock()
+
+	close(c.closed)
+}
+
+// must_json returns the value as JSON
+func must_json(pValue any) []byte {
+	_data, _ := json.Marshal(pValue)
+	return _data
+}
@@ -0,0 +1,250 @@
+package execunit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fake_unit is the unit executable side of a connection
+type fake_unit struct {
+	conn   *Conn
+	in     *bufio.Scanner /// requests of the framework
+	out    io.WriteCloser /// replies & notifications of the unit
+	lock   *sync.Mutex
+	events []string
+}
+
+// new_fake_unit returns a connection to a fake unit. Notifications are kept as "<method> <params>"
+func new_fake_unit(t *testing.T) *fake_unit {
+
+	_request_reader, _request_writer := io.Pipe()
+	_reply_reader, _reply_writer := io.Pipe()
+
+	_unit := &fake_unit{in: bufio.NewScanner(_request_reader), out: _reply_writer, lock: &sync.Mutex{}}
+	_unit.conn = NewConn(_reply_reader, _request_writer, func(pMethod string, pParams json.RawMessage) {
+		_unit.lock.Lock()
+		defer _unit.lock.Unlock()
+		_unit.events = append(_unit.events, pMethod+" "+string(pParams))
+	})
+
+	t.Cleanup(func() {
+		_reply_writer.Close()
+		_reply_reader.Close()
+		_request_reader.Close()
+	})
+	return _unit
+}
+
+// request reads the next request of the framework
+func (u *fake_unit) request(t *testing.T) *Message {
+
+	if !u.in.Scan() {
+		t.Error("request is not received")
+		return &Message{}
+	}
+	_message := &Message{}
+	if _err := json.Unmarshal(u.in.Bytes(), _message); _err != nil {
+		t.Error(_err)
+	}
+	return _message
+}
+
+// send writes a line to the framework
+func (u *fake_unit) send(t *testing.T, pLine string) {
+
+	if _, _err := u.out.Write([]byte(pLine + "\n")); _err != nil {
+		t.Error(_err)
+	}
+}
+
+// reply writes the response to the request
+func (u *fake_unit) reply(t *testing.T, pRequest *Message, pResponse string) {
+
+	if pRequest.ID == nil {
+		t.Error("request has no id")
+		return
+	}
+	u.send(t, `{"jsonrpc":"2.0","id":`+strings.TrimSpace(string(must_json(*pRequest.ID)))+`,`+pResponse+`}`)
+}
+
+func TestConn_Call(t *testing.T) {
+
+	_cases := []struct {
+		name     string
+		response string
+		result   string
+		fails    string
+	}{
+		{name: "result", response: `"result":{"info":{"version":"1.2.0"}}`, result: "1.2.0"},
+		{name: "empty result", response: `"result":{}`},
+		{name: "error", response: `"error":{"code":-32000,"message":"not ready"}`,
+			fails: "initialize failed. not ready (-32000)"},
+		{name: "invalid result", response: `"result":{"info":"1.2.0"}`, fails: "initialize returned an invalid result"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_unit := new_fake_unit(t)
+			go func() {
+				_request := _unit.request(t)
+				if _request.Method != METHOD_INITIALIZE || _request.JSONRPC != JSONRPC_VERSION {
+					t.Errorf("unexpected request %+v", _request)
+				}
+				_params := InitializeParams{}
+				json.Unmarshal(_request.Params, &_params)
+				if _params.Name != "orders" || _params.Index != 2 {
+					t.Errorf("unexpected params %s", _request.Params)
+				}
+				_unit.reply(t, _request, _case.response)
+			}()
+
+			_result := InitializeResult{}
+			_err := _unit.conn.Call(METHOD_INITIALIZE, InitializeParams{Index: 2, Name: "orders"}, &_result, time.Second)
+
+			if len(_case.fails) > 0 {
+				if _err == nil || !strings.HasPrefix(_err.Error(), _case.fails) {
+					t.Fatalf("expected %q, got %v", _case.fails, _err)
+				}
+				return
+			}
+			if _err != nil {
+				t.Fatal(_err)
+			}
+			if len(_case.result) > 0 && (_result.Info == nil || _result.Info.Version != _case.result) {
+				t.Fatalf("unexpected result %+v", _result)
+			}
+		})
+	}
+}
+
+func TestConn_Call_Timeout(t *testing.T) {
+
+	_unit := new_fake_unit(t)
+	_received := make(chan *Message, 1)
+	go func() { _received <- _unit.request(t) }()
+
+	_err := _unit.conn.Call(METHOD_STATUS, struct{}{}, nil, 50*time.Millisecond)
+	if _err == nil || !strings.Contains(_err.Error(), "did not reply within 50ms") {
+		t.Fatalf("expected timeout, got %v", _err)
+	}
+
+	/// late reply of the timed out request is dropped
+	_unit.reply(t, <-_received, `"result":{}`)
+
+	_unit.conn.lock.Lock()
+	_pending := len(_unit.conn.pending)
+	_unit.conn.lock.Unlock()
+	if _pending != 0 {
+		t.Fatalf("%d requests are still pending", _pending)
+	}
+}
+
+func TestConn_Call_Out_Of_Order(t *testing.T) {
+
+	_unit := new_fake_unit(t)
+	go func() {
+		_first := _unit.request(t)
+		_second := _unit.request(t)
+		_unit.reply(t, _second, `"result":{"routines":2}`)
+		_unit.reply(t, _first, `"result":{"routines":1}`)
+	}()
+
+	_results := make([]StatusResult, 2)
+	_errors := make([]error, 2)
+	_wait := &sync.WaitGroup{}
+	for _index := range _results {
+		_wait.Add(1)
+		go func(pIndex int) {
+			defer _wait.Done()
+			_errors[pIndex] = _unit.conn.Call(METHOD_STATUS, struct{}{}, &_results[pIndex], time.Second)
+		}(_index)
+	}
+	_wait.Wait()
+
+	if _errors[0] != nil || _errors[1] != nil {
+		t.Fatal(_errors)
+	}
+	if _results[0].Routines+_results[1].Routines != 3 || _results[0].Routines == _results[1].Routines {
+		t.Fatalf("responses are not matched by id %+v", _results)
+	}
+}
+
+func TestConn_Notifications(t *testing.T) {
+
+	_unit := new_fake_unit(t)
+
+	_unit.send(t, `{"jsonrpc":"2.0","method":"log","params":{"entry":"started","level":"warn"}}`)
+	_unit.send(t, ``)
+	_unit.send(t, `plain output`)
+	_unit.send(t, `{"jsonrpc":"2.0","method":"counter","params":{"name":"handled","count":3}}`)
+	_unit.send(t, `{"jsonrpc":"2.0","id":7,"method":"status","params":{}}`)
+
+	/// unit requests are answered with method not found
+	_response := _unit.request(t)
+	if _response.ID == nil || *_response.ID != 7 || _response.Error == nil || _response.Error.Code != ERROR_METHOD_NOT_FOUND {
+		t.Fatalf("unexpected response %+v", _response)
+	}
+
+	_expected := []string{
+		`log {"entry":"started","level":"warn"}`,
+		`log {"entry":"plain output","level":"info"}`,
+		`counter {"name":"handled","count":3}`,
+	}
+
+	_unit.lock.Lock()
+	defer _unit.lock.Unlock()
+	if strings.Join(_unit.events, "\n") != strings.Join(_expected, "\n") {
+		t.Fatalf("unexpected notifications\n%s", strings.Join(_unit.events, "\n"))
+	}
+}
+
+func TestConn_Closed(t *testing.T) {
+
+	_cases := []struct {
+		name  string
+		close func(pUnit *fake_unit, t *testing.T)
+		fails string
+	}{
+		{name: "stdout closed", close: func(pUnit *fake_unit, t *testing.T) { pUnit.out.Close() },
+			fails: "unit executable closed the connection"},
+		{name: "message too long", close: func(pUnit *fake_unit, t *testing.T) {
+			/// write fails when the pipe is closed by the cleanup, after the connection stopped reading
+			pUnit.out.Write([]byte(strings.Repeat("a", MAX_MESSAGE_SIZE+1) + "\n"))
+		}, fails: "unit executable connection failed"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_unit := new_fake_unit(t)
+			go func() {
+				_unit.request(t)
+				_case.close(_unit, t)
+			}()
+
+			/// pending call fails when the connection is closed
+			_err := _unit.conn.Call(METHOD_STOP, struct{}{}, nil, 5*time.Second)
+			if _err == nil || !strings.Contains(_err.Error(), "closed the connection") {
+				t.Fatalf("expected closed connection, got %v", _err)
+			}
+
+			select {
+			case <-_unit.conn.Closed():
+			case <-time.After(time.Second):
+				t.Fatal("Closed is not closed")
+			}
+
+			/// next calls fail without sending
+			_err = _unit.conn.Call(METHOD_STATUS, struct{}{}, nil, time.Second)
+			if _err == nil || !strings.HasPrefix(_err.Error(), _case.fails) {
+				t.Fatalf("expected %q, got %v", _case.fails, _err)
+			}
+		})
+	}
+}
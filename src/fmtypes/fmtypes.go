@@ -0,0 +1,363 @@
+// fmtypes package provides the framework side types of the AgniOne application framework
+//
+// Types defined in this package are used by the framework core and the monitors only.
+// Types shared with AgniOne units & plugins are defined in agnione/v1/src/appfm/types.
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   fmtypes
+
+	Objective     :   Define the types used by the framework core and monitors
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version with config apply types
+
+	Ajith de Silva		19/10/2026	Added 		Added the config watch settings & events
+
+	Ajith de Silva		19/10/2026	Added 		Added the config source settings
+
+	Ajith de Silva		19/10/2026	Added 		Added the plugin categories
+
+	Ajith de Silva		19/10/2026	Added 		Added the plugin pool settings & stats
+
+	Ajith de Silva		19/10/2026	Added 		Added the plugin build compatibility report
+
+	Ajith de Silva		19/10/2026	Added 		Added the plugin integrity settings & app.config unit pins
+
+	Ajith de Silva		19/10/2026	Added 		Added the plugin inventory
+
+	Ajith de Silva		19/10/2026	Added 		Added the plugin settings & per unit overrides
+
+	Ajith de Silva		19/10/2026	Added 		Added the plugins required by the units
+
+	Ajith de Silva		19/10/2026	Added 		Added the unit process isolation settings
+
+	Ajith de Silva		19/10/2026	Added 		Added the exec unit settings
+
+	Ajith de Silva		19/10/2026	Added 		Added the WebAssembly unit settings
+
+	Ajith de Silva		19/10/2026	Added 		Added the event bus settings & stats
+
+	Ajith de Silva		19/10/2026	Added 		Added the unit dependencies
+
+	Ajith de Silva		19/10/2026	Added 		Added the unit startup settings
+
+	Ajith de Silva		19/10/2026	Added 		Added the job run status
+
+	Ajith de Silva		19/10/2026	Added 		Added the managed routines
+
+#########################################################################################
+*/
+package fmtypes
+
+import (
+	"time"
+
+	apptypes "agnione/v1/src/appfm/types"
+)
+
+// ConfigApplyState defines the state of a two-phase configuration apply
+type ConfigApplyState string
+
+const (
+	APPLY_PENDING    ConfigApplyState = "pending"    /// new configuration staged & framework restarting with it
+	APPLY_COMMITTED  ConfigApplyState = "committed"  /// new configuration passed the grace period
+	APPLY_ROLLEDBACK ConfigApplyState = "rolledback" /// new configuration failed and previous configuration restored
+	APPLY_REJECTED   ConfigApplyState = "rejected"   /// new configuration failed the validation. nothing changed
+)
+
+// ConfigApply holds the state of the configuration apply in progress.
+// It is written next to the app.config, so that it survives the framework restart.
+type ConfigApply struct {
+	State        ConfigApplyState `json:"state"`
+	Requested    string           `json:"requested"`    /// time of the apply request
+	Grace_Period int              `json:"grace_period"` /// seconds to watch the unit startup
+	Backup_File  string           `json:"backup_file"`  /// previous configuration to restore on failure
+}
+
+// ConfigApplyResult holds the outcome of the last configuration apply
+type ConfigApplyResult struct {
+	State     ConfigApplyState  `json:"state"`
+	Requested string            `json:"requested"`
+	Completed string            `json:"completed"`
+	Message   string            `json:"message"`
+	Failed    map[string]string `json:"failed_units,omitempty"` /// unit name -> reason
+}
+
+// ConfigWatch defines the configuration file watcher settings. "config_watch" in the core section of core.config
+type ConfigWatch struct {
+	Enable        int8 `json:"enable"`
+	Poll_Interval int  `json:"poll_interval"` /// seconds. used only if inotify is not available
+	Debounce      int  `json:"debounce"`      /// milliseconds to wait for the changes to settle
+}
+
+// ConfigSource defines the source to fetch the app.config from. "config_source" in the core section of core.config
+type ConfigSource struct {
+	Enable           int8              `json:"enable"`
+	Type             string            `json:"type"`             /// file, http, https or a registered source type
+	Location         string            `json:"location"`         /// file path or URL of the app.config
+	Headers          map[string]string `json:"headers"`          /// request headers. eg: apikey
+	Poll_Interval    int               `json:"poll_interval"`    /// seconds to check for changes. 0 to fetch at startup only
+	Timeout          int               `json:"timeout"`          /// seconds
+	Insecure_TLS     int8              `json:"insecure_tls"`     /// skip the TLS certificate verification
+	Public_Key       string            `json:"public_key"`       /// ed25519 public key file to verify the signature. relative to base path
+	Require_Checksum int8              `json:"require_checksum"` /// reject the content without a checksum. detects truncation, not tampering
+}
+
+// ConfigSourceState holds the last fetched content information. Written next to the app.config
+type ConfigSourceState struct {
+	Source  string `json:"source"`
+	Version string `json:"version"`
+	Fetched string `json:"fetched"`
+}
+
+// PluginIntegrity defines the integrity policy of the plugin & unit .so files. "plugin_integrity" in the core section of core.config
+type PluginIntegrity struct {
+	Require_Checksum  int8   `json:"require_checksum"`  /// refuse the files without a sha256 pin
+	Require_Signature int8   `json:"require_signature"` /// refuse the files without a signature
+	Trusted_Keys      string `json:"trusted_keys"`      /// directory of the trusted ed25519 public keys. relative to base path
+}
+
+// EventBus defines the event bus settings. "event_bus" in the core section of core.config
+type EventBus struct {
+	Queue_Size int    `json:"queue_size"` /// default max events waiting per subscription. eventbus.DEFAULT_QUEUE_SIZE if 0
+	Policy     string `json:"policy"`     /// default policy of the full subscription queue. drop or block. drop if empty
+	Mirror     int8   `json:"mirror"`     /// broadcast the published events via web socket monitoring
+}
+
+// UnitStartup holds the "unit_startup" settings of the core section of core.config
+type UnitStartup struct {
+	Concurrency int `json:"concurrency"` /// unit instances initialized & started at the same time. DEFAULT_STARTUP_CONCURRENCY if 0
+	Timeout     int `json:"timeout"`     /// seconds for each Initialize & Start call of an unit instance. DEFAULT_STARTUP_TIMEOUT if 0
+}
+
+// CoreExt holds the core section settings of core.config that are handled by the framework only
+type CoreExt struct {
+	Config_Watch     ConfigWatch     `json:"config_watch"`
+	Config_Source    ConfigSource    `json:"config_source"`
+	Plugin_Integrity PluginIntegrity `json:"plugin_integrity"`
+	Event_Bus        EventBus        `json:"event_bus"`
+	Unit_Startup     UnitStartup     `json:"unit_startup"`
+}
+
+// FMConfigExt holds the core.config settings that are handled by the framework only.
+// It is read from the same core.config, in addition to agnione/v1 FMConfig
+//
+// Plugins holds all the plugin categories of core.config keyed by category name (http, websocket, mailer, mq ...)
+type FMConfigExt struct {
+	Core    CoreExt                   `json:"core"`
+	Plugins map[string][]PluginConfig `json:"plugins"`
+}
+
+// PluginConfig holds a plugin entry of core.config with the framework only settings
+type PluginConfig struct {
+	apptypes.PlugIn
+	Max_Instances int    `json:"max_instances"` /// max live instances of the plugin. 0 is unlimited
+	Max_Idle      int    `json:"max_idle"`      /// max released instances kept for reuse. 0 is DEFAULT_MAX_IDLE
+	SHA256        string `json:"sha256"`        /// expected sha256 checksum of the .so file
+	Signature     string `json:"signature"`     /// ed25519 signature file of the .so file. <file>.sig if empty
+
+	Settings map[string]any `json:"settings"` /// passed to Configure of the plugin instances
+}
+
+// AppunitExt holds the framework only settings of an unit in app.config
+type AppunitExt struct {
+	Uname     string `json:"uname"`
+	SHA256    string `json:"sha256"`    /// expected sha256 checksum of the unit .so file
+	Signature string `json:"signature"` /// ed25519 signature file of the unit .so file. <file>.sig if empty
+
+	Plugins map[string]map[string]map[string]any `json:"plugins"`  /// plugin settings overrides by category & type
+	Requires []PluginRequirement                  `json:"requires"` /// plugins resolved before the unit is initialized
+
+	Depends_On      []string `json:"depends_on"`      /// units started before the unit and stopped after it
+	Startup_Timeout int      `json:"startup_timeout"` /// seconds for each Initialize & Start call of the unit instances. core unit_startup timeout if 0
+
+	Isolation     string `json:"isolation"`     /// "process" runs the unit in a child process. in-process if empty
+	Max_Restarts  int    `json:"max_restarts"`  /// restarts of a crashed unit process. DEFAULT_MAX_RESTARTS if 0, no restart if < 0
+	Restart_Delay int    `json:"restart_delay"` /// seconds before the first restart, doubled on each restart. DEFAULT_RESTART_DELAY if 0
+
+	Type string   `json:"type"` /// "exec" runs the path as an executable speaking stdio JSON-RPC, "wasm" loads a WebAssembly module. Go plugin if empty
+	Args []string `json:"args"` /// command line arguments of the exec unit
+	Env  []string `json:"env"`  /// KEY=VALUE environment entries of the exec unit, added to the framework environment
+
+	Allow_HTTP    []string `json:"allow_http"`    /// URL prefixes the wasm unit can request. HTTP is disabled if empty
+	Tick_Interval int      `json:"tick_interval"` /// milliseconds between agni_tick calls of the wasm unit. no ticks if 0
+}
+
+// PluginRequirement holds a plugin required by an unit
+type PluginRequirement struct {
+	Category string `json:"category"`
+	Type     string `json:"type"` /// DEFAULT_TYPE if empty
+}
+
+// AppConfigExt holds the app.config settings that are handled by the framework only.
+// It is read from the same app.config, in addition to agnione/v1 AppConfig
+type AppConfigExt struct {
+	Appunits []AppunitExt `json:"appunits"`
+}
+
+// PluginPoolStats holds the instance counts of a plugin. Shown in /status
+type PluginPoolStats struct {
+	Category      string `json:"category"`
+	Type          string `json:"type"`
+	Live          int    `json:"live"`   /// in use + idle instances
+	In_Use        int    `json:"in_use"`
+	Idle          int    `json:"idle"`
+	Max_Instances int    `json:"max_instances"`
+	Created       uint64 `json:"created"`
+	Reused        uint64 `json:"reused"`
+	Closed        uint64 `json:"closed"`
+}
+
+// EventBusStats holds the counters of the event bus
+type EventBusStats struct {
+	Published     uint64                   `json:"published"`
+	Unrouted      uint64                   `json:"unrouted"` /// published without a matching subscription
+	Delivered     uint64                   `json:"delivered"`
+	Dropped       uint64                   `json:"dropped"` /// dropped on full queues & by failed handlers
+	Subscriptions []EventSubscriptionStats `json:"subscriptions"`
+}
+
+// EventSubscriptionStats holds the counters of an event bus subscription
+type EventSubscriptionStats struct {
+	Pattern    string `json:"pattern"`
+	Policy     string `json:"policy"`
+	Queue_Size int    `json:"queue_size"`
+	Queued     int    `json:"queued"`
+	Delivered  uint64 `json:"delivered"`
+	Dropped    uint64 `json:"dropped"`
+}
+
+// JobStatus holds the run status of a scheduled job. Sent by /admin/jobs
+type JobStatus struct {
+	Name          string    `json:"name"`
+	Schedule      string    `json:"schedule"`
+	Singleton     bool      `json:"singleton"`
+	Paused        bool      `json:"paused"`
+	Running       bool      `json:"running"`
+	Instances     int       `json:"instances"` /// unit instances scheduling the job
+	Runs          uint64    `json:"runs"`
+	Failures      uint64    `json:"failures"`
+	Skipped       uint64    `json:"skipped"` /// runs skipped while the previous run is running or no instance is started
+	Last_Run      time.Time `json:"last_run"`
+	Last_Duration string    `json:"last_duration"`
+	Last_Error    string    `json:"last_error"`
+	Next_Run      time.Time `json:"next_run"` /// zero time if the cron expression never matches
+}
+
+// RoutineInfo holds a managed routine. Sent by /admin/routines
+type RoutineInfo struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Owner     string    `json:"owner"` /// unit name of the owner instance. framework for the routines of the framework
+	Started   time.Time `json:"started"`
+	Duration  string    `json:"duration"`  /// running time. run time of an exited routine
+	Cancelled time.Time `json:"cancelled"` /// owner stopped. zero time if not cancelled
+	Exited    time.Time `json:"exited"`
+	Panic     string    `json:"panic"`  /// panic & stack of the routine. empty if it returned
+	Leaked    bool      `json:"leaked"` /// still running LEAK_GRACE after it is cancelled
+}
+
+// RoutineStats holds the running & the last exited managed routines with the counters
+type RoutineStats struct {
+	Started  uint64        `json:"started"`
+	Panicked uint64        `json:"panicked"`
+	Leaked   uint64        `json:"leaked"`
+	Running  []RoutineInfo `json:"running"`
+	Exited   []RoutineInfo `json:"exited"` /// last exited first
+}
+
+// ConfigWatchEvent holds the outcome of a configuration file change. Broadcast via web socket monitoring
+type ConfigWatchEvent struct {
+	Event   string `json:"event"`
+	File    string `json:"file"`
+	Action  string `json:"action"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Time    string `json:"time"`
+}
+
+// PluginStatus defines the load status of a plugin in the plugin inventory
+type PluginStatus string
+
+const (
+	PLUGIN_LOADED     PluginStatus = "loaded"     /// loaded successfully at least once
+	PLUGIN_FAILED     PluginStatus = "failed"     /// last load failed
+	PLUGIN_NOT_LOADED PluginStatus = "not_loaded" /// installed, not loaded yet
+	PLUGIN_INVALID    PluginStatus = "invalid"    /// configured symbol is not exported by the file
+	PLUGIN_MISSING    PluginStatus = "missing"    /// configured file is not found
+)
+
+// PluginFileInfo holds the build metadata & the exported symbols of a plugin .so file
+type PluginFileInfo struct {
+	Path       string   `json:"path"`
+	Module     string   `json:"module"` /// main package path of the plugin
+	Go_Version string   `json:"go_version"`
+	Exports    []string `json:"exports"` /// exported symbols of the main package
+	Size       int64    `json:"size"`
+	Modified   string   `json:"modified"`
+	Error      string   `json:"error"`
+}
+
+// PluginLoadStats holds the load counts of a plugin .so file
+type PluginLoadStats struct {
+	Load_Count   uint64 `json:"load_count"`
+	Failed_Count uint64 `json:"failed_count"`
+	Last_Loaded  string `json:"last_loaded"`
+	Last_Error   string `json:"last_error"` /// error of the last failed load. cleared by a successful load
+}
+
+// PluginInventory holds an installed or configured plugin. Shown in /admin/plugins
+type PluginInventory struct {
+	Category   string       `json:"category"`
+	Type       string       `json:"type"` /// empty if the file is not configured in core.config
+	Path       string       `json:"path"`
+	Ifname     string       `json:"ifname"`
+	Configured bool         `json:"configured"`
+	Enabled    bool         `json:"enabled"`
+	Installed  bool         `json:"installed"` /// file exists
+	Status     PluginStatus `json:"status"`
+	Module     string       `json:"module"`
+	Go_Version string       `json:"go_version"`
+	Exports    []string     `json:"exports"`
+	PluginLoadStats
+}
+
+// PluginABIMismatch holds a difference between the build of the plugin and the framework
+type PluginABIMismatch struct {
+	Kind   string `json:"kind"`   /// go, module, package or setting
+	Name   string `json:"name"`   /// module path, package path or build setting name
+	Host   string `json:"host"`   /// version of the framework. empty if the framework does not use the module
+	Plugin string `json:"plugin"` /// version of the plugin
+}
+
+// PluginABIReport holds the result of the plugin build compatibility check. Shown in /admin/plugins/check
+type PluginABIReport struct {
+	Category        string              `json:"category"`
+	Type            string              `json:"type"`
+	File            string              `json:"file"`
+	Go_Version      string              `json:"go_version"`
+	Host_Go_Version string              `json:"host_go_version"`
+	Compatible      bool                `json:"compatible"`
+	Checked         bool                `json:"checked"` /// false if the build info of the file can not be read
+	Mismatches      []PluginABIMismatch `json:"mismatches"`
+	Error           string              `json:"error"`
+}
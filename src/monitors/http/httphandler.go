@@ -17,6 +17,20 @@
 
 	Ajith de Silva		29/01/2024	Added 		Added multiple API endpoints
 
+	Ajith de Silva		19/10/2026	Added 		Secret references in apikeys.config
+
+	Ajith de Silva		19/10/2026	Added 		sha256 hashed keys in apikeys.config
+
+	Ajith de Silva		19/10/2026	Added 		Event bus counters in /status
+
+	Ajith de Silva		19/10/2026	Added 		Registered services in /status
+
+	Ajith de Silva		19/10/2026	Updated 	Unit stop, start, restart & status endpoints
+
+	Ajith de Silva		19/10/2026	Added 		Scheduled jobs endpoints
+
+	Ajith de Silva		19/10/2026	Added 		Managed routines endpoint
+
 #########################################################################################
 */
 package httmonitor
@@ -30,15 +44,21 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 
 	cors "agnione.appfm/src/monitors/lib"
+	ihttpm "agnione.appfm/src/monitors/http/ihttpmonitor"
+	autls "agnione.appfm/src/utils"
 )
 
 // RESPONSE_FORMAT define the default response format to application/JSON
 const RESPONSE_FORMAT = "application/json"
 
+// API_KEY_HASH define the prefix of the hashed api keys in apikeys.config
+const API_KEY_HASH = "sha256:"
+
 
 // HttpMonitor struct of the HttpMonitor
 type HttpMonitor struct {
@@ -56,15 +76,39 @@ func (hm *HttpMonitor) Initialize(pApp_Instance iappfw.IAgniApp) {
 	hm.appInstance = pApp_Instance
 	hm.httpServerExitDone = &sync.WaitGroup{}
 	
-	var _err error
-	
+	hm.Reload_APIKeys() /// load the api keys to REST authentication
+}
+
+// Reload_APIKeys reads the api keys from config/apikeys.config
+// A line can be the key, a secret reference (env://, secret://file, secret://local) or sha256:<hex> of the key
+// Returns true,nil if the keys are loaded. Unless returns false,error and keeps the current keys
+func (hm *HttpMonitor) Reload_APIKeys() (bool, error) {
+
 	var _temp_path=*hm.appInstance.App_Path() + "config/apikeys.config"
-	/// load the api keys to REST authentication
-	hm.apikeys, _err = hm.appInstance.Get_FileContent_Lines(&_temp_path)
+	
+	_apikeys, _err := hm.appInstance.Get_FileContent_Lines(&_temp_path)
 	if _err != nil {
 		hm.appInstance.Write2Log("HTTP Monitor :: failed to read the " + _temp_path + " - " +  _err.Error(), apptypes.LOG_ERROR)
+		return false, _err
 	}
-	_temp_path=""
+	
+	_keys := make([]string, 0, len(*_apikeys))
+	for _, _key := range *_apikeys {
+		_key = strings.TrimSpace(_key)
+		if len(_key) == 0 {
+			continue
+		}
+		if autls.Is_Secret_Ref(_key) {
+			if _key, _err = autls.Resolve_Secret(_key); _err != nil {
+				hm.appInstance.Write2Log("HTTP Monitor :: failed to resolve the api key - " +  _err.Error(), apptypes.LOG_ERROR)
+				continue
+			}
+		}
+		_keys = append(_keys, _key)
+	}
+
+	hm.apikeys = &_keys
+	return true, nil
 }
 
 func (hm *HttpMonitor) DeInitialize() {
@@ -102,9 +146,11 @@ func (hm *HttpMonitor) is_authorized(pRequest *http.Request) bool {
 		return false
 	}
 
+	_hashed := API_KEY_HASH + autls.SHA256_Hex([]byte(_apiKey))
+
 	var _value string
 	for _, _value = range *hm.apikeys {
-		if _value == _apiKey {
+		if _value == _apiKey || _value == _hashed {
 			return true
 		}
 	}
@@ -143,16 +189,32 @@ func (hm *HttpMonitor) Start(pAddress *string, pHttp_Port *int) {
 		_mux.Handle("/admin/monitor/stop", hm.authMiddleware(http.HandlerFunc(hm.stopmonitor)))
 		_mux.Handle("/admin/config/reload", hm.authMiddleware(http.HandlerFunc(hm.config_reload)))
 		_mux.Handle("/admin/config/save", hm.authMiddleware(http.HandlerFunc(hm.config_save)))
+		_mux.Handle("/admin/config/apply", hm.authMiddleware(http.HandlerFunc(hm.config_apply)))
+		_mux.Handle("/admin/config/apply/status", hm.authMiddleware(http.HandlerFunc(hm.config_apply_status)))
+
+		/// plugins management
+		_mux.Handle("/admin/plugins", hm.authMiddleware(http.HandlerFunc(hm.plugins_inventory)))
+		_mux.Handle("/admin/plugins/check", hm.authMiddleware(http.HandlerFunc(hm.plugins_check)))
 
 		/// sets the log level at runtime
 		_mux.Handle("/admin/log/setlevel", hm.authMiddleware(http.HandlerFunc(hm.set_log_level)))
 		
 		/// application units management
 		_mux.Handle("/admin/units", hm.authMiddleware(http.HandlerFunc(hm.list_units)))
-		_mux.Handle("/admin/unit/stop", hm.authMiddleware(http.HandlerFunc(hm.stop_unit)))
+		_mux.Handle("/admin/unit/{name}/stop", hm.authMiddleware(http.HandlerFunc(hm.stop_unit)))
 		_mux.Handle("/admin/unit/{name}/start", hm.authMiddleware(http.HandlerFunc(hm.start_unit)))
-		_mux.Handle("/admin/unit/{name}/restart?force=", hm.authMiddleware(http.HandlerFunc(hm.restart_unit)))
+		_mux.Handle("/admin/unit/{name}/restart", hm.authMiddleware(http.HandlerFunc(hm.restart_unit)))
 		_mux.Handle("/admin/unit/{name}/status", hm.authMiddleware(http.HandlerFunc(hm.status_unit)))
+		_mux.Handle("/admin/unit/{name}/kill", hm.authMiddleware(http.HandlerFunc(hm.kill_unit)))
+
+		/// scheduled jobs of the units
+		_mux.Handle("/admin/jobs", hm.authMiddleware(http.HandlerFunc(hm.list_jobs)))
+		_mux.Handle("/admin/jobs/{name}/trigger", hm.authMiddleware(http.HandlerFunc(hm.trigger_job)))
+		_mux.Handle("/admin/jobs/{name}/pause", hm.authMiddleware(http.HandlerFunc(hm.pause_job)))
+		_mux.Handle("/admin/jobs/{name}/resume", hm.authMiddleware(http.HandlerFunc(hm.pause_job)))
+
+		/// managed routines of the units
+		_mux.Handle("/admin/routines", hm.authMiddleware(http.HandlerFunc(hm.list_routines)))
 	
 		hm.isstarted = true
 	
@@ -205,10 +267,35 @@ func (hm *HttpMonitor) live(pResWriter http.ResponseWriter, pRequest *http.Reque
 // status sends the status message
 func (hm *HttpMonitor) status(pResWriter http.ResponseWriter, pRequest *http.Request) {
 
-	if _message, _err := json.Marshal(hm.appInstance.Get_App_Status()); _err == nil {
-		hm.setJsonResp(_message, http.StatusOK, pResWriter)
-		_message=nil
+	_message, _err := json.Marshal(hm.appInstance.Get_App_Status())
+	if _err != nil {
+		return
+	}
+
+	/// add the plugin instance counts, event bus counters & services to the status
+	_plugins, _with_plugins := hm.appInstance.(ihttpm.IPluginStats)
+	_events, _with_events := hm.appInstance.(ihttpm.IEventStats)
+	_services, _with_services := hm.appInstance.(ihttpm.IServiceList)
+	if _with_plugins || _with_events || _with_services {
+		_status := make(map[string]any)
+		if json.Unmarshal(_message, &_status) == nil {
+			if _with_plugins {
+				_status["plugins"] = _plugins.Plugin_Stats()
+			}
+			if _with_events {
+				_status["events"] = _events.Event_Stats()
+			}
+			if _with_services {
+				_status["services"] = _services.Services_List()
+			}
+			if _with_stats, _err := json.Marshal(_status); _err == nil {
+				_message = _with_stats
+			}
+		}
 	}
+
+	hm.setJsonResp(_message, http.StatusOK, pResWriter)
+	_message=nil
 }
 
 // info sends the information of the application
@@ -389,6 +476,121 @@ func (hm *HttpMonitor) config_save(pResWriter http.ResponseWriter, pRequest *htt
 	}
 }
 
+// config_apply stages the posted configuration and restarts the framework with it.
+// If the units failed to start within the grace period, previous configuration is restored.
+//
+// optional query parameter grace sets the grace period in seconds.
+func (hm *HttpMonitor) config_apply(pResWriter http.ResponseWriter, pRequest *http.Request) {
+
+	if pRequest.Method != "POST" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+		return
+	}
+
+	_iapply, _ok := hm.appInstance.(ihttpm.IConfigApply)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	_status:=struct{Status string}{Status: "OK"}
+	var _message []byte
+	
+	defer func ()  {
+		_message=nil
+	}()
+
+	_grace := 0
+	if _value := pRequest.URL.Query().Get("grace"); _value != "" {
+		var _err error
+		if _grace, _err = strconv.Atoi(_value); _err != nil || _grace < 0 {
+			http.Error(pResWriter, "invalid grace period", http.StatusBadRequest)
+			return
+		}
+	}
+
+	_bData, _err := io.ReadAll(pRequest.Body)
+	
+	defer func ()  {
+		_bData=nil
+	}()
+
+	if _err != nil || len(_bData) == 0 {
+		_status.Status="ERROR. Invalid content"
+		_message, _ = json.Marshal(_status)
+		hm.setJsonResp(_message, http.StatusBadRequest, pResWriter)
+		return
+	}
+
+	if _, _err = _iapply.Apply_App_Config(&_bData, _grace); _err != nil {
+		_status.Status="ERROR. " + _err.Error()
+		_message, _ = json.Marshal(_status)
+		hm.setJsonResp(_message, http.StatusBadRequest, pResWriter)
+		return
+	}
+
+	_status.Status="STAGED. restarting with the new configuration. check /admin/config/apply/status for the result"
+	_message, _ = json.Marshal(_status)
+	hm.setJsonResp(_message, http.StatusAccepted, pResWriter)
+}
+
+// config_apply_status sends the state of the configuration apply in progress or the result of the last apply
+func (hm *HttpMonitor) config_apply_status(pResWriter http.ResponseWriter, pRequest *http.Request) {
+
+	_iapply, _ok := hm.appInstance.(ihttpm.IConfigApply)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	_result, _err := _iapply.Config_Apply_Status()
+	if _err != nil {
+		_message, _ := json.Marshal(struct{Status string}{Status: _err.Error()})
+		hm.setJsonResp(_message, http.StatusNotFound, pResWriter)
+		return
+	}
+
+	if _message, _err := json.Marshal(_result); _err == nil {
+		hm.setJsonResp(_message, http.StatusOK, pResWriter)
+		_message=nil
+	}
+}
+
+// plugins_inventory sends the installed & configured plugins with their load status
+func (hm *HttpMonitor) plugins_inventory(pResWriter http.ResponseWriter, pRequest *http.Request) {
+
+	if pRequest.Method != "GET" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+		return
+	}
+
+	_iinventory, _ok := hm.appInstance.(ihttpm.IPluginInventory)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	if _message, _err := json.Marshal(_iinventory.Plugins_Inventory()); _err == nil {
+		hm.setJsonResp(_message, http.StatusOK, pResWriter)
+		_message=nil
+	}
+}
+
+// plugins_check sends the build compatibility of the configured plugins & units with the framework
+func (hm *HttpMonitor) plugins_check(pResWriter http.ResponseWriter, pRequest *http.Request) {
+
+	_icheck, _ok := hm.appInstance.(ihttpm.IPluginCheck)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	if _message, _err := json.Marshal(_icheck.Check_Plugins()); _err == nil {
+		hm.setJsonResp(_message, http.StatusOK, pResWriter)
+		_message=nil
+	}
+}
+
 //// config_reload reloads the configuration
 func (hm *HttpMonitor) list_units(pResWriter http.ResponseWriter, pRequest *http.Request) {
 	
@@ -431,67 +633,221 @@ func (hm *HttpMonitor) list_units(pResWriter http.ResponseWriter, pRequest *http
 	
 }
 
+// stop_unit stops the instances of the unit. With ?force=true, started units depending on it are stopped first
 func (hm *HttpMonitor) stop_unit(pResWriter http.ResponseWriter, pRequest *http.Request) {
-	if !hm.is_authorized(pRequest) {
-		hm.setJsonResp([]byte(""), http.StatusUnauthorized, pResWriter)
+
+	if pRequest.Method != "POST" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
 		return
 	}
-	
-	if pRequest.Method != "GET" {
-		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+
+	_icontrol, _ok := hm.appInstance.(ihttpm.IUnitControl)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
 		return
 	}
-	
-	
-	_unit_name := pRequest.URL.Query().Get("name")
-	if _unit_name==""{
-		http.Error(pResWriter, "missing unit name parameter", http.StatusBadRequest)
+
+	_unit_name := pRequest.PathValue("name")
+	_force, _ := strconv.ParseBool(pRequest.URL.Query().Get("force"))
+
+	if _, _err := _icontrol.Unit_Stop(&_unit_name, _force); _err != nil {
+		hm.setJsonResp([]byte(_err.Error()), http.StatusConflict, pResWriter)
 		return
 	}
-	
-	_force:=pRequest.URL.Query().Get("force")
-	
-	println("stop uint " + _unit_name + " force=" + _force)
-	
-	hm.setJsonResp([]byte("TO DD : stop uint " + _unit_name + " force=" + _force), http.StatusOK, pResWriter)
 
+	hm.appInstance.Write2Log("unit "+_unit_name+" stopped via HTTP monitor", apptypes.LOG_INFO)
+	hm.setJsonResp([]byte("stopped unit "+_unit_name), http.StatusOK, pResWriter)
 }
 
+// start_unit starts the stopped unit, after its depends_on units are checked to be started
 func (hm *HttpMonitor) start_unit(pResWriter http.ResponseWriter, pRequest *http.Request) {
-	_unit_name := pRequest.URL.Query().Get("name")
-	if _unit_name==""{
-		http.Error(pResWriter, "missing parameter", http.StatusBadRequest)
+
+	if pRequest.Method != "POST" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+		return
 	}
-	
-	println("start uint " + _unit_name)
-	hm.setJsonResp([]byte("TO DD : start uint " + _unit_name), http.StatusOK, pResWriter)
+
+	_icontrol, _ok := hm.appInstance.(ihttpm.IUnitControl)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	_unit_name := pRequest.PathValue("name")
+
+	if _, _err := _icontrol.Unit_Start(&_unit_name); _err != nil {
+		hm.setJsonResp([]byte(_err.Error()), http.StatusConflict, pResWriter)
+		return
+	}
+
+	hm.appInstance.Write2Log("unit "+_unit_name+" started via HTTP monitor", apptypes.LOG_INFO)
+	hm.setJsonResp([]byte("started unit "+_unit_name), http.StatusOK, pResWriter)
 }
 
+// restart_unit stops the unit and loads it again. With ?force=true, started units depending on it are restarted too
 func (hm *HttpMonitor) restart_unit(pResWriter http.ResponseWriter, pRequest *http.Request) {
-	_unit_name := pRequest.URL.Query().Get("name")
-	
-	if _unit_name==""{
-		http.Error(pResWriter, "missing parameter", http.StatusBadRequest)
+
+	if pRequest.Method != "POST" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+		return
+	}
+
+	_icontrol, _ok := hm.appInstance.(ihttpm.IUnitControl)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	_unit_name := pRequest.PathValue("name")
+	_force, _ := strconv.ParseBool(pRequest.URL.Query().Get("force"))
+
+	if _, _err := _icontrol.Unit_Restart(&_unit_name, _force); _err != nil {
+		hm.setJsonResp([]byte(_err.Error()), http.StatusConflict, pResWriter)
+		return
+	}
+
+	hm.appInstance.Write2Log("unit "+_unit_name+" restarted via HTTP monitor", apptypes.LOG_INFO)
+	hm.setJsonResp([]byte("restarted unit "+_unit_name), http.StatusOK, pResWriter)
+}
+
+// kill_unit kills the unit processes of an unit with process isolation. Processes are restarted by the framework
+func (hm *HttpMonitor) kill_unit(pResWriter http.ResponseWriter, pRequest *http.Request) {
+
+	if pRequest.Method != "POST" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+		return
+	}
+
+	_iprocess, _ok := hm.appInstance.(ihttpm.IUnitProcess)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	_unit_name := pRequest.PathValue("name")
+
+	_killed, _err := _iprocess.Unit_Kill(&_unit_name)
+	if _err != nil {
+		hm.setJsonResp([]byte(_err.Error()), http.StatusBadRequest, pResWriter)
+		return
+	}
+
+	hm.setJsonResp([]byte("killed " + strconv.Itoa(_killed) + " unit processes of " + _unit_name), http.StatusOK, pResWriter)
+}
+
+// list_jobs sends the run status of the scheduled jobs
+func (hm *HttpMonitor) list_jobs(pResWriter http.ResponseWriter, pRequest *http.Request) {
+
+	if pRequest.Method != "GET" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+		return
+	}
+
+	_ijobs, _ok := hm.appInstance.(ihttpm.IJobControl)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	if _message, _err := json.Marshal(_ijobs.Jobs_List()); _err == nil {
+		hm.setJsonResp(_message, http.StatusOK, pResWriter)
+		_message=nil
+	}
+}
+
+// trigger_job starts a run of the scheduled job now
+func (hm *HttpMonitor) trigger_job(pResWriter http.ResponseWriter, pRequest *http.Request) {
+
+	if pRequest.Method != "POST" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+		return
+	}
+
+	_ijobs, _ok := hm.appInstance.(ihttpm.IJobControl)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	_job_name := pRequest.PathValue("name")
+
+	if _err := _ijobs.Job_Trigger(_job_name); _err != nil {
+		hm.setJsonResp([]byte(_err.Error()), http.StatusBadRequest, pResWriter)
+		return
+	}
+
+	hm.setJsonResp([]byte("triggered job " + _job_name), http.StatusOK, pResWriter)
+}
+
+// pause_job pauses or resumes the scheduled runs of the job, by the path of the request
+func (hm *HttpMonitor) pause_job(pResWriter http.ResponseWriter, pRequest *http.Request) {
+
+	if pRequest.Method != "POST" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+		return
+	}
+
+	_ijobs, _ok := hm.appInstance.(ihttpm.IJobControl)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	_job_name := pRequest.PathValue("name")
+	_pause := strings.HasSuffix(pRequest.URL.Path, "/pause")
+
+	if _err := _ijobs.Job_Pause(_job_name, _pause); _err != nil {
+		hm.setJsonResp([]byte(_err.Error()), http.StatusBadRequest, pResWriter)
+		return
+	}
+
+	if _pause {
+		hm.setJsonResp([]byte("paused job " + _job_name), http.StatusOK, pResWriter)
+	} else {
+		hm.setJsonResp([]byte("resumed job " + _job_name), http.StatusOK, pResWriter)
 	}
-	
-	_force:=pRequest.URL.Query().Get("force")
-	
-	println("restart uint " + _unit_name + " force=" + _force)
-	hm.setJsonResp([]byte("TO DD : restart uint " + _unit_name + " force=" + _force), http.StatusOK, pResWriter)
 }
 
+// list_routines sends the running & the last exited managed routines with their owner units
+func (hm *HttpMonitor) list_routines(pResWriter http.ResponseWriter, pRequest *http.Request) {
+
+	if pRequest.Method != "GET" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+		return
+	}
+
+	_iroutines, _ok := hm.appInstance.(ihttpm.IRoutineList)
+	if !_ok {
+		hm.setJsonResp([]byte(""), http.StatusNotImplemented, pResWriter)
+		return
+	}
+
+	if _message, _err := json.Marshal(_iroutines.Routines_List()); _err == nil {
+		hm.setJsonResp(_message, http.StatusOK, pResWriter)
+		_message=nil
+	}
+}
+
+// status_unit sends the status of the unit
 func (hm *HttpMonitor) status_unit(pResWriter http.ResponseWriter, pRequest *http.Request) {
-	_unit_name := pRequest.URL.Query().Get("name")
-	
-	if _unit_name==""{
-		http.Error(pResWriter, "missing parameter", http.StatusBadRequest)
+
+	if pRequest.Method != "GET" {
+		hm.setJsonResp([]byte(""), http.StatusMethodNotAllowed, pResWriter)
+		return
+	}
+
+	_unit_name := pRequest.PathValue("name")
+
+	_status, _err := hm.appInstance.Unit_Status(&_unit_name)
+	if _err != nil {
+		hm.setJsonResp([]byte(_err.Error()), http.StatusNotFound, pResWriter)
+		return
+	}
+
+	if _message, _err := json.Marshal(_status); _err == nil {
+		hm.setJsonResp(_message, http.StatusOK, pResWriter)
+		_message=nil
 	}
-	println("send status of uint " + _unit_name)
-	
-	hm.appInstance.Unit_Status(&_unit_name)
-	//for  _unit:=range hm.appInstance.Ap
-	hm.setJsonResp([]byte("TO DD : send status of uint " + _unit_name), http.StatusOK, pResWriter)
-	
 }
 
 var IHTTPMonitor HttpMonitor
@@ -0,0 +1,68 @@
+package ihttpmonitor
+
+import fmtypes "agnione.appfm/src/fmtypes"
+
+// IConfigApply defines the two-phase configuration apply functions of the framework.
+// HttpMonitor checks the application instance for it before serving /admin/config/apply endpoints.
+type IConfigApply interface {
+	Apply_App_Config(pAppConfigData *[]byte, pGrace_Period int) (bool, error)
+	Config_Apply_Status() (*fmtypes.ConfigApplyResult, error)
+}
+
+// IPluginStats defines the plugin instance counts function of the framework.
+// HttpMonitor adds the counts to /status, if the application instance implements it.
+type IPluginStats interface {
+	Plugin_Stats() []fmtypes.PluginPoolStats
+}
+
+// IEventStats defines the event bus counters function of the framework.
+// HttpMonitor adds the counters to /status, if the application instance implements it.
+type IEventStats interface {
+	Event_Stats() fmtypes.EventBusStats
+}
+
+// IServiceList defines the service registry list function of the framework.
+// HttpMonitor adds the services & their started provider instances to /status, if the application instance implements it.
+type IServiceList interface {
+	Services_List() map[string]int
+}
+
+// IPluginCheck defines the plugin build compatibility check function of the framework.
+// HttpMonitor checks the application instance for it before serving /admin/plugins/check
+type IPluginCheck interface {
+	Check_Plugins() []fmtypes.PluginABIReport
+}
+
+// IUnitProcess defines the unit process control function of the framework.
+// HttpMonitor checks the application instance for it before serving /admin/unit/{name}/kill
+type IUnitProcess interface {
+	Unit_Kill(pUnitName *string) (int, error)
+}
+
+// IUnitControl defines the unit stop, start & restart functions of the framework.
+// HttpMonitor checks the application instance for it before serving /admin/unit/{name}/stop, start & restart
+type IUnitControl interface {
+	Unit_Stop(pUnitName *string, pForce bool) (bool, error)
+	Unit_Start(pUnitName *string) (bool, error)
+	Unit_Restart(pUnitName *string, pForce bool) (bool, error)
+}
+
+// IJobControl defines the scheduled job functions of the framework.
+// HttpMonitor checks the application instance for it before serving /admin/jobs endpoints
+type IJobControl interface {
+	Jobs_List() []fmtypes.JobStatus
+	Job_Trigger(pName string) error
+	Job_Pause(pName string, pPause bool) error
+}
+
+// IRoutineList defines the managed routine list function of the framework.
+// HttpMonitor checks the application instance for it before serving /admin/routines
+type IRoutineList interface {
+	Routines_List() fmtypes.RoutineStats
+}
+
+// IPluginInventory defines the plugin inventory function of the framework.
+// HttpMonitor checks the application instance for it before serving /admin/plugins
+type IPluginInventory interface {
+	Plugins_Inventory() []fmtypes.PluginInventory
+}
@@ -0,0 +1,71 @@
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   pluginreg - plugin injection
+
+	Objective     :   Deliver the plugins declared as "requires" of an unit to the unit instances,
+						before the unit is initialized
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package pluginreg
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// IPluginInjectable is implemented by the units that accept the required plugin instances.
+// Inject_Plugins is called before IAppUnit.Initialize with the instances keyed by Plugin_Key.
+// Instances are owned by the unit and closed when the unit is stopped.
+type IPluginInjectable interface {
+	Inject_Plugins(pPlugins map[string]any) error
+}
+
+// Plugin_Key returns the key of the plugin instance in the injected plugins. <category>/<type>
+func Plugin_Key(pCategory string, pType string) string {
+
+	if len(pType) == 0 {
+		pType = DEFAULT_TYPE
+	}
+	return strings.ToLower(pCategory) + "/" + strings.ToLower(pType)
+}
+
+// Injected returns the injected plugin instance of the given category & type as T
+func Injected[T any](pPlugins map[string]any, pCategory string, pType string) (T, error) {
+
+	var _none T
+
+	_instance, _found := pPlugins[Plugin_Key(pCategory, pType)]
+	if !_found {
+		return _none, errors.New("plugin " + Plugin_Key(pCategory, pType) + " is not injected. add it to requires of the unit")
+	}
+
+	_typed, _ok := _instance.(T)
+	if !_ok {
+		return _none, errors.New("plugin " + Plugin_Key(pCategory, pType) + " does not implement " + reflect.TypeOf((*T)(nil)).Elem().String())
+	}
+	return _typed, nil
+}
@@ -0,0 +1,60 @@
+package pluginreg
+
+import (
+	"testing"
+)
+
+func TestPlugin_Key(t *testing.T) {
+
+	_cases := []struct {
+		category string
+		type_    string
+		want     string
+	}{
+		{"http", "default", "http/default"},
+		{"MQ", "Memory", "mq/memory"},
+		{"mailer", "", "mailer/default"},
+	}
+
+	for _, _case := range _cases {
+		if _got := Plugin_Key(_case.category, _case.type_); _got != _case.want {
+			t.Errorf("Plugin_Key(%q, %q) = %q, want %q", _case.category, _case.type_, _got, _case.want)
+		}
+	}
+}
+
+func TestInjected(t *testing.T) {
+
+	_plugins := map[string]any{
+		Plugin_Key("http", ""):     &client{name: "http"},
+		Plugin_Key("mq", "memory"): &other_client{},
+	}
+
+	_cases := []struct {
+		name     string
+		category string
+		type_    string
+		error    string
+	}{
+		{"injected", "HTTP", "default", ""},
+		{"not injected", "mailer", "", "plugin mailer/default is not injected. add it to requires of the unit"},
+		{"not implemented", "mq", "memory", "plugin mq/memory does not implement pluginreg.iclient"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			_client, _err := Injected[iclient](_plugins, _case.category, _case.type_)
+
+			_got := ""
+			if _err != nil {
+				_got = _err.Error()
+			}
+			if _got != _case.error {
+				t.Fatalf("expected error %q, got %q", _case.error, _got)
+			}
+			if _err == nil && _client.Call() != "http" {
+				t.Fatalf("unexpected instance %v", _client)
+			}
+		})
+	}
+}
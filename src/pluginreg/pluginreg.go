@@ -0,0 +1,156 @@
+// pluginreg package provides the plugin registry of the AgniOne application framework
+//
+// Plugins are configured in core.config by category and type. Framework loads the plugin of the
+// given category & type and asserts the loaded symbol to the category interface via the Hook
+// registered for the category. Categories without a Hook use the Default_Hook.
+//
+// A new plugin category needs only the core.config entry and an interface package. eg:
+//
+//	_client, _err := pluginreg.Get[imq.IMQClient](app, "mq", "default")
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   pluginreg
+
+	Objective     :   Registry of the plugin categories and their interface assertion hooks
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package pluginreg
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// DEFAULT_TYPE define the plugin type used when the type is not given
+const DEFAULT_TYPE = "default"
+
+// Hook asserts the loaded plugin symbol to the category interface and returns a new plugin instance
+type Hook func(pSymbol any) (any, error)
+
+// IPluginProvider is implemented by the framework to return the plugin instance of the given category & type
+type IPluginProvider interface {
+	Get_Plugin(pCategory string, pType string) (any, error)
+}
+
+// iPluginNew is the factory method that every plugin symbol exposes
+type iPluginNew interface {
+	New() interface{}
+}
+
+var hooks = make(map[string]Hook)
+var hooks_lock = &sync.RWMutex{}
+
+// Register registers the interface assertion hook of the given plugin category.
+// Registering the same category again replaces the hook.
+func Register(pCategory string, pHook Hook) {
+	hooks_lock.Lock()
+	defer hooks_lock.Unlock()
+
+	hooks[strings.ToLower(pCategory)] = pHook
+}
+
+// Hook_For returns the hook of the given plugin category. Returns Default_Hook if not registered.
+func Hook_For(pCategory string) Hook {
+	hooks_lock.RLock()
+	defer hooks_lock.RUnlock()
+
+	if _hook, _found := hooks[strings.ToLower(pCategory)]; _found {
+		return _hook
+	}
+	return Default_Hook
+}
+
+// Categories returns the plugin categories that have a registered hook
+func Categories() []string {
+	hooks_lock.RLock()
+	defer hooks_lock.RUnlock()
+
+	_categories := make([]string, 0, len(hooks))
+	for _category := range hooks {
+		_categories = append(_categories, _category)
+	}
+	return _categories
+}
+
+// Default_Hook creates a new instance via New() interface{} of the loaded symbol.
+// Instance is returned as it is. Caller asserts it to the category interface.
+func Default_Hook(pSymbol any) (any, error) {
+
+	_factory, _ok := pSymbol.(iPluginNew)
+	if !_ok {
+		return nil, fmt.Errorf("unexpected type from module symbols %T. New() is not found", pSymbol)
+	}
+
+	_instance := _factory.New()
+	if _instance == nil {
+		return nil, errors.New("failed to creates a instance of the plugin")
+	}
+	return _instance, nil
+}
+
+// Hook_Of returns a hook that asserts the loaded symbol to T, creates the instance via pNew
+// and asserts the instance to T.
+func Hook_Of[T any](pNew func(T) any) Hook {
+	return func(pSymbol any) (any, error) {
+
+		_lib, _ok := pSymbol.(T)
+		if !_ok {
+			return nil, fmt.Errorf("unexpected type from module symbols %T", pSymbol)
+		}
+
+		_instance, _ok := pNew(_lib).(T)
+		if !_ok {
+			return nil, fmt.Errorf("failed to creates a instance from interface of %T", _lib)
+		}
+		return _instance, nil
+	}
+}
+
+// Get returns the plugin instance of the given category & type as T.
+// pProvider is the framework instance given to the units & plugins
+func Get[T any](pProvider any, pCategory string, pType string) (T, error) {
+
+	var _none T
+
+	_provider, _ok := pProvider.(IPluginProvider)
+	if !_ok {
+		return _none, errors.New("plugin registry is not supported by the framework instance")
+	}
+
+	_instance, _err := _provider.Get_Plugin(pCategory, pType)
+	if _err != nil {
+		return _none, _err
+	}
+
+	_typed, _ok := _instance.(T)
+	if !_ok {
+		return _none, fmt.Errorf("plugin %s(%s) does not implement %v", pCategory, pType, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return _typed, nil
+}
@@ -0,0 +1,157 @@
+package pluginreg
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// iclient is a category interface of the registry tests
+type iclient interface {
+	New() interface{}
+	Call() string
+}
+
+// client is a plugin symbol & instance of the registry tests
+type client struct {
+	name string
+}
+
+func (c *client) New() interface{} { return &client{name: c.name + "-instance"} }
+func (c *client) Call() string     { return c.name }
+
+// other_client creates the instances not implementing iclient
+type other_client struct{}
+
+func (c *other_client) New() interface{} { return "not a client" }
+
+// nil_client creates nil instances
+type nil_client struct{}
+
+func (c *nil_client) New() interface{} { return nil }
+
+func TestHook_For(t *testing.T) {
+
+	_hook := func(pSymbol any) (any, error) { return "registered", nil }
+	Register("Test_Category", _hook)
+
+	if _instance, _ := Hook_For("test_category")(nil); _instance != "registered" {
+		t.Fatalf("expected the registered hook regardless of the case, got %v", _instance)
+	}
+	if !slices.Contains(Categories(), "test_category") {
+		t.Fatalf("category is not listed %v", Categories())
+	}
+
+	/// categories without a hook use Default_Hook
+	_instance, _err := Hook_For("not_registered")(&client{name: "default"})
+	if _err != nil || _instance.(*client).name != "default-instance" {
+		t.Fatalf("expected the instance of Default_Hook, got %v %v", _instance, _err)
+	}
+}
+
+func TestDefault_Hook(t *testing.T) {
+
+	_cases := []struct {
+		name   string
+		symbol any
+		error  string
+	}{
+		{"new", &client{name: "c"}, ""},
+		{"any instance", &other_client{}, ""},
+		{"no New", "symbol", "New() is not found"},
+		{"nil instance", &nil_client{}, "failed to creates a instance of the plugin"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			_instance, _err := Default_Hook(_case.symbol)
+			if len(_case.error) > 0 {
+				if _err == nil || !strings.Contains(_err.Error(), _case.error) {
+					t.Fatalf("expected error %q, got %v", _case.error, _err)
+				}
+				return
+			}
+			if _err != nil || _instance == nil {
+				t.Fatalf("expected an instance, got %v %v", _instance, _err)
+			}
+		})
+	}
+}
+
+func TestHook_Of(t *testing.T) {
+
+	_hook := Hook_Of(func(pLib iclient) any { return pLib.New() })
+
+	_cases := []struct {
+		name   string
+		symbol any
+		want   string
+		error  string
+	}{
+		{"instance", &client{name: "c"}, "c-instance", ""},
+		{"symbol type", &other_client{}, "", "unexpected type from module symbols"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			_instance, _err := _hook(_case.symbol)
+			if len(_case.error) > 0 {
+				if _err == nil || !strings.Contains(_err.Error(), _case.error) {
+					t.Fatalf("expected error %q, got %v", _case.error, _err)
+				}
+				return
+			}
+			if _err != nil || _instance.(iclient).Call() != _case.want {
+				t.Fatalf("expected instance %s, got %v %v", _case.want, _instance, _err)
+			}
+		})
+	}
+
+	/// instance created by New should implement the interface too
+	_wrong := Hook_Of(func(pLib *other_client) any { return pLib.New() })
+	if _, _err := _wrong(&other_client{}); _err == nil || !strings.Contains(_err.Error(), "failed to creates a instance") {
+		t.Fatalf("expected error for the instance not implementing the interface, got %v", _err)
+	}
+}
+
+// provider is the framework instance of the Get tests
+type provider struct {
+	instance any
+	err      error
+}
+
+func (p *provider) Get_Plugin(pCategory string, pType string) (any, error) {
+	return p.instance, p.err
+}
+
+func TestGet(t *testing.T) {
+
+	_cases := []struct {
+		name     string
+		provider any
+		error    string
+	}{
+		{"instance", &provider{instance: &client{name: "c"}}, ""},
+		{"not a provider", "framework", "plugin registry is not supported by the framework instance"},
+		{"provider error", &provider{err: errors.New("Plugin http(default) is NOT found")}, "Plugin http(default) is NOT found"},
+		{"not implemented", &provider{instance: &other_client{}}, "plugin http(default) does not implement pluginreg.iclient"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			_client, _err := Get[iclient](_case.provider, "http", "default")
+
+			_got := ""
+			if _err != nil {
+				_got = _err.Error()
+			}
+			if _got != _case.error {
+				t.Fatalf("expected error %q, got %q", _case.error, _got)
+			}
+			if _err == nil && _client.Call() != "c" {
+				t.Fatalf("unexpected instance %v", _client)
+			}
+		})
+	}
+}
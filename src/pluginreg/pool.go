@@ -0,0 +1,353 @@
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   pluginreg - plugin instance pool
+
+	Objective     :   Managed plugin instances per category & type with max instances,
+						idle reuse, explicit release and close by owner
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+	Ajith de Silva		19/10/2026	Updated 	Added the framework owner of the instances of the legacy getters
+
+#########################################################################################
+*/
+package pluginreg
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// DEFAULT_MAX_IDLE define the max released instances kept for reuse per plugin, if not set in core.config
+const DEFAULT_MAX_IDLE int = 2
+
+// FRAMEWORK_OWNER define the owner of the instances acquired without an unit name. eg: Get_Plugin, Get_RESTClient.
+// They are closed when the framework stops, unless released before
+const FRAMEWORK_OWNER = "framework"
+
+// Factory creates a new plugin instance
+type Factory func() (any, error)
+
+// IPluginPool is implemented by the framework to acquire & release the pooled plugin instances.
+// Owner is the unit name. Instances of the owner are closed when the unit is stopped.
+type IPluginPool interface {
+	Acquire_Plugin(pOwner string, pCategory string, pType string) (any, error)
+	Release_Plugin(pInstance any) error
+}
+
+// iCloser is implemented by the plugin instances that hold resources
+type iCloser interface {
+	Close() error
+}
+
+// Pool holds the instances of a plugin
+type Pool struct {
+	lock          *sync.Mutex
+	category      string
+	ptype         string
+	max_instances int
+	max_idle      int
+	factory       Factory
+	idle          []any
+	in_use        map[any]string /// owner by instance
+	pending       int            /// instances being created
+	created       uint64
+	reused        uint64
+	closed        uint64
+}
+
+// Pools holds the plugin pools by category & type
+type Pools struct {
+	lock  *sync.RWMutex
+	pools map[string]*Pool
+}
+
+// NewPools creates an empty set of plugin pools
+func NewPools() *Pools {
+	return &Pools{lock: &sync.RWMutex{}, pools: make(map[string]*Pool)}
+}
+
+// Pool returns the pool of the given category & type. Pool is created with the given settings if not exists.
+// Settings of an existing pool are updated, so that core.config changes apply to the next acquire.
+func (ps *Pools) Pool(pCategory string, pType string, pMax_Instances int, pMax_Idle int, pFactory Factory) *Pool {
+
+	if pMax_Idle <= 0 {
+		pMax_Idle = DEFAULT_MAX_IDLE
+	}
+
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	_key := pCategory + "/" + pType
+	_pool, _found := ps.pools[_key]
+	if !_found {
+		_pool = &Pool{lock: &sync.Mutex{}, category: pCategory, ptype: pType, in_use: make(map[any]string)}
+		ps.pools[_key] = _pool
+	}
+
+	_pool.lock.Lock()
+	_pool.max_instances, _pool.max_idle, _pool.factory = pMax_Instances, pMax_Idle, pFactory
+	_pool.lock.Unlock()
+
+	return _pool
+}
+
+// Release returns the instance to its pool
+func (ps *Pools) Release(pInstance any) error {
+
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	for _, _pool := range ps.pools {
+		if _pool.Release(pInstance) {
+			return nil
+		}
+	}
+	return errors.New("plugin instance is not acquired from the plugin pool")
+}
+
+// Close_Owner closes all the instances acquired by the owner. Returns the number of closed instances
+func (ps *Pools) Close_Owner(pOwner string) int {
+
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	_closed := 0
+	for _, _pool := range ps.pools {
+		_closed += _pool.Close_Owner(pOwner)
+	}
+	return _closed
+}
+
+// Close_All closes all the instances of all the pools
+func (ps *Pools) Close_All() {
+
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	for _, _pool := range ps.pools {
+		_pool.Close()
+	}
+}
+
+// Stats returns the instance counts of all the pools sorted by category & type
+func (ps *Pools) Stats() []fmtypes.PluginPoolStats {
+
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	_stats := make([]fmtypes.PluginPoolStats, 0, len(ps.pools))
+	for _, _pool := range ps.pools {
+		_stats = append(_stats, _pool.Stats())
+	}
+
+	sort.Slice(_stats, func(i, j int) bool {
+		if _stats[i].Category != _stats[j].Category {
+			return _stats[i].Category < _stats[j].Category
+		}
+		return _stats[i].Type < _stats[j].Type
+	})
+	return _stats
+}
+
+// Acquire returns an idle instance or creates a new one for the owner.
+// Returns error if max instances are in use
+func (p *Pool) Acquire(pOwner string) (any, error) {
+
+	p.lock.Lock()
+
+	if _count := len(p.idle); _count > 0 {
+		_instance := p.idle[_count-1]
+		p.idle = p.idle[:_count-1]
+		p.in_use[_instance] = pOwner
+		p.reused++
+		p.lock.Unlock()
+		return _instance, nil
+	}
+
+	if p.max_instances > 0 && len(p.in_use)+p.pending >= p.max_instances {
+		p.lock.Unlock()
+		return nil, errors.New("plugin " + p.category + "(" + p.ptype + ") reached the max instances " + strconv.Itoa(p.max_instances))
+	}
+
+	_factory := p.factory
+	p.pending++
+	p.lock.Unlock()
+
+	/// plugin is loaded without holding the pool
+	_instance, _err := _factory()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.pending--
+
+	if _err != nil {
+		return nil, _err
+	}
+
+	if _instance == nil || !reflect.TypeOf(_instance).Comparable() {
+		return nil, errors.New("plugin " + p.category + "(" + p.ptype + ") instance can not be pooled")
+	}
+
+	p.in_use[_instance] = pOwner
+	p.created++
+	return _instance, nil
+}
+
+// Release returns the instance to the idle list. Instance is closed if the idle list is full.
+// Returns false if the instance is not acquired from this pool
+func (p *Pool) Release(pInstance any) bool {
+
+	if pInstance == nil || !reflect.TypeOf(pInstance).Comparable() {
+		return false
+	}
+
+	p.lock.Lock()
+
+	if _, _found := p.in_use[pInstance]; !_found {
+		p.lock.Unlock()
+		return false
+	}
+	delete(p.in_use, pInstance)
+
+	if len(p.idle) < p.max_idle {
+		p.idle = append(p.idle, pInstance)
+		p.lock.Unlock()
+		return true
+	}
+
+	p.closed++
+	p.lock.Unlock()
+
+	close_instance(pInstance)
+	return true
+}
+
+// Close_Owner closes the in use instances of the owner. Returns the number of closed instances
+func (p *Pool) Close_Owner(pOwner string) int {
+
+	p.lock.Lock()
+	_instances := make([]any, 0)
+	for _instance, _owner := range p.in_use {
+		if _owner == pOwner {
+			_instances = append(_instances, _instance)
+			delete(p.in_use, _instance)
+		}
+	}
+	p.closed += uint64(len(_instances))
+	p.lock.Unlock()
+
+	for _, _instance := range _instances {
+		close_instance(_instance)
+	}
+	return len(_instances)
+}
+
+// Close closes all the in use & idle instances of the pool
+func (p *Pool) Close() {
+
+	p.lock.Lock()
+	_instances := p.idle
+	for _instance := range p.in_use {
+		_instances = append(_instances, _instance)
+	}
+	p.idle = nil
+	clear(p.in_use)
+	p.closed += uint64(len(_instances))
+	p.lock.Unlock()
+
+	for _, _instance := range _instances {
+		close_instance(_instance)
+	}
+}
+
+// Stats returns the instance counts of the pool
+func (p *Pool) Stats() fmtypes.PluginPoolStats {
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return fmtypes.PluginPoolStats{
+		Category:      p.category,
+		Type:          p.ptype,
+		Live:          len(p.in_use) + len(p.idle),
+		In_Use:        len(p.in_use),
+		Idle:          len(p.idle),
+		Max_Instances: p.max_instances,
+		Created:       p.created,
+		Reused:        p.reused,
+		Closed:        p.closed,
+	}
+}
+
+// close_instance closes the instance, if it implements Close() error
+func close_instance(pInstance any) {
+
+	defer func() {
+		recover()
+	}()
+
+	if _closer, _ok := pInstance.(iCloser); _ok {
+		_closer.Close()
+	}
+}
+
+// Acquire returns a pooled plugin instance of the given category & type as T for the owner.
+// pProvider is the framework instance given to the units & plugins
+func Acquire[T any](pProvider any, pOwner string, pCategory string, pType string) (T, error) {
+
+	var _none T
+
+	_pool, _ok := pProvider.(IPluginPool)
+	if !_ok {
+		return _none, errors.New("plugin pool is not supported by the framework instance")
+	}
+
+	_instance, _err := _pool.Acquire_Plugin(pOwner, pCategory, pType)
+	if _err != nil {
+		return _none, _err
+	}
+
+	_typed, _ok := _instance.(T)
+	if !_ok {
+		_pool.Release_Plugin(_instance)
+		return _none, errors.New("plugin " + pCategory + "(" + pType + ") does not implement " + reflect.TypeOf((*T)(nil)).Elem().String())
+	}
+	return _typed, nil
+}
+
+// Release returns the plugin instance to the pool of the framework instance
+func Release(pProvider any, pInstance any) error {
+
+	_pool, _ok := pProvider.(IPluginPool)
+	if !_ok {
+		return errors.New("plugin pool is not supported by the framework instance")
+	}
+	return _pool.Release_Plugin(pInstance)
+}
@@ -0,0 +1,312 @@
+package pluginreg
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// instance is a plugin instance of the pool tests
+type instance struct {
+	id     int
+	closed atomic.Int32
+	panics bool
+}
+
+func (i *instance) Close() error {
+	i.closed.Add(1)
+	if i.panics {
+		panic("close failed")
+	}
+	return nil
+}
+
+// counting_factory returns a factory creating numbered instances
+func counting_factory() Factory {
+
+	_count := 0
+	_lock := &sync.Mutex{}
+	return func() (any, error) {
+		_lock.Lock()
+		defer _lock.Unlock()
+		_count++
+		return &instance{id: _count}, nil
+	}
+}
+
+func TestPool_Acquire_Limit(t *testing.T) {
+
+	_cases := []struct {
+		name     string
+		max      int
+		acquires int
+		fails    string
+	}{
+		{name: "unlimited", max: 0, acquires: 5},
+		{name: "within the limit", max: 2, acquires: 2},
+		{name: "over the limit", max: 2, acquires: 3, fails: "plugin http(default) reached the max instances 2"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_pool := NewPools().Pool("http", "default", _case.max, 1, counting_factory())
+
+			var _err error
+			for _index := 0; _index < _case.acquires && _err == nil; _index++ {
+				_, _err = _pool.Acquire("orders")
+			}
+
+			if len(_case.fails) == 0 {
+				if _err != nil {
+					t.Fatal(_err)
+				}
+				return
+			}
+			if _err == nil || _err.Error() != _case.fails {
+				t.Fatalf("expected %q, got %v", _case.fails, _err)
+			}
+		})
+	}
+}
+
+func TestPool_Acquire_Pending(t *testing.T) {
+
+	_loading := make(chan struct{})
+	_release := make(chan struct{})
+	_factory := counting_factory()
+
+	_pool := NewPools().Pool("http", "default", 1, 1, func() (any, error) {
+		close(_loading)
+		<-_release
+		return _factory()
+	})
+
+	_done := make(chan error)
+	go func() {
+		_, _err := _pool.Acquire("orders")
+		_done <- _err
+	}()
+	<-_loading
+
+	/// instance being created is counted in the max instances
+	if _, _err := _pool.Acquire("billing"); _err == nil {
+		t.Fatal("instance is created over the max instances")
+	}
+
+	close(_release)
+	if _err := <-_done; _err != nil {
+		t.Fatal(_err)
+	}
+	if _stats := _pool.Stats(); _stats.In_Use != 1 || _stats.Created != 1 {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+}
+
+func TestPool_Acquire_Errors(t *testing.T) {
+
+	_cases := []struct {
+		name    string
+		factory Factory
+		fails   string
+	}{
+		{name: "factory error", factory: func() (any, error) { return nil, errors.New("plugin.Open failed") },
+			fails: "plugin.Open failed"},
+		{name: "nil instance", factory: func() (any, error) { return nil, nil },
+			fails: "plugin http(default) instance can not be pooled"},
+		{name: "not comparable", factory: func() (any, error) { return map[string]any{}, nil },
+			fails: "plugin http(default) instance can not be pooled"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_pool := NewPools().Pool("http", "default", 1, 1, _case.factory)
+
+			/// failed acquire does not hold the max instances
+			for _try := 0; _try < 2; _try++ {
+				if _, _err := _pool.Acquire("orders"); _err == nil || _err.Error() != _case.fails {
+					t.Fatalf("expected %q, got %v", _case.fails, _err)
+				}
+			}
+			if _stats := _pool.Stats(); _stats.Live != 0 || _stats.Created != 0 {
+				t.Fatalf("unexpected stats %+v", _stats)
+			}
+		})
+	}
+}
+
+func TestPool_Idle_Reuse(t *testing.T) {
+
+	_pool := NewPools().Pool("http", "default", 1, 1, counting_factory())
+
+	_first, _ := _pool.Acquire("orders")
+	if !_pool.Release(_first) {
+		t.Fatal("acquired instance is not released")
+	}
+
+	/// idle instance is reused by any owner & does not count over the max instances
+	_second, _err := _pool.Acquire("billing")
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	if _second != _first {
+		t.Fatal("idle instance is not reused")
+	}
+
+	_stats := _pool.Stats()
+	if _stats.Created != 1 || _stats.Reused != 1 || _stats.In_Use != 1 || _stats.Idle != 0 || _stats.Closed != 0 {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+	if _first.(*instance).closed.Load() != 0 {
+		t.Fatal("released instance is closed")
+	}
+}
+
+func TestPool_Release_Idle_Full(t *testing.T) {
+
+	_pool := NewPools().Pool("http", "default", 0, 1, counting_factory())
+
+	_first, _ := _pool.Acquire("orders")
+	_second, _ := _pool.Acquire("orders")
+
+	_pool.Release(_first)
+	_pool.Release(_second)
+
+	if _first.(*instance).closed.Load() != 0 || _second.(*instance).closed.Load() != 1 {
+		t.Fatal("instance released over the max idle is not closed")
+	}
+
+	_stats := _pool.Stats()
+	if _stats.Live != 1 || _stats.Idle != 1 || _stats.In_Use != 0 || _stats.Closed != 1 {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+
+	/// released instances are not released again
+	if _pool.Release(_second) || _pool.Release(&instance{}) || _pool.Release(nil) {
+		t.Fatal("instance not in use is released")
+	}
+}
+
+func TestPools_Close_Owner(t *testing.T) {
+
+	_pools := NewPools()
+	_http := _pools.Pool("http", "default", 0, 2, counting_factory())
+	_mq := _pools.Pool("mq", "default", 0, 2, counting_factory())
+
+	_orders_http, _ := _http.Acquire("orders")
+	_orders_mq, _ := _mq.Acquire("orders")
+	_billing, _ := _http.Acquire("billing")
+	_idle, _ := _http.Acquire("orders")
+	_pools.Release(_idle)
+
+	if _closed := _pools.Close_Owner("orders"); _closed != 2 {
+		t.Fatalf("expected 2 closed instances, got %d", _closed)
+	}
+
+	for _, _case := range []struct {
+		name     string
+		instance any
+		closed   int32
+	}{
+		{name: "http of orders", instance: _orders_http, closed: 1},
+		{name: "mq of orders", instance: _orders_mq, closed: 1},
+		{name: "http of billing", instance: _billing, closed: 0},
+		{name: "idle", instance: _idle, closed: 0},
+	} {
+		if _closed := _case.instance.(*instance).closed.Load(); _closed != _case.closed {
+			t.Fatalf("%s is closed %d times", _case.name, _closed)
+		}
+	}
+
+	/// closed instances are not in use
+	if _err := _pools.Release(_orders_http); _err == nil {
+		t.Fatal("closed instance is released")
+	}
+	if _closed := _pools.Close_Owner("orders"); _closed != 0 {
+		t.Fatalf("instances are closed again %d", _closed)
+	}
+	if _err := _pools.Release(_billing); _err != nil {
+		t.Fatal(_err)
+	}
+}
+
+func TestPools_Close_All(t *testing.T) {
+
+	_pools := NewPools()
+	_pool := _pools.Pool("http", "default", 0, 2, counting_factory())
+
+	_in_use, _ := _pool.Acquire(FRAMEWORK_OWNER)
+	_idle, _ := _pool.Acquire("orders")
+	_pool.Release(_idle)
+
+	/// panic of a plugin Close does not stop the close of the others
+	_panics := &instance{panics: true}
+	_pools.Pool("mq", "default", 0, 2, func() (any, error) { return _panics, nil }).Acquire("orders")
+
+	_pools.Close_All()
+
+	for _, _instance := range []*instance{_in_use.(*instance), _idle.(*instance), _panics} {
+		if _instance.closed.Load() != 1 {
+			t.Fatalf("instance %d is not closed", _instance.id)
+		}
+	}
+	if _stats := _pool.Stats(); _stats.Live != 0 || _stats.Closed != 2 {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+}
+
+func TestPools_Stats(t *testing.T) {
+
+	_pools := NewPools()
+	_pools.Pool("mq", "default", 0, 0, counting_factory())
+	_pools.Pool("http", "secure", 0, 0, counting_factory())
+	_http := _pools.Pool("http", "default", 1, 0, counting_factory())
+
+	/// settings of an existing pool are updated
+	if _again := _pools.Pool("http", "default", 3, 0, counting_factory()); _again != _http {
+		t.Fatal("existing pool is not returned")
+	}
+
+	_stats := _pools.Stats()
+	_names := make([]string, 0, len(_stats))
+	for _, _stat := range _stats {
+		_names = append(_names, _stat.Category+"/"+_stat.Type)
+	}
+	if len(_names) != 3 || _names[0] != "http/default" || _names[1] != "http/secure" || _names[2] != "mq/default" {
+		t.Fatalf("unexpected order %v", _names)
+	}
+	if _stats[0].Max_Instances != 3 || _http.max_idle != DEFAULT_MAX_IDLE {
+		t.Fatalf("unexpected settings %+v, max idle %d", _stats[0], _http.max_idle)
+	}
+}
+
+func TestPool_Concurrent(t *testing.T) {
+
+	_pool := NewPools().Pool("http", "default", 4, 2, counting_factory())
+
+	_wait := &sync.WaitGroup{}
+	for _index := 0; _index < 16; _index++ {
+		_wait.Add(1)
+		go func() {
+			defer _wait.Done()
+			for _try := 0; _try < 50; _try++ {
+				_instance, _err := _pool.Acquire("orders")
+				if _err != nil {
+					/// max instances are in use
+					time.Sleep(time.Microsecond)
+					continue
+				}
+				_pool.Release(_instance)
+			}
+		}()
+	}
+	_wait.Wait()
+
+	_stats := _pool.Stats()
+	if _stats.In_Use != 0 || _stats.Idle > 2 || int(_stats.Created) > 4+int(_stats.Closed) {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+}
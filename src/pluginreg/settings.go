@@ -0,0 +1,391 @@
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   pluginreg - plugin settings
+
+	Objective     :   Deliver the "settings" of the plugin entries to the plugin instances,
+						validated against the JSON schema exposed by the plugin
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package pluginreg
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IConfigurable is implemented by the plugin instances that accept settings.
+// Configure is called once after New(), before the instance is given to the caller.
+type IConfigurable interface {
+	Configure(pSettings map[string]any) error
+}
+
+// ISettingsSchema is implemented by the plugin instances that validate their settings.
+// Schema is a JSON schema subset of an object:
+// type, properties, required, additionalProperties, default, enum, minimum, maximum,
+// minLength, maxLength, pattern, items, minItems & maxItems.
+type ISettingsSchema interface {
+	Settings_Schema() map[string]any
+}
+
+// Merge_Settings returns a deep copy of the base settings with the override settings applied.
+// Objects are merged by key, other values of the override replace the base values
+func Merge_Settings(pBase map[string]any, pOverride map[string]any) map[string]any {
+
+	_merged := make(map[string]any, len(pBase)+len(pOverride))
+	for _key, _value := range pBase {
+		_merged[_key] = copy_value(_value)
+	}
+
+	for _key, _value := range pOverride {
+		_base, _base_ok := _merged[_key].(map[string]any)
+		_over, _over_ok := _value.(map[string]any)
+		if _base_ok && _over_ok {
+			_merged[_key] = Merge_Settings(_base, _over)
+		} else {
+			_merged[_key] = copy_value(_value)
+		}
+	}
+	return _merged
+}
+
+// copy_value returns a deep copy of the objects & arrays of the value
+func copy_value(pValue any) any {
+
+	switch _value := pValue.(type) {
+	case map[string]any:
+		return Merge_Settings(_value, nil)
+	case []any:
+		_copy := make([]any, len(_value))
+		for _index := range _value {
+			_copy[_index] = copy_value(_value[_index])
+		}
+		return _copy
+	}
+	return pValue
+}
+
+// Configure validates the settings against the schema of the instance, if the instance implements ISettingsSchema.
+// Defaults of the schema are added to the settings. Settings are passed to Configure, if the instance implements IConfigurable.
+//
+// Instance is closed if the settings are rejected.
+//
+// Returns true,nil if the instance is configured. false,nil if the instance does not accept settings. Unless false,error
+func Configure(pInstance any, pSettings map[string]any) (bool, error) {
+
+	if pSettings == nil {
+		pSettings = make(map[string]any)
+	}
+
+	if _schema, _ok := pInstance.(ISettingsSchema); _ok {
+		if _err := Validate_Settings(_schema.Settings_Schema(), pSettings); _err != nil {
+			close_instance(pInstance)
+			return false, _err
+		}
+	}
+
+	_configurable, _ok := pInstance.(IConfigurable)
+	if !_ok {
+		return false, nil
+	}
+
+	if _err := _configurable.Configure(pSettings); _err != nil {
+		close_instance(pInstance)
+		return false, errors.New("configure failed. " + _err.Error())
+	}
+	return true, nil
+}
+
+// Validate_Settings validates the settings against the schema and adds the defaults of the missing properties.
+// Returns nil if valid. Unless the error with the path of the invalid setting
+func Validate_Settings(pSchema map[string]any, pSettings map[string]any) error {
+
+	if pSchema == nil {
+		return nil
+	}
+
+	/// schema of the plugin is normalized to the JSON types. eg: []string enum to []any
+	_data, _err := json.Marshal(pSchema)
+	if _err != nil {
+		return errors.New("invalid settings schema. " + _err.Error())
+	}
+	_schema := make(map[string]any)
+	if _err = json.Unmarshal(_data, &_schema); _err != nil {
+		return errors.New("invalid settings schema. " + _err.Error())
+	}
+
+	return validate_value("settings", _schema, pSettings)
+}
+
+// validate_value validates the value against the schema
+func validate_value(pPath string, pSchema map[string]any, pValue any) error {
+
+	_type := json_type(pValue)
+
+	if _expected, _found := pSchema["type"]; _found && !type_matches(_expected, _type) {
+		return errors.New(pPath + " should be " + type_name(_expected) + ", found " + _type)
+	}
+
+	if _enum, _found := pSchema["enum"].([]any); _found {
+		_matched := false
+		for _, _option := range _enum {
+			if values_equal(_option, pValue) {
+				_matched = true
+				break
+			}
+		}
+		if !_matched {
+			return errors.New(pPath + " should be one of " + to_json(_enum))
+		}
+	}
+
+	switch _value := pValue.(type) {
+	case map[string]any:
+		return validate_object(pPath, pSchema, _value)
+
+	case []any:
+		if _min, _found := to_number(pSchema["minItems"]); _found && float64(len(_value)) < _min {
+			return errors.New(pPath + " should have at least " + format_number(_min) + " items")
+		}
+		if _max, _found := to_number(pSchema["maxItems"]); _found && float64(len(_value)) > _max {
+			return errors.New(pPath + " should have at most " + format_number(_max) + " items")
+		}
+		if _items, _found := pSchema["items"].(map[string]any); _found {
+			for _index := range _value {
+				if _err := validate_value(pPath+"["+strconv.Itoa(_index)+"]", _items, _value[_index]); _err != nil {
+					return _err
+				}
+			}
+		}
+
+	case string:
+		_length := float64(len([]rune(_value)))
+		if _min, _found := to_number(pSchema["minLength"]); _found && _length < _min {
+			return errors.New(pPath + " should be at least " + format_number(_min) + " characters")
+		}
+		if _max, _found := to_number(pSchema["maxLength"]); _found && _length > _max {
+			return errors.New(pPath + " should be at most " + format_number(_max) + " characters")
+		}
+		if _pattern, _found := pSchema["pattern"].(string); _found {
+			_regex, _err := regexp.Compile(_pattern)
+			if _err != nil {
+				return errors.New(pPath + " has an invalid pattern in the schema. " + _err.Error())
+			}
+			if !_regex.MatchString(_value) {
+				return errors.New(pPath + " should match " + _pattern)
+			}
+		}
+
+	default:
+		if _number, _ok := to_number(pValue); _ok {
+			if _min, _found := to_number(pSchema["minimum"]); _found && _number < _min {
+				return errors.New(pPath + " should be >= " + format_number(_min))
+			}
+			if _max, _found := to_number(pSchema["maximum"]); _found && _number > _max {
+				return errors.New(pPath + " should be <= " + format_number(_max))
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate_object validates the properties of the object and adds the defaults of the missing properties
+func validate_object(pPath string, pSchema map[string]any, pObject map[string]any) error {
+
+	_properties, _ := pSchema["properties"].(map[string]any)
+
+	for _, _name := range to_strings(pSchema["required"]) {
+		if _, _found := pObject[_name]; !_found {
+			if _property, _ok := _properties[_name].(map[string]any); !_ok || _property["default"] == nil {
+				return errors.New(pPath + "." + _name + " is required")
+			}
+		}
+	}
+
+	_names := make([]string, 0, len(_properties))
+	for _name := range _properties {
+		_names = append(_names, _name)
+	}
+	sort.Strings(_names)
+
+	for _, _name := range _names {
+		_property, _ok := _properties[_name].(map[string]any)
+		if !_ok {
+			continue
+		}
+
+		_value, _found := pObject[_name]
+		if !_found {
+			if _default, _has_default := _property["default"]; _has_default {
+				pObject[_name] = copy_value(_default)
+			}
+			continue
+		}
+
+		if _err := validate_value(pPath+"."+_name, _property, _value); _err != nil {
+			return _err
+		}
+	}
+
+	if _additional, _found := pSchema["additionalProperties"].(bool); _found && !_additional {
+		_unknown := make([]string, 0)
+		for _name := range pObject {
+			if _, _found := _properties[_name]; !_found {
+				_unknown = append(_unknown, _name)
+			}
+		}
+		if len(_unknown) > 0 {
+			sort.Strings(_unknown)
+			return errors.New(pPath + " has unknown properties " + strings.Join(_unknown, ", "))
+		}
+	}
+
+	return nil
+}
+
+// json_type returns the JSON type name of the value
+func json_type(pValue any) string {
+
+	switch pValue.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+
+	if _number, _ok := to_number(pValue); _ok {
+		if _number == math.Trunc(_number) {
+			return "integer"
+		}
+		return "number"
+	}
+	return reflect.TypeOf(pValue).String()
+}
+
+// type_matches checks the value type against the schema type. Schema type can be a name or a list of names
+func type_matches(pExpected any, pType string) bool {
+
+	for _, _name := range to_strings(pExpected) {
+		if _name == pType || (_name == "number" && pType == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// type_name returns the schema type as text
+func type_name(pExpected any) string {
+	return strings.Join(to_strings(pExpected), " or ")
+}
+
+// values_equal compares the values. Numbers are compared by value regardless of the Go type
+func values_equal(pLeft any, pRight any) bool {
+
+	_left, _left_ok := to_number(pLeft)
+	_right, _right_ok := to_number(pRight)
+	if _left_ok && _right_ok {
+		return _left == _right
+	}
+	return reflect.DeepEqual(pLeft, pRight)
+}
+
+// to_number converts the Go & JSON numbers to float64
+func to_number(pValue any) (float64, bool) {
+
+	switch _value := pValue.(type) {
+	case float64:
+		return _value, true
+	case float32:
+		return float64(_value), true
+	case int:
+		return float64(_value), true
+	case int8:
+		return float64(_value), true
+	case int16:
+		return float64(_value), true
+	case int32:
+		return float64(_value), true
+	case int64:
+		return float64(_value), true
+	case uint:
+		return float64(_value), true
+	case uint8:
+		return float64(_value), true
+	case uint16:
+		return float64(_value), true
+	case uint32:
+		return float64(_value), true
+	case uint64:
+		return float64(_value), true
+	case json.Number:
+		_number, _err := _value.Float64()
+		return _number, _err == nil
+	}
+	return 0, false
+}
+
+// to_strings converts a string or a list of strings of the schema to []string
+func to_strings(pValue any) []string {
+
+	switch _value := pValue.(type) {
+	case string:
+		return []string{_value}
+	case []string:
+		return _value
+	case []any:
+		_strings := make([]string, 0, len(_value))
+		for _, _item := range _value {
+			if _string, _ok := _item.(string); _ok {
+				_strings = append(_strings, _string)
+			}
+		}
+		return _strings
+	}
+	return nil
+}
+
+// format_number formats the schema limit without the trailing zeros
+func format_number(pNumber float64) string {
+	return strconv.FormatFloat(pNumber, 'f', -1, 64)
+}
+
+// to_json returns the value as JSON text
+func to_json(pValue any) string {
+	_data, _ := json.Marshal(pValue)
+	return string(_data)
+}
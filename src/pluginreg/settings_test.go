@@ -0,0 +1,210 @@
+package pluginreg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// settings_schema is the schema of the validator tests
+var settings_schema = map[string]any{
+	"type":                 "object",
+	"required":             []string{"url"},
+	"additionalProperties": false,
+	"properties": map[string]any{
+		"url":     map[string]any{"type": "string", "pattern": "^https?://", "minLength": 10, "maxLength": 40},
+		"mode":    map[string]any{"type": "string", "enum": []string{"fast", "safe"}, "default": "safe"},
+		"retries": map[string]any{"type": "integer", "minimum": 0, "maximum": 5},
+		"ratio":   map[string]any{"type": "number", "minimum": 0.5},
+		"debug":   map[string]any{"type": []string{"boolean", "null"}},
+		"tags":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "minItems": 1, "maxItems": 2},
+		"tls": map[string]any{
+			"type":     "object",
+			"required": []string{"cert"},
+			"properties": map[string]any{
+				"cert":    map[string]any{"type": "string"},
+				"verify":  map[string]any{"type": "boolean", "default": true},
+				"version": map[string]any{"enum": []any{1.2, 1.3}},
+			},
+		},
+	},
+}
+
+func TestValidate_Settings(t *testing.T) {
+
+	_cases := []struct {
+		name     string
+		settings map[string]any
+		fails    string
+	}{
+		{name: "minimal", settings: map[string]any{"url": "http://localhost"}},
+		{name: "all set", settings: map[string]any{"url": "https://localhost", "mode": "fast", "retries": 3, "ratio": 0.75,
+			"debug": nil, "tags": []any{"a"}, "tls": map[string]any{"cert": "c.pem", "verify": false, "version": 1.3}}},
+		{name: "JSON numbers", settings: map[string]any{"url": "http://localhost", "retries": float64(5), "ratio": float64(1)}},
+
+		{name: "required", settings: map[string]any{}, fails: "settings.url is required"},
+		{name: "type", settings: map[string]any{"url": 10}, fails: "settings.url should be string, found integer"},
+		{name: "integer type", settings: map[string]any{"url": "http://localhost", "retries": 1.5},
+			fails: "settings.retries should be integer, found number"},
+		{name: "type list", settings: map[string]any{"url": "http://localhost", "debug": "yes"},
+			fails: "settings.debug should be boolean or null, found string"},
+		{name: "enum", settings: map[string]any{"url": "http://localhost", "mode": "slow"},
+			fails: `settings.mode should be one of ["fast","safe"]`},
+		{name: "minimum", settings: map[string]any{"url": "http://localhost", "retries": -1}, fails: "settings.retries should be >= 0"},
+		{name: "maximum", settings: map[string]any{"url": "http://localhost", "retries": 6}, fails: "settings.retries should be <= 5"},
+		{name: "fractional minimum", settings: map[string]any{"url": "http://localhost", "ratio": 0.25},
+			fails: "settings.ratio should be >= 0.5"},
+		{name: "pattern", settings: map[string]any{"url": "ftp://localhost"}, fails: "settings.url should match ^https?://"},
+		{name: "minLength", settings: map[string]any{"url": "http://a"}, fails: "settings.url should be at least 10 characters"},
+		{name: "maxLength", settings: map[string]any{"url": "http://" + strings.Repeat("a", 40)},
+			fails: "settings.url should be at most 40 characters"},
+		{name: "minItems", settings: map[string]any{"url": "http://localhost", "tags": []any{}},
+			fails: "settings.tags should have at least 1 items"},
+		{name: "maxItems", settings: map[string]any{"url": "http://localhost", "tags": []any{"a", "b", "c"}},
+			fails: "settings.tags should have at most 2 items"},
+		{name: "items", settings: map[string]any{"url": "http://localhost", "tags": []any{"a", 1}},
+			fails: "settings.tags[1] should be string, found integer"},
+		{name: "additional properties", settings: map[string]any{"url": "http://localhost", "proxy": "p", "agent": "a"},
+			fails: "settings has unknown properties agent, proxy"},
+		{name: "nested required", settings: map[string]any{"url": "http://localhost", "tls": map[string]any{}},
+			fails: "settings.tls.cert is required"},
+		{name: "nested type", settings: map[string]any{"url": "http://localhost", "tls": map[string]any{"cert": "c", "verify": "no"}},
+			fails: "settings.tls.verify should be boolean, found string"},
+		{name: "nested enum", settings: map[string]any{"url": "http://localhost", "tls": map[string]any{"cert": "c", "version": 1.1}},
+			fails: "settings.tls.version should be one of [1.2,1.3]"},
+		{name: "nested object type", settings: map[string]any{"url": "http://localhost", "tls": "c.pem"},
+			fails: "settings.tls should be object, found string"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_err := Validate_Settings(settings_schema, _case.settings)
+
+			if len(_case.fails) == 0 {
+				if _err != nil {
+					t.Fatal(_err)
+				}
+				return
+			}
+			if _err == nil || _err.Error() != _case.fails {
+				t.Fatalf("expected %q, got %v", _case.fails, _err)
+			}
+		})
+	}
+}
+
+func TestValidate_Settings_Defaults(t *testing.T) {
+
+	_tls := map[string]any{"cert": "c.pem"}
+	_settings := map[string]any{"url": "http://localhost", "tls": _tls}
+
+	if _err := Validate_Settings(settings_schema, _settings); _err != nil {
+		t.Fatal(_err)
+	}
+	if _settings["mode"] != "safe" {
+		t.Fatalf("default of mode is not added: %v", _settings["mode"])
+	}
+	if _tls["verify"] != true {
+		t.Fatalf("default of the nested verify is not added: %v", _tls["verify"])
+	}
+	if _, _found := _settings["retries"]; _found {
+		t.Fatal("property without default is added")
+	}
+
+	/// required property with a default is not missing
+	_schema := map[string]any{"type": "object", "required": []string{"mode"},
+		"properties": map[string]any{"mode": map[string]any{"type": "string", "default": "safe"}}}
+	if _err := Validate_Settings(_schema, map[string]any{}); _err != nil {
+		t.Fatal(_err)
+	}
+}
+
+func TestValidate_Settings_Schema_Errors(t *testing.T) {
+
+	_cases := []struct {
+		name   string
+		schema map[string]any
+		fails  string
+	}{
+		{name: "invalid pattern", schema: map[string]any{"properties": map[string]any{"url": map[string]any{"pattern": "("}}},
+			fails: "settings.url has an invalid pattern in the schema"},
+		{name: "not JSON", schema: map[string]any{"properties": map[string]any{"url": map[string]any{"default": func() {}}}},
+			fails: "invalid settings schema"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_err := Validate_Settings(_case.schema, map[string]any{"url": "http://localhost"})
+			if _err == nil || !strings.HasPrefix(_err.Error(), _case.fails) {
+				t.Fatalf("expected %q, got %v", _case.fails, _err)
+			}
+		})
+	}
+
+	if _err := Validate_Settings(nil, map[string]any{"any": 1}); _err != nil {
+		t.Fatalf("nil schema should accept the settings: %v", _err)
+	}
+}
+
+// settings_plugin is a plugin instance with a schema & Configure
+type settings_plugin struct {
+	settings  map[string]any
+	configure error
+	closed    bool
+}
+
+func (p *settings_plugin) Settings_Schema() map[string]any { return settings_schema }
+func (p *settings_plugin) Configure(pSettings map[string]any) error {
+	p.settings = pSettings
+	return p.configure
+}
+func (p *settings_plugin) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestConfigure(t *testing.T) {
+
+	_cases := []struct {
+		name       string
+		settings   map[string]any
+		configure  error
+		configured bool
+		fails      string
+	}{
+		{name: "configured", settings: map[string]any{"url": "http://localhost"}, configured: true},
+		{name: "rejected settings", settings: map[string]any{}, fails: "settings.url is required"},
+		{name: "configure failed", settings: map[string]any{"url": "http://localhost"}, configure: errors.New("no route"),
+			fails: "configure failed. no route"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_plugin := &settings_plugin{configure: _case.configure}
+			_configured, _err := Configure(_plugin, _case.settings)
+
+			if len(_case.fails) > 0 {
+				if _err == nil || _err.Error() != _case.fails {
+					t.Fatalf("expected %q, got %v", _case.fails, _err)
+				}
+				if !_plugin.closed {
+					t.Fatal("rejected instance is not closed")
+				}
+				return
+			}
+			if _err != nil || _configured != _case.configured {
+				t.Fatalf("unexpected result %v, %v", _configured, _err)
+			}
+			if _plugin.settings["mode"] != "safe" {
+				t.Fatal("defaults are not passed to Configure")
+			}
+		})
+	}
+
+	if _configured, _err := Configure(struct{}{}, nil); _configured || _err != nil {
+		t.Fatalf("instance without settings should not be configured: %v, %v", _configured, _err)
+	}
+}
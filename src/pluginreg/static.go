@@ -0,0 +1,91 @@
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   pluginreg - static registry
+
+	Objective     :   Registry of the units & plugins compiled into the framework binary,
+						resolved by static://<name> paths instead of plugin.Open
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package pluginreg
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// STATIC_PREFIX define the path prefix of the compiled-in units & plugins
+const STATIC_PREFIX = "static://"
+
+// UNIT_CATEGORY define the category of the units in the static registry
+const UNIT_CATEGORY = "unit"
+
+var statics = make(map[string]Factory)
+var statics_lock = &sync.RWMutex{}
+
+// Register_Static registers the factory of a compiled-in unit or plugin by category & name.
+// Registering the same category & name again replaces the factory.
+func Register_Static(pCategory string, pName string, pFactory Factory) {
+	statics_lock.Lock()
+	defer statics_lock.Unlock()
+
+	statics[strings.ToLower(pCategory)+"/"+strings.ToLower(pName)] = pFactory
+}
+
+// Static returns the factory of the compiled-in unit or plugin of the given category & name
+func Static(pCategory string, pName string) (Factory, bool) {
+	statics_lock.RLock()
+	defer statics_lock.RUnlock()
+
+	_factory, _found := statics[strings.ToLower(pCategory)+"/"+strings.ToLower(pName)]
+	return _factory, _found
+}
+
+// Statics returns the registered compiled-in units & plugins as <category>/<name>, sorted
+func Statics() []string {
+	statics_lock.RLock()
+	defer statics_lock.RUnlock()
+
+	_names := make([]string, 0, len(statics))
+	for _name := range statics {
+		_names = append(_names, _name)
+	}
+	sort.Strings(_names)
+	return _names
+}
+
+// Is_Static checks whether the path is a static://<name> path
+func Is_Static(pPath string) bool {
+	return strings.HasPrefix(strings.ToLower(pPath), STATIC_PREFIX)
+}
+
+// Static_Name returns the name of the static://<name> path. Other paths are returned as they are
+func Static_Name(pPath string) string {
+	if !Is_Static(pPath) {
+		return pPath
+	}
+	return strings.Trim(pPath[len(STATIC_PREFIX):], "/")
+}
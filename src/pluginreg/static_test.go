@@ -0,0 +1,52 @@
+package pluginreg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRegister_Static(t *testing.T) {
+
+	Register_Static("Unit", "Static_Test", func() (any, error) { return "first", nil })
+	Register_Static("unit", "static_test", func() (any, error) { return "second", nil })
+
+	_factory, _found := Static("UNIT", "STATIC_TEST")
+	if !_found {
+		t.Fatal("registered factory is not found regardless of the case")
+	}
+	if _instance, _ := _factory(); _instance != "second" {
+		t.Fatalf("expected the factory registered last, got %v", _instance)
+	}
+
+	if _, _found = Static("http", "static_test"); _found {
+		t.Fatal("factory is found in another category")
+	}
+
+	if _statics := Statics(); !slices.Contains(_statics, "unit/static_test") || !slices.IsSorted(_statics) {
+		t.Fatalf("unexpected statics %v", _statics)
+	}
+}
+
+func TestStatic_Name(t *testing.T) {
+
+	_cases := []struct {
+		path   string
+		static bool
+		want   string
+	}{
+		{"static://demo_http", true, "demo_http"},
+		{"STATIC://demo_http/", true, "demo_http"},
+		{"static://", true, ""},
+		{"plugins/http/default.so", false, "plugins/http/default.so"},
+		{"units/static/demo.so", false, "units/static/demo.so"},
+	}
+
+	for _, _case := range _cases {
+		if _static := Is_Static(_case.path); _static != _case.static {
+			t.Errorf("Is_Static(%q) = %v, want %v", _case.path, _static, _case.static)
+		}
+		if _got := Static_Name(_case.path); _got != _case.want {
+			t.Errorf("Static_Name(%q) = %q, want %q", _case.path, _got, _case.want)
+		}
+	}
+}
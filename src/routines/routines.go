@@ -0,0 +1,368 @@
+// routines package provides the managed go routines of the units of the framework instance
+//
+// A managed routine has a name & an owner unit instance. Its context is cancelled when the owner is stopped.
+// Routines still running LEAK_GRACE after their context is cancelled are reported as leaks.
+// Start time, exit & panics of the routines are kept for /admin/routines.
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   routines
+
+	Objective     :   Named go routines of the units, cancelled on unit stop, with panics, exits & leaks
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+	Ajith de Silva		19/10/2026	Updated 	Kept the leak grace in the registry
+
+	Ajith de Silva		19/10/2026	Updated 	Derived the routine contexts from the context of the owner instance
+
+#########################################################################################
+*/
+package routines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// LEAK_GRACE define the time a routine has to return after its context is cancelled, before it is reported as leak
+const LEAK_GRACE = 5 * time.Second
+
+// MAX_EXITED define the number of the last exited routines kept for the listing
+const MAX_EXITED = 100
+
+// FRAMEWORK_OWNER define the owner name of the routines started without an owner
+const FRAMEWORK_OWNER = "framework"
+
+// Func is the function of the routine. It should return when the context is done
+type Func func(pCTX context.Context)
+
+// IOwner is the unit instance starting the routine
+type IOwner interface {
+	IsStarted() bool
+}
+
+// IRoutines defines the managed routine functions of the framework instance.
+// Units check the framework instance for it:
+//
+//	if _routines, _ok := pApp.(routines.IRoutines); _ok { ... }
+type IRoutines interface {
+	Go(pOwner IOwner, pName string, pRun Func) error
+}
+
+// routine holds a running routine
+type routine struct {
+	info   fmtypes.RoutineInfo
+	owner  IOwner
+	cancel context.CancelFunc
+}
+
+// Registry holds the running routines and the last exited routines
+type Registry struct {
+	lock     *sync.Mutex
+	routines map[uint64]*routine
+	exited   []fmtypes.RoutineInfo
+	sequence uint64
+	started  uint64
+	panicked uint64
+	leaked   uint64
+	closed   bool
+	wait     *sync.WaitGroup
+	owners   map[IOwner]string                   /// unit name of the owners
+	on_event func(pInfo fmtypes.RoutineInfo)     /// called on panic & leak of a routine. may be nil
+	grace    time.Duration                       /// LEAK_GRACE. shorter in the tests
+	context  func(pOwner IOwner) context.Context /// context of the owner instance. may be nil
+}
+
+// New creates a new registry. pOn_Event is called on panics & leaks of the routines.
+// pContext returns the context of the owner instance, the routine contexts are derived from. nil for background contexts
+func New(pOn_Event func(pInfo fmtypes.RoutineInfo), pContext func(pOwner IOwner) context.Context) *Registry {
+
+	return &Registry{
+		lock:     &sync.Mutex{},
+		routines: make(map[uint64]*routine),
+		exited:   make([]fmtypes.RoutineInfo, 0),
+		wait:     &sync.WaitGroup{},
+		owners:   make(map[IOwner]string),
+		on_event: pOn_Event,
+		grace:    LEAK_GRACE,
+		context:  pContext,
+	}
+}
+
+// Set_Owner sets the unit name of the owner instance. Called by the framework before the instance is initialized
+func (r *Registry) Set_Owner(pOwner IOwner, pName string) {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.owners[pOwner] = pName
+}
+
+// Go starts the function as a routine of the owner. Owner is nil for the routines of the framework
+func (r *Registry) Go(pOwner IOwner, pName string, pRun Func) error {
+
+	if len(pName) == 0 {
+		return errors.New("routine name is not given")
+	}
+	if pRun == nil {
+		return errors.New("function of routine " + pName + " is not given")
+	}
+
+	r.lock.Lock()
+	if r.closed {
+		r.lock.Unlock()
+		return errors.New("routine " + pName + " is not started. framework is stopping")
+	}
+
+	r.sequence++
+	r.started++
+
+	_ctx, _cancel := context.WithCancel(r.owner_context(pOwner))
+	_routine := &routine{owner: pOwner, cancel: _cancel}
+	_routine.info = fmtypes.RoutineInfo{ID: r.sequence, Name: pName, Owner: r.resolve(pOwner), Started: time.Now()}
+	r.routines[_routine.info.ID] = _routine
+	r.wait.Add(1)
+	r.lock.Unlock()
+
+	go r.run(_ctx, _routine, pRun)
+	return nil
+}
+
+// owner_context returns the context of the owner instance. Background context for the routines of the framework
+func (r *Registry) owner_context(pOwner IOwner) context.Context {
+
+	if pOwner == nil || r.context == nil {
+		return context.Background()
+	}
+	if _ctx := r.context(pOwner); _ctx != nil {
+		return _ctx
+	}
+	return context.Background()
+}
+
+// run calls the function of the routine & records its exit
+func (r *Registry) run(pCTX context.Context, pRoutine *routine, pRun Func) {
+
+	_panic := ""
+
+	defer func() {
+		if _r := recover(); _r != nil {
+			_panic = fmt.Sprintf("%v\n%s", _r, debug.Stack())
+		}
+		pRoutine.cancel()
+		r.exit(pRoutine, _panic)
+		r.wait.Done()
+	}()
+
+	pRun(pCTX)
+}
+
+// exit removes the routine from the running routines & keeps it in the exited routines
+func (r *Registry) exit(pRoutine *routine, pPanic string) {
+
+	r.lock.Lock()
+
+	delete(r.routines, pRoutine.info.ID)
+
+	_info := pRoutine.info
+	_info.Exited = time.Now()
+	_info.Duration = _info.Exited.Sub(_info.Started).String()
+	_info.Panic = pPanic
+	if len(pPanic) > 0 {
+		r.panicked++
+	}
+
+	r.exited = append(r.exited, _info)
+	if len(r.exited) > MAX_EXITED {
+		r.exited = r.exited[len(r.exited)-MAX_EXITED:]
+	}
+	r.lock.Unlock()
+
+	if len(pPanic) > 0 && r.on_event != nil {
+		r.on_event(_info)
+	}
+}
+
+// resolve returns the unit name of the owner. Called with the lock
+func (r *Registry) resolve(pOwner IOwner) string {
+
+	if pOwner == nil {
+		return FRAMEWORK_OWNER
+	}
+	return r.owners[pOwner]
+}
+
+// Cancel_Owner cancels the contexts of the routines of the owner, when the owner is stopped.
+// Routines still running after LEAK_GRACE are reported as leaks. Returns the number of cancelled routines
+func (r *Registry) Cancel_Owner(pOwner IOwner) int {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.cancel_owner(pOwner)
+}
+
+// Remove_Owner cancels the routines of the owner & forgets the owner, when it is removed from the unit pool.
+// Returns the number of cancelled routines
+func (r *Registry) Remove_Owner(pOwner IOwner) int {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.owners, pOwner)
+	return r.cancel_owner(pOwner)
+}
+
+// cancel_owner cancels the running routines of the owner. Called with the lock
+func (r *Registry) cancel_owner(pOwner IOwner) int {
+
+	if pOwner == nil {
+		return 0
+	}
+
+	_ids := make([]uint64, 0)
+	for _id, _routine := range r.routines {
+		if _routine.owner == pOwner && _routine.info.Cancelled.IsZero() {
+			_routine.info.Cancelled = time.Now()
+			_routine.cancel()
+			_ids = append(_ids, _id)
+		}
+	}
+
+	if len(_ids) > 0 {
+		time.AfterFunc(r.grace, func() { r.check_leaks(_ids) })
+	}
+	return len(_ids)
+}
+
+// check_leaks reports the given routines as leaks if they are still running
+func (r *Registry) check_leaks(pIDs []uint64) {
+
+	_leaks := make([]fmtypes.RoutineInfo, 0)
+
+	r.lock.Lock()
+	for _, _id := range pIDs {
+		if _routine, _found := r.routines[_id]; _found && !_routine.info.Leaked {
+			_routine.info.Leaked = true
+			r.leaked++
+			_leaks = append(_leaks, _routine.info)
+		}
+	}
+	r.lock.Unlock()
+
+	if r.on_event != nil {
+		for _, _leak := range _leaks {
+			r.on_event(_leak)
+		}
+	}
+}
+
+// Count returns the number of running routines
+func (r *Registry) Count() int {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return len(r.routines)
+}
+
+// Stats returns the running & the last exited routines with the counters
+func (r *Registry) Stats() fmtypes.RoutineStats {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_stats := fmtypes.RoutineStats{
+		Started:  r.started,
+		Panicked: r.panicked,
+		Leaked:   r.leaked,
+		Running:  make([]fmtypes.RoutineInfo, 0, len(r.routines)),
+		Exited:   make([]fmtypes.RoutineInfo, len(r.exited)),
+	}
+
+	_now := time.Now()
+	for _, _routine := range r.routines {
+		_info := _routine.info
+		_info.Duration = _now.Sub(_info.Started).String()
+		_stats.Running = append(_stats.Running, _info)
+	}
+	sort.Slice(_stats.Running, func(pI int, pJ int) bool { return _stats.Running[pI].ID < _stats.Running[pJ].ID })
+
+	/// last exited first
+	for _index, _info := range r.exited {
+		_stats.Exited[len(r.exited)-1-_index] = _info
+	}
+	return _stats
+}
+
+// Close cancels all the routines & waits for them up to the timeout.
+// Routines not returned in the timeout are reported as leaks & returned
+func (r *Registry) Close(pTimeout time.Duration) []fmtypes.RoutineInfo {
+
+	r.lock.Lock()
+	r.closed = true
+	for _, _routine := range r.routines {
+		if _routine.info.Cancelled.IsZero() {
+			_routine.info.Cancelled = time.Now()
+			_routine.cancel()
+		}
+	}
+	r.lock.Unlock()
+
+	_done := make(chan struct{})
+	go func() {
+		r.wait.Wait()
+		close(_done)
+	}()
+
+	select {
+	case <-_done:
+		return nil
+	case <-time.After(pTimeout):
+	}
+
+	_ids := make([]uint64, 0)
+	r.lock.Lock()
+	for _id := range r.routines {
+		_ids = append(_ids, _id)
+	}
+	r.lock.Unlock()
+
+	r.check_leaks(_ids)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_leaks := make([]fmtypes.RoutineInfo, 0, len(r.routines))
+	for _, _routine := range r.routines {
+		_leaks = append(_leaks, _routine.info)
+	}
+	return _leaks
+}
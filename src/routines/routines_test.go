@@ -0,0 +1,313 @@
+package routines
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// owner is an owner instance of the tests
+type owner struct {
+	started atomic.Bool
+}
+
+func (o *owner) IsStarted() bool { return o.started.Load() }
+
+// events keeps the panics & leaks reported by the registry
+type events struct {
+	lock  *sync.Mutex
+	items []fmtypes.RoutineInfo
+}
+
+func (e *events) add(pInfo fmtypes.RoutineInfo) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.items = append(e.items, pInfo)
+}
+
+func (e *events) list() []fmtypes.RoutineInfo {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return append([]fmtypes.RoutineInfo{}, e.items...)
+}
+
+// new_registry returns a registry with a short leak grace & its events
+func new_registry() (*Registry, *events) {
+
+	_events := &events{lock: &sync.Mutex{}}
+	_registry := New(_events.add, nil)
+	_registry.grace = 30 * time.Millisecond
+	return _registry, _events
+}
+
+// wait_for waits up to a second for the condition
+func wait_for(t *testing.T, pWhat string, pCondition func() bool) {
+
+	_deadline := time.Now().Add(time.Second)
+	for !pCondition() {
+		if time.Now().After(_deadline) {
+			t.Fatal(pWhat + " is not reached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGo_Validate(t *testing.T) {
+
+	_registry, _ := new_registry()
+	_run := func(context.Context) {}
+
+	_cases := []struct {
+		name    string
+		routine string
+		run     Func
+		fails   string
+	}{
+		{name: "no name", run: _run, fails: "routine name is not given"},
+		{name: "no function", routine: "consumer", fails: "function of routine consumer is not given"},
+		{name: "started", routine: "consumer", run: _run},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_err := _registry.Go(nil, _case.routine, _case.run)
+			if len(_case.fails) == 0 {
+				if _err != nil {
+					t.Fatal(_err)
+				}
+				return
+			}
+			if _err == nil || _err.Error() != _case.fails {
+				t.Fatalf("expected %q, got %v", _case.fails, _err)
+			}
+		})
+	}
+
+	_registry.Close(time.Second)
+	if _err := _registry.Go(nil, "late", _run); _err == nil || !strings.Contains(_err.Error(), "framework is stopping") {
+		t.Fatalf("routine is started by the closed registry: %v", _err)
+	}
+}
+
+func TestRegistry_Exit_Panic(t *testing.T) {
+
+	_registry, _events := new_registry()
+	_unit := &owner{}
+	_registry.Set_Owner(_unit, "orders")
+
+	_registry.Go(_unit, "returns", func(context.Context) {})
+	_registry.Go(nil, "panics", func(context.Context) { panic("nil order") })
+
+	wait_for(t, "exit of the routines", func() bool { return _registry.Count() == 0 })
+
+	_stats := _registry.Stats()
+	if _stats.Started != 2 || _stats.Panicked != 1 || len(_stats.Exited) != 2 || len(_stats.Running) != 0 {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+
+	_owners := map[string]string{}
+	for _, _info := range _stats.Exited {
+		_owners[_info.Name] = _info.Owner
+		if _info.Exited.IsZero() || len(_info.Duration) == 0 {
+			t.Fatalf("exit of %s is not recorded", _info.Name)
+		}
+	}
+	if _owners["returns"] != "orders" || _owners["panics"] != FRAMEWORK_OWNER {
+		t.Fatalf("unexpected owners %v", _owners)
+	}
+
+	_reported := _events.list()
+	if len(_reported) != 1 || _reported[0].Name != "panics" || !strings.HasPrefix(_reported[0].Panic, "nil order") {
+		t.Fatalf("unexpected events %+v", _reported)
+	}
+}
+
+func TestRegistry_Leaks(t *testing.T) {
+
+	_cases := []struct {
+		name   string
+		stuck  bool /// routine ignores its context
+		leaked bool
+	}{
+		{name: "returns when cancelled"},
+		{name: "ignores the context", stuck: true, leaked: true},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_registry, _events := new_registry()
+			_unit := &owner{}
+			_other := &owner{}
+			_registry.Set_Owner(_unit, "orders")
+			_registry.Set_Owner(_other, "billing")
+
+			_release := make(chan struct{})
+			defer close(_release)
+
+			_registry.Go(_unit, "consumer", func(pCTX context.Context) {
+				if _case.stuck {
+					<-_release
+					return
+				}
+				<-pCTX.Done()
+			})
+			_registry.Go(_other, "poller", func(pCTX context.Context) { <-pCTX.Done() })
+
+			/// routines of the other owner are not cancelled
+			if _cancelled := _registry.Cancel_Owner(_unit); _cancelled != 1 {
+				t.Fatalf("expected 1 cancelled routine, got %d", _cancelled)
+			}
+			if _cancelled := _registry.Cancel_Owner(_unit); _cancelled != 0 {
+				t.Fatalf("cancelled routine is cancelled again")
+			}
+
+			time.Sleep(3 * _registry.grace)
+
+			_stats := _registry.Stats()
+			_expected := 1
+			if _case.stuck {
+				_expected = 2
+			}
+			if len(_stats.Running) != _expected {
+				t.Fatalf("expected %d running routines, got %+v", _expected, _stats.Running)
+			}
+			if _case.leaked != (_stats.Leaked == 1) {
+				t.Fatalf("unexpected leak count %d", _stats.Leaked)
+			}
+
+			_reported := _events.list()
+			if _case.leaked != (len(_reported) == 1) {
+				t.Fatalf("unexpected events %+v", _reported)
+			}
+			if _case.leaked && (!_reported[0].Leaked || _reported[0].Name != "consumer" || _reported[0].Owner != "orders") {
+				t.Fatalf("unexpected leak %+v", _reported[0])
+			}
+			for _, _info := range _stats.Running {
+				if _info.Name == "poller" && (!_info.Cancelled.IsZero() || _info.Leaked) {
+					t.Fatalf("routine of the other owner is cancelled %+v", _info)
+				}
+			}
+
+			/// leak is reported once
+			_registry.Cancel_Owner(_unit)
+			time.Sleep(3 * _registry.grace)
+			if _leaked := _registry.Stats().Leaked; _case.leaked && _leaked != 1 {
+				t.Fatalf("leak is reported %d times", _leaked)
+			}
+
+			_registry.Remove_Owner(_other)
+		})
+	}
+}
+
+func TestRegistry_Remove_Owner(t *testing.T) {
+
+	_registry, _ := new_registry()
+	_unit := &owner{}
+	_registry.Set_Owner(_unit, "orders")
+
+	_registry.Go(_unit, "consumer", func(pCTX context.Context) { <-pCTX.Done() })
+	if _removed := _registry.Remove_Owner(_unit); _removed != 1 {
+		t.Fatalf("expected 1 cancelled routine, got %d", _removed)
+	}
+	wait_for(t, "exit of the routine", func() bool { return _registry.Count() == 0 })
+
+	/// owner name is forgotten
+	_registry.Go(_unit, "late", func(context.Context) {})
+	wait_for(t, "exit of the late routine", func() bool { return _registry.Count() == 0 })
+	if _exited := _registry.Stats().Exited; _exited[0].Name != "late" || _exited[0].Owner != "" {
+		t.Fatalf("unexpected owner of the removed instance %+v", _exited[0])
+	}
+}
+
+func TestRegistry_Close(t *testing.T) {
+
+	_registry, _events := new_registry()
+	_release := make(chan struct{})
+	defer close(_release)
+
+	_registry.Go(nil, "returns", func(pCTX context.Context) { <-pCTX.Done() })
+	_registry.Go(nil, "stuck", func(context.Context) { <-_release })
+
+	_leaks := _registry.Close(50 * time.Millisecond)
+	if len(_leaks) != 1 || _leaks[0].Name != "stuck" || !_leaks[0].Leaked {
+		t.Fatalf("unexpected leaks %+v", _leaks)
+	}
+	if _reported := _events.list(); len(_reported) != 1 || _reported[0].Name != "stuck" {
+		t.Fatalf("unexpected events %+v", _reported)
+	}
+
+	_closed, _ := new_registry()
+	_closed.Go(nil, "returns", func(pCTX context.Context) { <-pCTX.Done() })
+	if _leaks := _closed.Close(time.Second); _leaks != nil {
+		t.Fatalf("routines returned in time are reported %+v", _leaks)
+	}
+}
+
+func TestRegistry_Max_Exited(t *testing.T) {
+
+	_registry, _ := new_registry()
+	for _index := 0; _index < MAX_EXITED+10; _index++ {
+		_registry.Go(nil, "short", func(context.Context) {})
+	}
+	wait_for(t, "exit of the routines", func() bool { return _registry.Count() == 0 })
+
+	_stats := _registry.Stats()
+	if len(_stats.Exited) != MAX_EXITED || _stats.Started != MAX_EXITED+10 {
+		t.Fatalf("unexpected exited routines %d of %d", len(_stats.Exited), _stats.Started)
+	}
+	/// last exited first
+	for _index := 1; _index < len(_stats.Exited); _index++ {
+		if _stats.Exited[_index-1].Exited.Before(_stats.Exited[_index].Exited) {
+			t.Fatal("exited routines are not listed last exited first")
+		}
+	}
+}
+
+// unit_key is the key of the unit name set in the owner context of the tests
+type unit_key struct{}
+
+func TestRegistry_Owner_Context(t *testing.T) {
+
+	_unit := &owner{}
+	_unit_ctx, _stop_unit := context.WithCancel(context.WithValue(context.Background(), unit_key{}, "orders"))
+	defer _stop_unit()
+
+	_registry := New(nil, func(pOwner IOwner) context.Context {
+		if pOwner == _unit {
+			return _unit_ctx
+		}
+		return nil
+	})
+
+	_values := make(chan any, 2)
+	_run := func(pCTX context.Context) {
+		_values <- pCTX.Value(unit_key{})
+		<-pCTX.Done()
+	}
+	_registry.Go(_unit, "consumer", _run)
+	_registry.Go(&owner{}, "unknown", _run)
+
+	if _value := <-_values; _value != "orders" && _value != nil {
+		t.Fatalf("unexpected context value %v", _value)
+	}
+	if _value := <-_values; _value != "orders" && _value != nil {
+		t.Fatalf("unexpected context value %v", _value)
+	}
+
+	/// routine is cancelled with the owner instance
+	_stop_unit()
+	wait_for(t, "exit of the routine of the stopped instance", func() bool { return _registry.Count() == 1 })
+	if _running := _registry.Stats().Running; _running[0].Name != "unknown" {
+		t.Fatalf("unexpected running routine %+v", _running[0])
+	}
+
+	_registry.Close(time.Second)
+}
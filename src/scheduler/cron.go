@@ -0,0 +1,237 @@
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   scheduler
+
+	Objective     :   Parse the cron expressions of the jobs and find their next run time
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package scheduler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CRON_SEARCH_YEARS define how far the next run of a cron expression is searched. eg: 30th of February never matches
+const CRON_SEARCH_YEARS = 5
+
+// cron_descriptors holds the expressions of the predefined schedules
+var cron_descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var month_names = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var weekday_names = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// cron_field holds the range & names of a field of the cron expression
+type cron_field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var cron_fields = []cron_field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: month_names},
+	{name: "weekday", min: 0, max: 7, names: weekday_names}, /// 7 is sunday too
+}
+
+// cron_spec holds the matching values of each field of a cron expression
+type cron_spec struct {
+	minute  []bool
+	hour    []bool
+	day     []bool
+	month   []bool
+	weekday []bool
+	any_day bool /// day is "*". matched by weekday only
+	any_wd  bool /// weekday is "*". matched by day only
+}
+
+// parse_cron parses the cron expression "minute hour day month weekday" or a descriptor like @daily.
+// Fields support *, lists (1,15), ranges (1-5), steps (*/10, 0-30/5) and the names of months & weekdays
+func parse_cron(pExpression string) (*cron_spec, error) {
+
+	_expression := strings.TrimSpace(pExpression)
+	if _descriptor, _found := cron_descriptors[strings.ToLower(_expression)]; _found {
+		_expression = _descriptor
+	}
+
+	_parts := strings.Fields(_expression)
+	if len(_parts) != len(cron_fields) {
+		return nil, errors.New("cron expression " + pExpression + " must have 5 fields: minute hour day month weekday")
+	}
+
+	_values := make([][]bool, len(cron_fields))
+	for _index, _field := range cron_fields {
+		_matches, _err := parse_cron_field(_parts[_index], _field)
+		if _err != nil {
+			return nil, errors.New("cron expression " + pExpression + " is not valid. " + _err.Error())
+		}
+		_values[_index] = _matches
+	}
+
+	/// 7 & 0 are sunday
+	_values[4][0] = _values[4][0] || _values[4][7]
+
+	return &cron_spec{
+		minute:  _values[0],
+		hour:    _values[1],
+		day:     _values[2],
+		month:   _values[3],
+		weekday: _values[4],
+		any_day: _parts[2] == "*",
+		any_wd:  _parts[4] == "*",
+	}, nil
+}
+
+// parse_cron_field returns the matching values of a field, indexed by value
+func parse_cron_field(pField string, pRange cron_field) ([]bool, error) {
+
+	_matches := make([]bool, pRange.max+1)
+
+	for _, _item := range strings.Split(pField, ",") {
+
+		_step := 1
+		if _slash := strings.Index(_item, "/"); _slash >= 0 {
+			_value, _err := strconv.Atoi(_item[_slash+1:])
+			if _err != nil || _value <= 0 {
+				return nil, errors.New(pRange.name + " step " + _item + " is not valid")
+			}
+			_step = _value
+			_item = _item[:_slash]
+		}
+
+		_from, _to := pRange.min, pRange.max
+		switch {
+		case _item == "*":
+		case strings.Contains(_item, "-"):
+			_bounds := strings.SplitN(_item, "-", 2)
+			_low, _err := cron_value(_bounds[0], pRange)
+			if _err != nil {
+				return nil, _err
+			}
+			_high, _err := cron_value(_bounds[1], pRange)
+			if _err != nil {
+				return nil, _err
+			}
+			if _low > _high {
+				return nil, errors.New(pRange.name + " range " + _item + " is not valid")
+			}
+			_from, _to = _low, _high
+		default:
+			_value, _err := cron_value(_item, pRange)
+			if _err != nil {
+				return nil, _err
+			}
+			_from = _value
+			if _step == 1 {
+				_to = _value
+			}
+		}
+
+		for _value := _from; _value <= _to; _value += _step {
+			_matches[_value] = true
+		}
+	}
+	return _matches, nil
+}
+
+// cron_value returns the value of a number or a name in the field range
+func cron_value(pValue string, pRange cron_field) (int, error) {
+
+	if _value, _found := pRange.names[strings.ToLower(pValue)]; _found {
+		return _value, nil
+	}
+
+	_value, _err := strconv.Atoi(pValue)
+	if _err != nil || _value < pRange.min || _value > pRange.max {
+		return 0, errors.New(pRange.name + " " + pValue + " is not in " + strconv.Itoa(pRange.min) + "-" + strconv.Itoa(pRange.max))
+	}
+	return _value, nil
+}
+
+// day_matches checks the day & weekday of the time.
+// If both are restricted, the time matches either of them, as in the standard cron
+func (c *cron_spec) day_matches(pTime time.Time) bool {
+
+	_day := c.day[pTime.Day()]
+	_weekday := c.weekday[int(pTime.Weekday())]
+
+	switch {
+	case c.any_day && c.any_wd:
+		return true
+	case c.any_day:
+		return _weekday
+	case c.any_wd:
+		return _day
+	}
+	return _day || _weekday
+}
+
+// next returns the first matching minute after the given time. Zero time if there is no match in CRON_SEARCH_YEARS
+func (c *cron_spec) next(pFrom time.Time) time.Time {
+
+	_time := pFrom.Truncate(time.Minute).Add(time.Minute)
+	_limit := _time.AddDate(CRON_SEARCH_YEARS, 0, 0)
+	_location := _time.Location()
+
+	for _time.Before(_limit) {
+		switch {
+		case !c.month[int(_time.Month())]:
+			_time = time.Date(_time.Year(), _time.Month()+1, 1, 0, 0, 0, 0, _location)
+
+		case !c.day_matches(_time):
+			_time = time.Date(_time.Year(), _time.Month(), _time.Day()+1, 0, 0, 0, 0, _location)
+
+		case !c.hour[_time.Hour()]:
+			_time = time.Date(_time.Year(), _time.Month(), _time.Day(), _time.Hour()+1, 0, 0, 0, _location)
+
+		case !c.minute[_time.Minute()]:
+			_time = _time.Add(time.Minute)
+
+		default:
+			return _time
+		}
+	}
+	return time.Time{}
+}
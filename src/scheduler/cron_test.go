@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// matching returns the matching values of a field as text. eg: 0,15,30,45
+func matching(pValues []bool) string {
+
+	_matching := make([]string, 0)
+	for _value, _matches := range pValues {
+		if _matches {
+			_matching = append(_matching, strconv.Itoa(_value))
+		}
+	}
+	return strings.Join(_matching, ",")
+}
+
+func TestParse_Cron(t *testing.T) {
+
+	_cases := []struct {
+		expression string
+		field      func(pSpec *cron_spec) []bool
+		matches    string
+		fails      string
+	}{
+		{expression: "*/15 * * * *", field: func(c *cron_spec) []bool { return c.minute }, matches: "0,15,30,45"},
+		{expression: "0-30/10 * * * *", field: func(c *cron_spec) []bool { return c.minute }, matches: "0,10,20,30"},
+		{expression: "5/20 * * * *", field: func(c *cron_spec) []bool { return c.minute }, matches: "5,25,45"},
+		{expression: "1,15,59 * * * *", field: func(c *cron_spec) []bool { return c.minute }, matches: "1,15,59"},
+		{expression: "0 9-17/4 * * *", field: func(c *cron_spec) []bool { return c.hour }, matches: "9,13,17"},
+		{expression: "0 0 * jan-mar,DEC *", field: func(c *cron_spec) []bool { return c.month }, matches: "1,2,3,12"},
+		{expression: "0 0 * * mon-fri", field: func(c *cron_spec) []bool { return c.weekday }, matches: "1,2,3,4,5"},
+		{expression: "0 0 * * Sat,sun", field: func(c *cron_spec) []bool { return c.weekday }, matches: "0,6"},
+		{expression: "0 0 * * 7", field: func(c *cron_spec) []bool { return c.weekday }, matches: "0,7"},
+		{expression: "0 0 * * 5-7", field: func(c *cron_spec) []bool { return c.weekday }, matches: "0,5,6,7"},
+		{expression: "@daily", field: func(c *cron_spec) []bool { return c.hour }, matches: "0"},
+		{expression: " @Weekly ", field: func(c *cron_spec) []bool { return c.weekday }, matches: "0"},
+		{expression: "@hourly", field: func(c *cron_spec) []bool { return c.minute }, matches: "0"},
+		{expression: "* * * * *", field: func(c *cron_spec) []bool { return c.hour }, matches: "0,1,2,3,4,5,6,7,8,9,10,11," +
+			"12,13,14,15,16,17,18,19,20,21,22,23"},
+
+		{expression: "* * * *", fails: "must have 5 fields"},
+		{expression: "* * * * * *", fails: "must have 5 fields"},
+		{expression: "60 * * * *", fails: "minute 60 is not in 0-59"},
+		{expression: "* 24 * * *", fails: "hour 24 is not in 0-23"},
+		{expression: "* * 0 * *", fails: "day 0 is not in 1-31"},
+		{expression: "* * * 13 *", fails: "month 13 is not in 1-12"},
+		{expression: "* * * * 8", fails: "weekday 8 is not in 0-7"},
+		{expression: "*/0 * * * *", fails: "minute step */0 is not valid"},
+		{expression: "*/x * * * *", fails: "minute step */x is not valid"},
+		{expression: "30-10 * * * *", fails: "minute range 30-10 is not valid"},
+		{expression: "* * * foo *", fails: "month foo is not in 1-12"},
+		{expression: "@never", fails: "must have 5 fields"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.expression, func(t *testing.T) {
+
+			_spec, _err := parse_cron(_case.expression)
+
+			if len(_case.fails) > 0 {
+				if _err == nil || !strings.Contains(_err.Error(), _case.fails) {
+					t.Fatalf("expected %q, got %v", _case.fails, _err)
+				}
+				return
+			}
+			if _err != nil {
+				t.Fatal(_err)
+			}
+
+			if _found := matching(_case.field(_spec)); _found != _case.matches {
+				t.Fatalf("expected %s, got %s", _case.matches, _found)
+			}
+		})
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+
+	_time := func(pValue string) time.Time {
+		_parsed, _err := time.ParseInLocation("2006-01-02 15:04:05", pValue, time.UTC)
+		if _err != nil {
+			t.Fatal(_err)
+		}
+		return _parsed
+	}
+
+	_cases := []struct {
+		name       string
+		expression string
+		from       string
+		next       string
+	}{
+		{name: "next minute", expression: "* * * * *", from: "2026-10-19 10:15:30", next: "2026-10-19 10:16:00"},
+		{name: "on the minute", expression: "* * * * *", from: "2026-10-19 10:15:00", next: "2026-10-19 10:16:00"},
+		{name: "step", expression: "*/30 * * * *", from: "2026-10-19 10:29:59", next: "2026-10-19 10:30:00"},
+		{name: "next hour", expression: "0 * * * *", from: "2026-10-19 10:00:00", next: "2026-10-19 11:00:00"},
+		{name: "next day", expression: "30 2 * * *", from: "2026-10-19 03:00:00", next: "2026-10-20 02:30:00"},
+		{name: "month end", expression: "0 0 1 * *", from: "2026-01-31 10:00:00", next: "2026-02-01 00:00:00"},
+		{name: "month end of a 30 day month", expression: "0 0 * * *", from: "2026-04-30 23:59:00", next: "2026-05-01 00:00:00"},
+		{name: "year end", expression: "0 0 * * *", from: "2026-12-31 23:59:00", next: "2027-01-01 00:00:00"},
+		{name: "last minute of the year", expression: "59 23 31 12 *", from: "2026-12-31 23:59:00", next: "2027-12-31 23:59:00"},
+		{name: "31st skips the short months", expression: "0 0 31 * *", from: "2026-04-01 00:00:00", next: "2026-05-31 00:00:00"},
+		{name: "leap day", expression: "0 12 29 2 *", from: "2027-03-01 00:00:00", next: "2028-02-29 12:00:00"},
+		{name: "month name", expression: "0 0 1 jan *", from: "2026-10-19 00:00:00", next: "2027-01-01 00:00:00"},
+		{name: "weekday", expression: "0 0 * * mon", from: "2026-10-19 00:00:00", next: "2026-10-26 00:00:00"},
+		{name: "7 is sunday", expression: "0 0 * * 7", from: "2026-10-19 00:00:00", next: "2026-10-25 00:00:00"},
+		{name: "day or weekday. weekday first", expression: "0 9 13 * fri", from: "2026-10-01 00:00:00", next: "2026-10-02 09:00:00"},
+		{name: "day or weekday. day first", expression: "0 9 13 * fri", from: "2026-10-10 00:00:00", next: "2026-10-13 09:00:00"},
+		{name: "day only", expression: "0 9 13 * *", from: "2026-10-14 00:00:00", next: "2026-11-13 09:00:00"},
+		{name: "weekday across the year end", expression: "0 0 * * thu", from: "2026-12-31 00:00:00", next: "2027-01-07 00:00:00"},
+		{name: "never", expression: "0 0 30 2 *", from: "2026-10-19 00:00:00", next: ""},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_spec, _err := parse_cron(_case.expression)
+			if _err != nil {
+				t.Fatal(_err)
+			}
+
+			_next := _spec.next(_time(_case.from))
+			if len(_case.next) == 0 {
+				if !_next.IsZero() {
+					t.Fatalf("expected no next run, got %v", _next)
+				}
+				return
+			}
+			if !_next.Equal(_time(_case.next)) {
+				t.Fatalf("expected %s, got %s", _case.next, _next.Format("2006-01-02 15:04:05 Mon"))
+			}
+		})
+	}
+}
@@ -0,0 +1,526 @@
+// scheduler package provides the periodic & cron jobs shared by the units of the framework instance
+//
+// A job runs on a cron expression (Cron), at a fixed rate (Every) or with a fixed delay after the previous run (Delay).
+// Jitter adds a random delay to each run. A run is skipped while the previous run is running, unless Overlap is set.
+//
+// Each pool instance of a unit schedules the job with the same name. The job runs on every started instance,
+// or on one started instance at a time if Singleton is set. A run is cancelled when its instance is stopped.
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   scheduler
+
+	Objective     :   Cron, fixed-rate & fixed-delay jobs of the units with jitter, overlap prevention & run status
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+	Ajith de Silva		19/10/2026	Updated 	Ran the jobs with the context of the owner instance
+
+#########################################################################################
+*/
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// Func is the function of the job. Context is done when the owner instance is stopped,
+// the job is unscheduled or the scheduler is closed
+type Func func(pCTX context.Context) error
+
+// Job holds the schedule of a job. One of Cron, Every or Delay must be set
+type Job struct {
+	Name      string
+	Cron      string        /// "minute hour day month weekday" or @hourly, @daily, @weekly, @monthly, @yearly. local time
+	Every     time.Duration /// fixed-rate. runs at every interval from the time it is scheduled
+	Delay     time.Duration /// fixed-delay. runs after the interval from the end of the previous run
+	Jitter    time.Duration /// random delay up to Jitter added to each run
+	Overlap   bool          /// starts a run while the previous run is running. the run is skipped if false
+	Singleton bool          /// runs on one started instance of the pool. runs on every started instance if false
+}
+
+// IOwner is the unit instance scheduling the job. Jobs run only on the started instances
+type IOwner interface {
+	IsStarted() bool
+}
+
+// IJobs defines the scheduler functions of the framework instance.
+// Units check the framework instance for it:
+//
+//	if _jobs, _ok := pApp.(scheduler.IJobs); _ok { ... }
+type IJobs interface {
+	Schedule_Job(pOwner IOwner, pJob Job, pRun Func) error
+	Unschedule_Job(pOwner IOwner, pName string)
+}
+
+// registration is the function of the job scheduled by an owner instance
+type registration struct {
+	owner  IOwner
+	run    Func
+	ctx    context.Context /// done when the registration is removed or the job is stopped
+	cancel context.CancelFunc
+}
+
+// new_registration returns the registration of the owner with a context derived from the job context
+func new_registration(pJob *job, pOwner IOwner, pRun Func) *registration {
+
+	_registration := &registration{owner: pOwner, run: pRun}
+	_registration.ctx, _registration.cancel = context.WithCancel(pJob.ctx)
+	return _registration
+}
+
+// job holds the registrations, the state & the run status of a job
+type job struct {
+	spec          Job
+	cron          *cron_spec
+	lock          *sync.Mutex
+	registrations []*registration
+	next          int /// next registration of the singleton job
+	running       int
+	paused        bool
+	ctx           context.Context
+	cancel        context.CancelFunc
+	status        fmtypes.JobStatus
+}
+
+// Scheduler holds the jobs by name
+type Scheduler struct {
+	lock    *sync.Mutex
+	jobs    map[string]*job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wait    *sync.WaitGroup                     /// schedule routines of the jobs
+	context func(pOwner IOwner) context.Context /// context of the owner instance. may be nil
+}
+
+// New creates a new scheduler. pContext returns the context of the owner instance, done when the instance is stopped.
+// Runs are cancelled with the context of their owner. nil if the runs are cancelled with the job only
+func New(pContext func(pOwner IOwner) context.Context) *Scheduler {
+
+	_ctx, _cancel := context.WithCancel(context.Background())
+	return &Scheduler{lock: &sync.Mutex{}, jobs: make(map[string]*job), ctx: _ctx, cancel: _cancel, wait: &sync.WaitGroup{},
+		context: pContext}
+}
+
+// Schedule schedules the job for the owner instance.
+// Instances of a pool schedule the job with the same name & schedule. Function of the same owner replaces the previous one,
+// and its run in progress is cancelled
+func (s *Scheduler) Schedule(pOwner IOwner, pJob Job, pRun Func) error {
+
+	if pOwner == nil {
+		return errors.New("owner of job " + pJob.Name + " is not given")
+	}
+	if len(pJob.Name) == 0 {
+		return errors.New("job name is not given")
+	}
+	if pRun == nil {
+		return errors.New("function of job " + pJob.Name + " is not given")
+	}
+
+	_cron, _err := validate(pJob)
+	if _err != nil {
+		return _err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.ctx.Err() != nil {
+		return errors.New("scheduler is closed")
+	}
+
+	_job, _found := s.jobs[pJob.Name]
+	if _found {
+		if _job.spec != pJob {
+			return errors.New("job " + pJob.Name + " is already scheduled with a different schedule")
+		}
+
+		_job.lock.Lock()
+		defer _job.lock.Unlock()
+
+		for _index, _registration := range _job.registrations {
+			if _registration.owner == pOwner {
+				_registration.cancel()
+				_job.registrations[_index] = new_registration(_job, pOwner, pRun)
+				return nil
+			}
+		}
+		_job.registrations = append(_job.registrations, new_registration(_job, pOwner, pRun))
+		return nil
+	}
+
+	_job = &job{spec: pJob, cron: _cron, lock: &sync.Mutex{}}
+	_job.ctx, _job.cancel = context.WithCancel(s.ctx)
+	_job.registrations = []*registration{new_registration(_job, pOwner, pRun)}
+	_job.status = fmtypes.JobStatus{Name: pJob.Name, Schedule: schedule_text(pJob), Singleton: pJob.Singleton}
+	s.jobs[pJob.Name] = _job
+
+	s.wait.Add(1)
+	go s.schedule(_job)
+	return nil
+}
+
+// validate checks the schedule of the job. Returns the parsed cron expression of a cron job
+func validate(pJob Job) (*cron_spec, error) {
+
+	_schedules := 0
+	for _, _set := range []bool{len(pJob.Cron) > 0, pJob.Every != 0, pJob.Delay != 0} {
+		if _set {
+			_schedules++
+		}
+	}
+	if _schedules != 1 {
+		return nil, errors.New("job " + pJob.Name + " must have one of cron, every or delay")
+	}
+	if pJob.Every < 0 || pJob.Delay < 0 || pJob.Jitter < 0 {
+		return nil, errors.New("intervals of job " + pJob.Name + " must be positive")
+	}
+
+	if len(pJob.Cron) == 0 {
+		return nil, nil
+	}
+	return parse_cron(pJob.Cron)
+}
+
+// schedule_text returns the schedule of the job for the run status
+func schedule_text(pJob Job) string {
+
+	switch {
+	case len(pJob.Cron) > 0:
+		return "cron " + pJob.Cron
+	case pJob.Every > 0:
+		return "every " + pJob.Every.String()
+	}
+	return "delay " + pJob.Delay.String()
+}
+
+// Unschedule removes the function of the job scheduled by the owner instance.
+// Job is stopped when the last owner is removed
+func (s *Scheduler) Unschedule(pOwner IOwner, pName string) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.remove(pOwner, pName)
+}
+
+// Remove_Owner removes all the jobs scheduled by the owner instance.
+// Called by the framework when the instance is removed from the unit pool. Returns the number of removed jobs
+func (s *Scheduler) Remove_Owner(pOwner IOwner) int {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_removed := 0
+	for _name := range s.jobs {
+		if s.remove(pOwner, _name) {
+			_removed++
+		}
+	}
+	return _removed
+}
+
+// remove removes the registration of the owner from the job. Called with the lock
+func (s *Scheduler) remove(pOwner IOwner, pName string) bool {
+
+	_job, _found := s.jobs[pName]
+	if !_found {
+		return false
+	}
+
+	_job.lock.Lock()
+	defer _job.lock.Unlock()
+
+	_index := slices.IndexFunc(_job.registrations, func(pRegistration *registration) bool {
+		return pRegistration.owner == pOwner
+	})
+	if _index < 0 {
+		return false
+	}
+
+	/// run in progress on the owner is cancelled
+	_job.registrations[_index].cancel()
+	_job.registrations = slices.Delete(_job.registrations, _index, _index+1)
+	if len(_job.registrations) == 0 {
+		_job.cancel()
+		delete(s.jobs, pName)
+	}
+	return true
+}
+
+// Trigger starts a run of the job now, paused or not. Returns error if the job is running & overlap is not allowed
+func (s *Scheduler) Trigger(pName string) error {
+
+	_job, _err := s.job(pName)
+	if _err != nil {
+		return _err
+	}
+	if _, _started := s.start(_job); !_started {
+		return errors.New("job " + pName + " is running")
+	}
+	return nil
+}
+
+// Pause pauses or resumes the job. Paused job skips the scheduled runs, but can be triggered
+func (s *Scheduler) Pause(pName string, pPause bool) error {
+
+	_job, _err := s.job(pName)
+	if _err != nil {
+		return _err
+	}
+
+	_job.lock.Lock()
+	_job.paused = pPause
+	_job.lock.Unlock()
+	return nil
+}
+
+// job returns the job of the name
+func (s *Scheduler) job(pName string) (*job, error) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_job, _found := s.jobs[pName]
+	if !_found {
+		return nil, errors.New("job " + pName + " is not found")
+	}
+	return _job, nil
+}
+
+// Jobs returns the run status of the jobs, sorted by name
+func (s *Scheduler) Jobs() []fmtypes.JobStatus {
+
+	s.lock.Lock()
+	_jobs := make([]*job, 0, len(s.jobs))
+	for _, _job := range s.jobs {
+		_jobs = append(_jobs, _job)
+	}
+	s.lock.Unlock()
+
+	_status := make([]fmtypes.JobStatus, 0, len(_jobs))
+	for _, _job := range _jobs {
+		_job.lock.Lock()
+		_item := _job.status
+		_item.Paused = _job.paused
+		_item.Running = _job.running > 0
+		_item.Instances = len(_job.registrations)
+		_job.lock.Unlock()
+
+		_status = append(_status, _item)
+	}
+
+	sort.Slice(_status, func(pI int, pJ int) bool { return _status[pI].Name < _status[pJ].Name })
+	return _status
+}
+
+// Close stops all the jobs. Contexts of the running functions are cancelled
+func (s *Scheduler) Close() {
+
+	s.lock.Lock()
+	s.cancel()
+	s.jobs = make(map[string]*job)
+	s.lock.Unlock()
+
+	s.wait.Wait()
+}
+
+// schedule waits for the next run time of the job & starts the run, until the job is removed
+func (s *Scheduler) schedule(pJob *job) {
+
+	defer s.wait.Done()
+
+	_base := time.Now()
+	for {
+		/// fixed-rate keeps the interval from the previous planned time, skipping the missed runs
+		switch {
+		case pJob.cron != nil:
+			_base = pJob.cron.next(time.Now())
+			if _base.IsZero() {
+				pJob.set_next(time.Time{})
+				<-pJob.ctx.Done()
+				return
+			}
+		case pJob.spec.Every > 0:
+			_base = _base.Add(pJob.spec.Every)
+			for _now := time.Now(); _base.Before(_now); {
+				_base = _base.Add(pJob.spec.Every)
+			}
+		default:
+			_base = time.Now().Add(pJob.spec.Delay)
+		}
+
+		_run_at := _base
+		if pJob.spec.Jitter > 0 {
+			_run_at = _run_at.Add(rand.N(pJob.spec.Jitter))
+		}
+		pJob.set_next(_run_at)
+
+		_timer := time.NewTimer(time.Until(_run_at))
+		select {
+		case <-pJob.ctx.Done():
+			_timer.Stop()
+			return
+		case <-_timer.C:
+		}
+
+		pJob.lock.Lock()
+		_paused := pJob.paused
+		pJob.lock.Unlock()
+		if _paused {
+			continue
+		}
+
+		_done, _started := s.start(pJob)
+		if !_started || pJob.spec.Delay == 0 {
+			continue
+		}
+
+		/// fixed-delay waits for the end of the run
+		select {
+		case <-pJob.ctx.Done():
+			return
+		case <-_done:
+		}
+	}
+}
+
+// set_next sets the next run time of the job status
+func (j *job) set_next(pTime time.Time) {
+
+	j.lock.Lock()
+	j.status.Next_Run = pTime
+	j.lock.Unlock()
+}
+
+// start starts a run of the job on the started owners. Returns a channel closed at the end of the run.
+// Run is skipped if the previous run is running & overlap is not allowed
+func (s *Scheduler) start(pJob *job) (chan struct{}, bool) {
+
+	pJob.lock.Lock()
+	if pJob.running > 0 && !pJob.spec.Overlap {
+		pJob.status.Skipped++
+		pJob.lock.Unlock()
+		return nil, false
+	}
+	pJob.running++
+	_registrations := slices.Clone(pJob.registrations)
+	_next := pJob.next
+	pJob.next++
+	pJob.lock.Unlock()
+
+	_done := make(chan struct{})
+
+	go func() {
+		defer close(_done)
+
+		_start := time.Now()
+		_ran, _err := s.run(pJob, _registrations, _next)
+
+		pJob.lock.Lock()
+		defer pJob.lock.Unlock()
+
+		pJob.running--
+		if !_ran {
+			pJob.status.Skipped++
+			return
+		}
+
+		pJob.status.Runs++
+		pJob.status.Last_Run = _start
+		pJob.status.Last_Duration = time.Since(_start).String()
+		pJob.status.Last_Error = ""
+		if _err != nil {
+			pJob.status.Failures++
+			pJob.status.Last_Error = _err.Error()
+		}
+	}()
+
+	return _done, true
+}
+
+// run calls the functions of the started owners. Singleton job calls one started owner, starting from pNext.
+// Returns false if no owner is started
+func (s *Scheduler) run(pJob *job, pRegistrations []*registration, pNext int) (bool, error) {
+
+	/// owner state is checked without the lock. unit process answers over RPC
+	_started := make([]*registration, 0, len(pRegistrations))
+	for _tried := 0; _tried < len(pRegistrations); _tried++ {
+		_registration := pRegistrations[(pNext+_tried)%len(pRegistrations)]
+		if _registration.owner.IsStarted() {
+			_started = append(_started, _registration)
+			if pJob.spec.Singleton {
+				break
+			}
+		}
+	}
+	if len(_started) == 0 {
+		return false, nil
+	}
+
+	_errors := make([]error, len(_started))
+	_wait := &sync.WaitGroup{}
+
+	for _index, _registration := range _started {
+		_wait.Add(1)
+		go func() {
+			defer _wait.Done()
+			defer func() {
+				if _r := recover(); _r != nil {
+					_errors[_index] = fmt.Errorf("job %s panicked. %v", pJob.spec.Name, _r)
+				}
+			}()
+			_ctx, _cancel := s.run_context(_registration)
+			defer _cancel()
+
+			_errors[_index] = _registration.run(_ctx)
+		}()
+	}
+
+	_wait.Wait()
+	return true, errors.Join(_errors...)
+}
+
+// run_context returns the context of a run on the owner. It is done when the owner instance is stopped,
+// the job of the owner is unscheduled or the scheduler is closed
+func (s *Scheduler) run_context(pRegistration *registration) (context.Context, context.CancelFunc) {
+
+	if s.context == nil {
+		return context.WithCancel(pRegistration.ctx)
+	}
+
+	_ctx, _cancel := context.WithCancel(s.context(pRegistration.owner))
+	_stop := context.AfterFunc(pRegistration.ctx, _cancel)
+	return _ctx, func() {
+		_stop()
+		_cancel()
+	}
+}
@@ -0,0 +1,387 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// owner is an owner instance of the tests
+type owner struct {
+	name    string
+	started atomic.Bool
+}
+
+func (o *owner) IsStarted() bool { return o.started.Load() }
+
+// new_owner returns a started owner
+func new_owner(pName string) *owner {
+	_owner := &owner{name: pName}
+	_owner.started.Store(true)
+	return _owner
+}
+
+// wait_for waits up to a second for the condition
+func wait_for(t *testing.T, pWhat string, pCondition func() bool) {
+
+	_deadline := time.Now().Add(time.Second)
+	for !pCondition() {
+		if time.Now().After(_deadline) {
+			t.Fatal(pWhat + " is not reached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// job_status returns the run status of the job
+func job_status(t *testing.T, pScheduler *Scheduler, pName string) (_status struct {
+	runs, skipped, failures uint64
+	error                   string
+	running                 bool
+}) {
+
+	for _, _job := range pScheduler.Jobs() {
+		if _job.Name == pName {
+			_status.runs, _status.skipped, _status.failures = _job.Runs, _job.Skipped, _job.Failures
+			_status.error, _status.running = _job.Last_Error, _job.Running
+			return
+		}
+	}
+	t.Fatal("job " + pName + " is not found")
+	return
+}
+
+func TestSchedule_Validate(t *testing.T) {
+
+	_run := func(context.Context) error { return nil }
+
+	_cases := []struct {
+		name  string
+		job   Job
+		owner IOwner
+		run   Func
+		fails string
+	}{
+		{name: "no schedule", job: Job{Name: "sync"}, fails: "must have one of cron, every or delay"},
+		{name: "two schedules", job: Job{Name: "sync", Every: time.Second, Delay: time.Second},
+			fails: "must have one of cron, every or delay"},
+		{name: "negative", job: Job{Name: "sync", Every: -time.Second}, fails: "must be positive"},
+		{name: "negative jitter", job: Job{Name: "sync", Every: time.Second, Jitter: -time.Second}, fails: "must be positive"},
+		{name: "invalid cron", job: Job{Name: "sync", Cron: "* * *"}, fails: "must have 5 fields"},
+		{name: "no name", job: Job{Every: time.Second}, fails: "job name is not given"},
+		{name: "no owner", job: Job{Name: "sync", Every: time.Second}, owner: IOwner(nil), fails: "owner of job sync is not given"},
+		{name: "no function", job: Job{Name: "sync", Every: time.Second}, fails: "function of job sync is not given"},
+		{name: "different schedule", job: Job{Name: "existing", Every: 2 * time.Hour}, fails: "already scheduled with a different schedule"},
+		{name: "same schedule", job: Job{Name: "existing", Every: time.Hour}},
+	}
+
+	_scheduler := New(nil)
+	defer _scheduler.Close()
+	_scheduler.Schedule(new_owner("a"), Job{Name: "existing", Every: time.Hour}, _run)
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_owner, _function := _case.owner, _case.run
+			if _owner == nil && _case.name != "no owner" {
+				_owner = new_owner("b")
+			}
+			if _function == nil && _case.name != "no function" {
+				_function = _run
+			}
+
+			_err := _scheduler.Schedule(_owner, _case.job, _function)
+			if len(_case.fails) == 0 {
+				if _err != nil {
+					t.Fatal(_err)
+				}
+				return
+			}
+			if _err == nil || !strings.Contains(_err.Error(), _case.fails) {
+				t.Fatalf("expected %q, got %v", _case.fails, _err)
+			}
+		})
+	}
+}
+
+func TestScheduler_Every(t *testing.T) {
+
+	_scheduler := New(nil)
+	defer _scheduler.Close()
+
+	_runs := atomic.Int32{}
+	_scheduler.Schedule(new_owner("a"), Job{Name: "tick", Every: 10 * time.Millisecond}, func(context.Context) error {
+		_runs.Add(1)
+		return nil
+	})
+
+	wait_for(t, "3 runs", func() bool { return _runs.Load() >= 3 })
+	wait_for(t, "3 runs in the status", func() bool { return job_status(t, _scheduler, "tick").runs >= 3 })
+}
+
+func TestScheduler_Overlap(t *testing.T) {
+
+	_cases := []struct {
+		name    string
+		job     Job
+		overlap bool
+	}{
+		{name: "fixed-rate skips the overlapping runs", job: Job{Name: "slow", Every: 5 * time.Millisecond}},
+		{name: "fixed-rate with overlap", job: Job{Name: "slow", Every: 5 * time.Millisecond, Overlap: true}, overlap: true},
+		{name: "fixed-delay waits for the run", job: Job{Name: "slow", Delay: 5 * time.Millisecond}},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_scheduler := New(nil)
+			defer _scheduler.Close()
+
+			_running := atomic.Int32{}
+			_max := atomic.Int32{}
+			_runs := atomic.Int32{}
+			_scheduler.Schedule(new_owner("a"), _case.job, func(pCTX context.Context) error {
+				_count := _running.Add(1)
+				defer _running.Add(-1)
+				if _count > _max.Load() {
+					_max.Store(_count)
+				}
+				select {
+				case <-time.After(30 * time.Millisecond):
+				case <-pCTX.Done():
+				}
+				_runs.Add(1)
+				return nil
+			})
+
+			wait_for(t, "3 runs", func() bool { return _runs.Load() >= 3 })
+
+			_skipped := job_status(t, _scheduler, "slow").skipped
+			switch {
+			case _case.overlap && _max.Load() < 2:
+				t.Fatal("runs did not overlap")
+			case !_case.overlap && _max.Load() > 1:
+				t.Fatalf("%d runs overlapped", _max.Load())
+			case _case.job.Every > 0 && !_case.overlap && _skipped == 0:
+				t.Fatal("overlapping runs are not skipped")
+			case _case.job.Delay > 0 && _skipped != 0:
+				t.Fatalf("fixed-delay skipped %d runs", _skipped)
+			}
+		})
+	}
+}
+
+func TestScheduler_Owners(t *testing.T) {
+
+	_cases := []struct {
+		name      string
+		singleton bool
+		stopped   string
+		expected  string /// owners of the first 4 runs
+	}{
+		{name: "every owner", expected: "ab,ab,ab,ab"},
+		{name: "singleton", singleton: true, expected: "a,b,a,b"},
+		{name: "stopped owner", stopped: "a", expected: "b,b,b,b"},
+		{name: "singleton with stopped owner", singleton: true, stopped: "b", expected: "a,a,a,a"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_scheduler := New(nil)
+			defer _scheduler.Close()
+
+			_lock := &sync.Mutex{}
+			_runs := make([]string, 0)
+			_run := ""
+			_job := Job{Name: "sync", Delay: time.Hour, Singleton: _case.singleton}
+
+			for _, _name := range []string{"a", "b"} {
+				_owner := new_owner(_name)
+				_owner.started.Store(_name != _case.stopped)
+				_scheduler.Schedule(_owner, _job, func(context.Context) error {
+					_lock.Lock()
+					_run += _owner.name
+					_lock.Unlock()
+					return nil
+				})
+			}
+
+			/// runs are triggered one by one, so that the owners of a run are known
+			for _index := 0; _index < 4; _index++ {
+				if _err := _scheduler.Trigger("sync"); _err != nil {
+					t.Fatal(_err)
+				}
+				wait_for(t, "end of the run", func() bool {
+					_status := job_status(t, _scheduler, "sync")
+					return !_status.running && _status.runs == uint64(_index+1)
+				})
+
+				_lock.Lock()
+				_owners := []byte(_run)
+				if len(_owners) == 2 && _owners[0] > _owners[1] {
+					_owners[0], _owners[1] = _owners[1], _owners[0]
+				}
+				_runs = append(_runs, string(_owners))
+				_run = ""
+				_lock.Unlock()
+			}
+
+			if _found := strings.Join(_runs, ","); _found != _case.expected {
+				t.Fatalf("expected %s, got %s", _case.expected, _found)
+			}
+		})
+	}
+}
+
+func TestScheduler_Status(t *testing.T) {
+
+	_scheduler := New(nil)
+	defer _scheduler.Close()
+
+	_owner := new_owner("a")
+	_result := make(chan error, 1)
+	_scheduler.Schedule(_owner, Job{Name: "report", Delay: time.Hour}, func(context.Context) error {
+		switch _err := <-_result; {
+		case _err != nil && _err.Error() == "panic":
+			panic("nil report")
+		default:
+			return _err
+		}
+	})
+
+	_cases := []struct {
+		name   string
+		result error
+		error  string
+	}{
+		{name: "failed", result: errors.New("no data"), error: "no data"},
+		{name: "panicked", result: errors.New("panic"), error: "job report panicked. nil report"},
+		{name: "succeeded", result: nil, error: ""},
+	}
+
+	for _index, _case := range _cases {
+		_result <- _case.result
+		_scheduler.Trigger("report")
+		wait_for(t, _case.name, func() bool { return job_status(t, _scheduler, "report").runs == uint64(_index+1) })
+
+		if _status := job_status(t, _scheduler, "report"); _status.error != _case.error {
+			t.Fatalf("%s: expected error %q, got %q", _case.name, _case.error, _status.error)
+		}
+	}
+	if _failures := job_status(t, _scheduler, "report").failures; _failures != 2 {
+		t.Fatalf("expected 2 failures, got %d", _failures)
+	}
+
+	/// run without a started owner is skipped
+	_owner.started.Store(false)
+	_scheduler.Trigger("report")
+	wait_for(t, "skipped run", func() bool { return job_status(t, _scheduler, "report").skipped == 1 })
+
+	if _err := _scheduler.Trigger("unknown"); _err == nil {
+		t.Fatal("unknown job is triggered")
+	}
+}
+
+func TestScheduler_Pause(t *testing.T) {
+
+	_scheduler := New(nil)
+	defer _scheduler.Close()
+
+	_runs := atomic.Int32{}
+	_scheduler.Schedule(new_owner("a"), Job{Name: "tick", Every: 5 * time.Millisecond}, func(context.Context) error {
+		_runs.Add(1)
+		return nil
+	})
+
+	_scheduler.Pause("tick", true)
+	time.Sleep(10 * time.Millisecond) /// run started before the pause
+	_paused := _runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	if _runs.Load() != _paused {
+		t.Fatal("paused job is run by the schedule")
+	}
+
+	/// paused job can be triggered
+	_scheduler.Trigger("tick")
+	wait_for(t, "triggered run", func() bool { return _runs.Load() == _paused+1 })
+
+	_scheduler.Pause("tick", false)
+	wait_for(t, "resumed runs", func() bool { return _runs.Load() >= _paused+3 })
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+
+	_cases := []struct {
+		name   string
+		cancel func(pScheduler *Scheduler, pOwner *owner, pCancel_Owner context.CancelFunc)
+	}{
+		{name: "unschedule", cancel: func(pScheduler *Scheduler, pOwner *owner, _ context.CancelFunc) {
+			pScheduler.Unschedule(pOwner, "wait")
+		}},
+		{name: "remove owner", cancel: func(pScheduler *Scheduler, pOwner *owner, _ context.CancelFunc) { pScheduler.Remove_Owner(pOwner) }},
+		{name: "owner stopped", cancel: func(_ *Scheduler, _ *owner, pCancel_Owner context.CancelFunc) { pCancel_Owner() }},
+		{name: "close", cancel: func(pScheduler *Scheduler, _ *owner, _ context.CancelFunc) { pScheduler.Close() }},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			/// context of each owner instance, cancelled when the instance is stopped
+			_owner_ctx, _cancel_owner := context.WithCancel(context.Background())
+			defer _cancel_owner()
+			_other := new_owner("b")
+
+			_scheduler := New(func(pOwner IOwner) context.Context {
+				if pOwner == _other {
+					return context.Background()
+				}
+				return _owner_ctx
+			})
+			defer _scheduler.Close()
+
+			_owner := new_owner("a")
+			_entered := make(chan string, 2)
+			_cancelled := make(chan string, 2)
+			for _, _instance := range []*owner{_owner, _other} {
+				_scheduler.Schedule(_instance, Job{Name: "wait", Delay: time.Hour}, func(pCTX context.Context) error {
+					_entered <- _instance.name
+					select {
+					case <-pCTX.Done():
+						_cancelled <- _instance.name
+					case <-time.After(200 * time.Millisecond):
+					}
+					return nil
+				})
+			}
+
+			_scheduler.Trigger("wait")
+			<-_entered
+			<-_entered
+
+			_case.cancel(_scheduler, _owner, _cancel_owner)
+
+			select {
+			case _name := <-_cancelled:
+				if _name != "a" && _case.name == "owner stopped" {
+					t.Fatalf("run of %s is cancelled", _name)
+				}
+			case <-time.After(100 * time.Millisecond):
+				t.Fatal("run is not cancelled")
+			}
+
+			/// run of the other instance is cancelled with the job only
+			if _case.name == "owner stopped" {
+				select {
+				case _name := <-_cancelled:
+					t.Fatalf("run of %s is cancelled with the other instance", _name)
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+// servicereg package provides the request/reply service registry shared by the units of the framework instance
+//
+// A provider unit registers named handlers from each of its pool instances. Other units call a service by name
+// with a context, timeout and JSON payload. Calls are balanced over the started instances of the provider,
+// so a service scales with the pool size of its unit.
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   servicereg
+
+	Objective     :   Named request/reply handlers of the units, balanced over the pool instances of the provider
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package servicereg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DEFAULT_CALL_TIMEOUT define the time for a call, if timeout is not given
+const DEFAULT_CALL_TIMEOUT = 30 * time.Second
+
+// Handler handles a call of the service. Returned reply is sent to the caller as JSON.
+// Context is done when the caller gives up
+type Handler func(pCTX context.Context, pRequest json.RawMessage) (any, error)
+
+// IProvider is the unit instance providing the services. Calls are sent only to the started instances
+type IProvider interface {
+	IsStarted() bool
+}
+
+// IServices defines the service registry functions of the framework instance.
+// Units check the framework instance for it:
+//
+//	if _services, _ok := pApp.(servicereg.IServices); _ok { ... }
+type IServices interface {
+	Register_Service(pProvider IProvider, pName string, pHandler Handler) error
+	Unregister_Service(pProvider IProvider, pName string)
+	Call_Service(pCTX context.Context, pName string, pRequest any, pReply any, pTimeout time.Duration) error
+}
+
+// endpoint is a handler of a service registered by a provider instance
+type endpoint struct {
+	provider IProvider
+	handler  Handler
+}
+
+// service holds the endpoints of a service and the next endpoint to call
+type service struct {
+	endpoints []*endpoint
+	next      int
+}
+
+// Registry holds the services by name
+type Registry struct {
+	lock     *sync.Mutex
+	services map[string]*service
+}
+
+// New creates a new registry
+func New() *Registry {
+	return &Registry{lock: &sync.Mutex{}, services: make(map[string]*service)}
+}
+
+// Register registers the handler of the service for the provider instance.
+// Handler of the same provider replaces the previous one
+func (r *Registry) Register(pProvider IProvider, pName string, pHandler Handler) error {
+
+	if pProvider == nil {
+		return errors.New("provider of service " + pName + " is not given")
+	}
+	if len(pName) == 0 {
+		return errors.New("service name is not given")
+	}
+	if pHandler == nil {
+		return errors.New("handler of service " + pName + " is not given")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_service, _found := r.services[pName]
+	if !_found {
+		_service = &service{}
+		r.services[pName] = _service
+	}
+
+	/// endpoints are replaced, not changed, as the callers use them without the lock
+	for _index, _endpoint := range _service.endpoints {
+		if _endpoint.provider == pProvider {
+			_service.endpoints[_index] = &endpoint{provider: pProvider, handler: pHandler}
+			return nil
+		}
+	}
+
+	_service.endpoints = append(_service.endpoints, &endpoint{provider: pProvider, handler: pHandler})
+	return nil
+}
+
+// Unregister removes the handler of the service registered by the provider instance
+func (r *Registry) Unregister(pProvider IProvider, pName string) {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.remove(pProvider, pName)
+}
+
+// Remove_Provider removes all the handlers registered by the provider instance.
+// Called by the framework when the instance is removed from the unit pool. Returns the number of removed handlers
+func (r *Registry) Remove_Provider(pProvider IProvider) int {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_removed := 0
+	for _name := range r.services {
+		if r.remove(pProvider, _name) {
+			_removed++
+		}
+	}
+	return _removed
+}
+
+// remove removes the endpoint of the provider from the service. Called with the lock
+func (r *Registry) remove(pProvider IProvider, pName string) bool {
+
+	_service, _found := r.services[pName]
+	if !_found {
+		return false
+	}
+
+	_index := slices.IndexFunc(_service.endpoints, func(pEndpoint *endpoint) bool {
+		return pEndpoint.provider == pProvider
+	})
+	if _index < 0 {
+		return false
+	}
+
+	_service.endpoints = slices.Delete(_service.endpoints, _index, _index+1)
+	if len(_service.endpoints) == 0 {
+		delete(r.services, pName)
+	}
+	return true
+}
+
+// pick returns the next endpoint of the service with a started provider
+func (r *Registry) pick(pName string) (*endpoint, error) {
+
+	r.lock.Lock()
+	_service, _found := r.services[pName]
+	if !_found {
+		r.lock.Unlock()
+		return nil, errors.New("service " + pName + " has no provider")
+	}
+
+	_endpoints := slices.Clone(_service.endpoints)
+	_start := _service.next % len(_endpoints)
+	_service.next = (_start + 1) % len(_endpoints)
+	r.lock.Unlock()
+
+	/// provider state is checked without the lock. unit process answers over RPC
+	for _tried := 0; _tried < len(_endpoints); _tried++ {
+		if _endpoint := _endpoints[(_start+_tried)%len(_endpoints)]; _endpoint.provider.IsStarted() {
+			return _endpoint, nil
+		}
+	}
+	return nil, errors.New("provider of service " + pName + " is stopped")
+}
+
+// Call calls the service with the request and decodes the reply into pReply. pReply may be nil.
+// Request is marshalled to JSON, unless it is []byte or json.RawMessage of JSON.
+// DEFAULT_CALL_TIMEOUT is used if pTimeout <= 0
+func (r *Registry) Call(pCTX context.Context, pName string, pRequest any, pReply any, pTimeout time.Duration) error {
+
+	if pCTX == nil {
+		pCTX = context.Background()
+	}
+	if pTimeout <= 0 {
+		pTimeout = DEFAULT_CALL_TIMEOUT
+	}
+
+	_request, _err := to_json(pRequest)
+	if _err != nil {
+		return errors.New("request of service " + pName + " is not valid. " + _err.Error())
+	}
+
+	_endpoint, _err := r.pick(pName)
+	if _err != nil {
+		return _err
+	}
+
+	_ctx, _cancel := context.WithTimeout(pCTX, pTimeout)
+	defer _cancel()
+
+	_done := make(chan error, 1)
+	var _reply []byte
+
+	/// caller returns on timeout, even if the handler does not check the context
+	go func() {
+		defer func() {
+			if _r := recover(); _r != nil {
+				_done <- fmt.Errorf("service %s panicked. %v", pName, _r)
+			}
+		}()
+
+		_result, _err := _endpoint.handler(_ctx, _request)
+		if _err == nil {
+			if _reply, _err = to_json(_result); _err != nil {
+				_err = errors.New("reply of service " + pName + " is not valid. " + _err.Error())
+			}
+		}
+		_done <- _err
+	}()
+
+	select {
+	case _err = <-_done:
+	case <-_ctx.Done():
+		return errors.New("service " + pName + " did not reply. " + _ctx.Err().Error())
+	}
+
+	if _err != nil {
+		return _err
+	}
+	if pReply != nil && len(_reply) > 0 {
+		if _err = json.Unmarshal(_reply, pReply); _err != nil {
+			return errors.New("reply of service " + pName + " can not be decoded. " + _err.Error())
+		}
+	}
+	return nil
+}
+
+// Services returns the registered services with the number of started provider instances
+func (r *Registry) Services() map[string]int {
+
+	r.lock.Lock()
+	_providers := make(map[string][]IProvider, len(r.services))
+	for _name, _service := range r.services {
+		for _, _endpoint := range _service.endpoints {
+			_providers[_name] = append(_providers[_name], _endpoint.provider)
+		}
+	}
+	r.lock.Unlock()
+
+	_services := make(map[string]int, len(_providers))
+	for _name, _list := range _providers {
+		_services[_name] = 0
+		for _, _provider := range _list {
+			if _provider.IsStarted() {
+				_services[_name]++
+			}
+		}
+	}
+	return _services
+}
+
+// to_json returns the JSON of the value
+func to_json(pValue any) (json.RawMessage, error) {
+
+	switch _value := pValue.(type) {
+	case nil:
+		return nil, nil
+	case json.RawMessage:
+		if !json.Valid(_value) {
+			return nil, errors.New("value is not valid JSON")
+		}
+		return _value, nil
+	case []byte:
+		if !json.Valid(_value) {
+			return nil, errors.New("value is not valid JSON")
+		}
+		return _value, nil
+	}
+	return json.Marshal(pValue)
+}
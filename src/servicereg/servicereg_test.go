@@ -0,0 +1,219 @@
+package servicereg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// provider is a provider instance of the tests
+type provider struct {
+	name    string
+	started atomic.Bool
+}
+
+func (p *provider) IsStarted() bool { return p.started.Load() }
+
+// new_providers returns started providers of the given names
+func new_providers(pNames ...string) []*provider {
+
+	_providers := make([]*provider, 0, len(pNames))
+	for _, _name := range pNames {
+		_provider := &provider{name: _name}
+		_provider.started.Store(true)
+		_providers = append(_providers, _provider)
+	}
+	return _providers
+}
+
+// register_echo registers a handler replying with the provider name for each provider
+func register_echo(t *testing.T, pRegistry *Registry, pName string, pProviders []*provider) {
+
+	for _, _provider := range pProviders {
+		_name := _provider.name
+		if _err := pRegistry.Register(_provider, pName, func(context.Context, json.RawMessage) (any, error) {
+			return _name, nil
+		}); _err != nil {
+			t.Fatal(_err)
+		}
+	}
+}
+
+// call_sequence calls the service the given times & returns the replies
+func call_sequence(t *testing.T, pRegistry *Registry, pName string, pCount int) string {
+
+	_replies := make([]string, 0, pCount)
+	for _index := 0; _index < pCount; _index++ {
+		_reply := ""
+		if _err := pRegistry.Call(context.Background(), pName, nil, &_reply, time.Second); _err != nil {
+			t.Fatal(_err)
+		}
+		_replies = append(_replies, _reply)
+	}
+	return strings.Join(_replies, ",")
+}
+
+func TestRegistry_Round_Robin(t *testing.T) {
+
+	_cases := []struct {
+		name     string
+		stopped  []int
+		expected string
+	}{
+		{name: "all started", expected: "a,b,c,a,b,c"},
+		{name: "one stopped", stopped: []int{1}, expected: "a,c,c,a,c,c"},
+		{name: "two stopped", stopped: []int{0, 2}, expected: "b,b,b,b,b,b"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_registry := New()
+			_providers := new_providers("a", "b", "c")
+			register_echo(t, _registry, "orders", _providers)
+
+			for _, _index := range _case.stopped {
+				_providers[_index].started.Store(false)
+			}
+
+			if _replies := call_sequence(t, _registry, "orders", 6); _replies != _case.expected {
+				t.Fatalf("expected %s, got %s", _case.expected, _replies)
+			}
+			if _started := _registry.Services()["orders"]; _started != 3-len(_case.stopped) {
+				t.Fatalf("unexpected started providers %d", _started)
+			}
+		})
+	}
+}
+
+func TestRegistry_Concurrent_Balance(t *testing.T) {
+
+	_registry := New()
+	register_echo(t, _registry, "orders", new_providers("a", "b", "c", "d"))
+
+	_counts := make(map[string]int)
+	_lock := &sync.Mutex{}
+	_wait := &sync.WaitGroup{}
+
+	for _index := 0; _index < 400; _index++ {
+		_wait.Add(1)
+		go func() {
+			defer _wait.Done()
+			_reply := ""
+			if _err := _registry.Call(nil, "orders", nil, &_reply, time.Second); _err != nil {
+				t.Error(_err)
+				return
+			}
+			_lock.Lock()
+			_counts[_reply]++
+			_lock.Unlock()
+		}()
+	}
+	_wait.Wait()
+
+	for _, _name := range []string{"a", "b", "c", "d"} {
+		if _counts[_name] != 100 {
+			t.Fatalf("calls are not balanced %v", _counts)
+		}
+	}
+}
+
+func TestRegistry_Providers(t *testing.T) {
+
+	_registry := New()
+	_providers := new_providers("a", "b")
+	register_echo(t, _registry, "orders", _providers)
+	register_echo(t, _registry, "invoices", _providers[:1])
+
+	/// handler of the same provider is replaced
+	_registry.Register(_providers[0], "orders", func(context.Context, json.RawMessage) (any, error) { return "a2", nil })
+	if _replies := call_sequence(t, _registry, "orders", 2); _replies != "a2,b" {
+		t.Fatalf("handler is not replaced %s", _replies)
+	}
+
+	_registry.Unregister(_providers[1], "orders")
+	if _replies := call_sequence(t, _registry, "orders", 2); _replies != "a2,a2" {
+		t.Fatalf("handler is not unregistered %s", _replies)
+	}
+
+	if _removed := _registry.Remove_Provider(_providers[0]); _removed != 2 {
+		t.Fatalf("expected 2 removed handlers, got %d", _removed)
+	}
+	if _services := _registry.Services(); len(_services) != 0 {
+		t.Fatalf("services without providers are kept %v", _services)
+	}
+
+	_providers[0].started.Store(false)
+	register_echo(t, _registry, "orders", _providers[:1])
+	_err := _registry.Call(nil, "orders", nil, nil, time.Second)
+	if _err == nil || _err.Error() != "provider of service orders is stopped" {
+		t.Fatalf("expected stopped provider, got %v", _err)
+	}
+
+	_handler := func(context.Context, json.RawMessage) (any, error) { return nil, nil }
+	for _, _err := range []error{_registry.Register(nil, "orders", _handler), _registry.Register(_providers[1], "", _handler),
+		_registry.Register(_providers[1], "orders", nil)} {
+		if _err == nil {
+			t.Fatal("invalid registration is accepted")
+		}
+	}
+}
+
+func TestRegistry_Call(t *testing.T) {
+
+	_cases := []struct {
+		name    string
+		request any
+		handler Handler
+		timeout time.Duration
+		reply   string
+		fails   string
+	}{
+		{name: "request & reply", request: map[string]int{"id": 7},
+			handler: func(pCTX context.Context, pRequest json.RawMessage) (any, error) { return pRequest, nil }, reply: `{"id":7}`},
+		{name: "no service", fails: "service orders has no provider"},
+		{name: "invalid request", request: []byte("{"), fails: "request of service orders is not valid"},
+		{name: "handler error", handler: func(context.Context, json.RawMessage) (any, error) { return nil, errors.New("out of stock") },
+			fails: "out of stock"},
+		{name: "panic", handler: func(context.Context, json.RawMessage) (any, error) { panic("nil order") },
+			fails: "service orders panicked. nil order"},
+		{name: "invalid reply", handler: func(context.Context, json.RawMessage) (any, error) { return func() {}, nil },
+			fails: "reply of service orders is not valid"},
+		{name: "reply not decoded", handler: func(context.Context, json.RawMessage) (any, error) { return 7, nil },
+			fails: "reply of service orders can not be decoded"},
+		{name: "timeout", timeout: 20 * time.Millisecond,
+			handler: func(context.Context, json.RawMessage) (any, error) { time.Sleep(time.Second); return nil, nil },
+			fails:   "service orders did not reply. context deadline exceeded"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_registry := New()
+			if _case.handler != nil {
+				_registry.Register(new_providers("a")[0], "orders", _case.handler)
+			}
+
+			_reply := map[string]int{}
+			_err := _registry.Call(context.Background(), "orders", _case.request, &_reply, _case.timeout)
+
+			if len(_case.fails) > 0 {
+				if _err == nil || !strings.HasPrefix(_err.Error(), _case.fails) {
+					t.Fatalf("expected %q, got %v", _case.fails, _err)
+				}
+				return
+			}
+			if _err != nil {
+				t.Fatal(_err)
+			}
+			if _data, _ := json.Marshal(_reply); string(_data) != _case.reply {
+				t.Fatalf("unexpected reply %s", _data)
+			}
+		})
+	}
+}
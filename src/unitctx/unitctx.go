@@ -0,0 +1,147 @@
+// unitctx package provides the context of each unit instance of the framework instance
+//
+// The context of an unit instance is derived from the application context and cancelled when the instance is stopped
+// by Unit_Stop, a reload or a scale down, so that the unit routines can stop with "this unit only".
+// It carries the unit name, the pool index and the request correlation id for the log entries.
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   unitctx
+
+	Objective     :   Per unit instance contexts with the unit name, pool index & correlation id
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package unitctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+
+	apptypes "agnione/v1/src/appfm/types"
+)
+
+// NO_POOL_INDEX define the pool index of a context without it. eg: unit process of an isolated unit
+const NO_POOL_INDEX = -1
+
+// context_key is the type of the keys of the values set by the package
+type context_key int
+
+const (
+	key_unit context_key = iota
+	key_pool_index
+	key_correlation
+)
+
+// IOwner is the unit instance of the context
+type IOwner interface {
+	IsStarted() bool
+}
+
+// IUnitContext defines the unit context functions of the framework instance.
+// Units check the framework instance for it:
+//
+//	if _contexts, _ok := pApp.(unitctx.IUnitContext); _ok { _ctx := _contexts.Unit_Context(u) ... }
+type IUnitContext interface {
+	Unit_Context(pOwner IOwner) context.Context
+	Write2Log_Context(pCTX context.Context, pEntry string, pLog_Level apptypes.LogLevel)
+}
+
+// New returns the context of an unit instance derived from the parent context
+func New(pParent context.Context, pUname string, pPool_Index int) (context.Context, context.CancelFunc) {
+
+	if pParent == nil {
+		pParent = context.Background()
+	}
+
+	_ctx := context.WithValue(pParent, key_unit, pUname)
+	_ctx = context.WithValue(_ctx, key_pool_index, pPool_Index)
+	return context.WithCancel(_ctx)
+}
+
+// Unit_Name returns the unit name of the context. Empty if it is not an unit context
+func Unit_Name(pCTX context.Context) string {
+
+	_uname, _ := pCTX.Value(key_unit).(string)
+	return _uname
+}
+
+// Pool_Index returns the pool index of the unit instance of the context. NO_POOL_INDEX if not known
+func Pool_Index(pCTX context.Context) int {
+
+	if _index, _ok := pCTX.Value(key_pool_index).(int); _ok {
+		return _index
+	}
+	return NO_POOL_INDEX
+}
+
+// With_Correlation returns a context with the correlation id of a request. A new id is created if pID is empty
+func With_Correlation(pCTX context.Context, pID string) context.Context {
+
+	if len(pID) == 0 {
+		pID = New_Correlation_ID()
+	}
+	return context.WithValue(pCTX, key_correlation, pID)
+}
+
+// Correlation_ID returns the correlation id of the context. Empty if not set
+func Correlation_ID(pCTX context.Context) string {
+
+	_id, _ := pCTX.Value(key_correlation).(string)
+	return _id
+}
+
+// New_Correlation_ID returns a random correlation id
+func New_Correlation_ID() string {
+
+	_id := make([]byte, 8)
+	rand.Read(_id)
+	return hex.EncodeToString(_id)
+}
+
+// Log_Prefix returns the prefix of the log entries written with the context. eg: [orders#1 9f2c41d07a3be815]
+func Log_Prefix(pCTX context.Context) string {
+
+	if pCTX == nil {
+		return ""
+	}
+
+	_prefix := Unit_Name(pCTX)
+	if _index := Pool_Index(pCTX); len(_prefix) > 0 && _index != NO_POOL_INDEX {
+		_prefix += "#" + strconv.Itoa(_index)
+	}
+	if _id := Correlation_ID(pCTX); len(_id) > 0 {
+		if len(_prefix) > 0 {
+			_prefix += " "
+		}
+		_prefix += _id
+	}
+
+	if len(_prefix) == 0 {
+		return ""
+	}
+	return "[" + _prefix + "] "
+}
@@ -0,0 +1,89 @@
+package unitctx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+
+	_parent, _stop := context.WithCancel(context.Background())
+	defer _stop()
+
+	_ctx, _cancel := New(_parent, "orders", 2)
+	if Unit_Name(_ctx) != "orders" || Pool_Index(_ctx) != 2 {
+		t.Fatalf("unexpected unit %q #%d", Unit_Name(_ctx), Pool_Index(_ctx))
+	}
+
+	/// instance context is cancelled alone
+	_cancel()
+	if _ctx.Err() == nil || _parent.Err() != nil {
+		t.Fatal("only the instance context should be cancelled")
+	}
+
+	/// instance context is cancelled with the parent
+	_ctx, _cancel = New(_parent, "orders", 3)
+	defer _cancel()
+	_stop()
+	if _ctx.Err() == nil {
+		t.Fatal("instance context is not cancelled with the parent")
+	}
+
+	_ctx, _cancel = New(nil, "billing", NO_POOL_INDEX)
+	defer _cancel()
+	if _ctx.Err() != nil || Unit_Name(_ctx) != "billing" {
+		t.Fatal("context without a parent is not created")
+	}
+}
+
+func TestValues_Default(t *testing.T) {
+
+	_ctx := context.Background()
+	if Unit_Name(_ctx) != "" || Pool_Index(_ctx) != NO_POOL_INDEX || Correlation_ID(_ctx) != "" {
+		t.Fatal("unexpected values of a context without them")
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+
+	_ctx := With_Correlation(context.Background(), "req-1")
+	if Correlation_ID(_ctx) != "req-1" {
+		t.Fatalf("unexpected correlation id %q", Correlation_ID(_ctx))
+	}
+
+	_first := Correlation_ID(With_Correlation(context.Background(), ""))
+	_second := Correlation_ID(With_Correlation(context.Background(), ""))
+	if len(_first) != 16 || _first == _second {
+		t.Fatalf("unexpected new correlation ids %q, %q", _first, _second)
+	}
+}
+
+func TestLog_Prefix(t *testing.T) {
+
+	_unit, _cancel := New(context.Background(), "orders", 1)
+	defer _cancel()
+	_process, _cancel_process := New(context.Background(), "orders", NO_POOL_INDEX)
+	defer _cancel_process()
+
+	_cases := []struct {
+		name   string
+		ctx    context.Context
+		prefix string
+	}{
+		{name: "nil", ctx: nil, prefix: ""},
+		{name: "no values", ctx: context.Background(), prefix: ""},
+		{name: "unit", ctx: _unit, prefix: "[orders#1] "},
+		{name: "no pool index", ctx: _process, prefix: "[orders] "},
+		{name: "unit & correlation", ctx: With_Correlation(_unit, "9f2c"), prefix: "[orders#1 9f2c] "},
+		{name: "correlation only", ctx: With_Correlation(context.Background(), "9f2c"), prefix: "[9f2c] "},
+		{name: "pool index without unit", ctx: context.WithValue(context.Background(), key_pool_index, 4), prefix: ""},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			if _prefix := Log_Prefix(_case.ctx); _prefix != _case.prefix {
+				t.Fatalf("expected %q, got %q", _case.prefix, _prefix)
+			}
+		})
+	}
+}
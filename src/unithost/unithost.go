@@ -0,0 +1,335 @@
+// unithost package provides the RPC channel between the framework and the units running in a child process
+//
+// Unit with "isolation": "process" in app.config is run by a child process of the framework binary.
+// Child dials the unix socket of the framework twice. On the control connection child serves the unit (Unit service),
+// on the host connection framework serves the calls the unit makes to the framework (Host service).
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   unithost
+
+	Objective     :   Define the RPC protocol of the out-of-process units and serve the unit in the child process
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package unithost
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"time"
+
+	iappunit "agnione/v1/src/aau/iappunit"
+	iappfw "agnione/v1/src/appfm/iappfw"
+	apptypes "agnione/v1/src/appfm/types"
+	lib "agnione/v1/src/lib"
+)
+
+const (
+	UNIT_SERVICE = "Unit" /// served by the child on the control connection
+	HOST_SERVICE = "Host" /// served by the framework on the host connection
+
+	ROLE_CONTROL byte = 'C' /// first byte of the control connection
+	ROLE_HOST    byte = 'H' /// first byte of the host connection
+)
+
+// DIAL_TIMEOUT define the time to connect to the framework
+const DIAL_TIMEOUT = 10 * time.Second
+
+// Empty is the argument & reply of the calls without data
+type Empty struct{}
+
+// InitArgs holds the IAppUnit.Initialize arguments
+type InitArgs struct {
+	Index       int
+	Name        string
+	Path        string
+	Config_File string
+}
+
+// LogArgs holds a log entry of the unit
+type LogArgs struct {
+	Entry string
+	Level apptypes.LogLevel
+}
+
+// UnitService serves the unit instance of the child process to the framework.
+// A panic in the unit ends the child process, which is restarted by the framework.
+type UnitService struct {
+	app  iappfw.IAgniApp
+	unit iappunit.IAppUnit
+	done chan struct{}
+}
+
+// NewUnitService creates the service of the given unit. pApp is given to the unit on Initialize
+func NewUnitService(pApp iappfw.IAgniApp, pUnit iappunit.IAppUnit) *UnitService {
+	return &UnitService{app: pApp, unit: pUnit, done: make(chan struct{})}
+}
+
+// Done is closed when the unit is deinitialized by the framework
+func (s *UnitService) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *UnitService) Initialize(pArgs InitArgs, pReply *bool) error {
+	_ok, _err := s.unit.Initialize(s.app, pArgs.Index, pArgs.Name, pArgs.Path, pArgs.Config_File)
+	*pReply = _ok
+	return _err
+}
+
+func (s *UnitService) Start(pArgs Empty, pReply *bool) error {
+	_ok, _err := s.unit.Start()
+	*pReply = _ok
+	return _err
+}
+
+func (s *UnitService) Stop(pArgs Empty, pReply *Empty) error {
+	s.unit.Stop()
+	return nil
+}
+
+func (s *UnitService) IsStarted(pArgs Empty, pReply *bool) error {
+	*pReply = s.unit.IsStarted()
+	return nil
+}
+
+func (s *UnitService) Info(pArgs Empty, pReply *lib.BuildInfo) error {
+	if _info := s.unit.Info(); _info != nil {
+		*pReply = *_info
+	}
+	return nil
+}
+
+func (s *UnitService) Status(pArgs Empty, pReply *apptypes.AppUnitInfo) error {
+	if _status := s.unit.Status(); _status != nil {
+		*pReply = *_status
+	}
+	return nil
+}
+
+func (s *UnitService) Deinitialize(pArgs Empty, pReply *Empty) error {
+	s.unit.Deinitialize()
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	return nil
+}
+
+// IHost defines the framework functions that the unit process sends to the framework
+type IHost interface {
+	Write2Log(pEntry string, pLog_Level apptypes.LogLevel)
+	Add_Request_HandleCount()
+	Add_Request_Failed_Count()
+	Send_Monitor_Message(pMessage []byte)
+}
+
+// HostService serves the framework functions used by the unit of the child process
+type HostService struct {
+	app IHost
+}
+
+// NewHostService creates the service of the framework instance
+func NewHostService(pApp IHost) *HostService {
+	return &HostService{app: pApp}
+}
+
+func (s *HostService) Log(pArgs LogArgs, pReply *Empty) error {
+	s.app.Write2Log(pArgs.Entry, pArgs.Level)
+	return nil
+}
+
+func (s *HostService) Request_Handled(pArgs Empty, pReply *Empty) error {
+	s.app.Add_Request_HandleCount()
+	return nil
+}
+
+func (s *HostService) Request_Failed(pArgs Empty, pReply *Empty) error {
+	s.app.Add_Request_Failed_Count()
+	return nil
+}
+
+func (s *HostService) Monitor_Message(pArgs []byte, pReply *Empty) error {
+	s.app.Send_Monitor_Message(pArgs)
+	return nil
+}
+
+// HostClient forwards the framework calls of the child process to the framework.
+// Calls are sent without waiting for the reply, as the in-process calls of the framework
+type HostClient struct {
+	client *rpc.Client
+}
+
+func (h *HostClient) send(pMethod string, pArgs any) {
+	h.client.Go(HOST_SERVICE+"."+pMethod, pArgs, &Empty{}, nil)
+}
+
+// Log forwards the log entry to the framework log
+func (h *HostClient) Log(pEntry string, pLevel apptypes.LogLevel) {
+	h.send("Log", LogArgs{Entry: pEntry, Level: pLevel})
+}
+
+// Request_Handled adds 1 to the handled request count of the framework
+func (h *HostClient) Request_Handled() {
+	h.send("Request_Handled", Empty{})
+}
+
+// Request_Failed adds 1 to the failed request count of the framework
+func (h *HostClient) Request_Failed() {
+	h.send("Request_Failed", Empty{})
+}
+
+// Monitor_Message broadcasts the message via web socket monitoring of the framework
+func (h *HostClient) Monitor_Message(pMessage []byte) {
+	h.send("Monitor_Message", pMessage)
+}
+
+// Close closes the host connection
+func (h *HostClient) Close() error {
+	return h.client.Close()
+}
+
+// UnitClient calls the unit of the child process.
+// Calls return an error if the child process exits before the reply.
+type UnitClient struct {
+	client *rpc.Client
+}
+
+// Call calls the UnitService method. pTimeout 0 waits for the reply until the connection is closed
+func (u *UnitClient) Call(pMethod string, pArgs any, pReply any, pTimeout time.Duration) error {
+
+	if pTimeout <= 0 {
+		return u.client.Call(UNIT_SERVICE+"."+pMethod, pArgs, pReply)
+	}
+
+	_call := u.client.Go(UNIT_SERVICE+"."+pMethod, pArgs, pReply, make(chan *rpc.Call, 1))
+
+	select {
+	case <-_call.Done:
+		return _call.Error
+	case <-time.After(pTimeout):
+		return errors.New("unit process did not reply to " + pMethod + " within " + pTimeout.String())
+	}
+}
+
+// Close closes the control connection. Child process exits when the connection is closed
+func (u *UnitClient) Close() error {
+	return u.client.Close()
+}
+
+// dial connects to the framework socket with the given role
+func dial(pSocket string, pRole byte) (net.Conn, error) {
+
+	_conn, _err := net.DialTimeout("unix", pSocket, DIAL_TIMEOUT)
+	if _err != nil {
+		return nil, _err
+	}
+
+	if _, _err = _conn.Write([]byte{pRole}); _err != nil {
+		_conn.Close()
+		return nil, _err
+	}
+	return _conn, nil
+}
+
+// Dial connects the child to the framework socket.
+// Returns the control connection to serve the UnitService on and the client of the Host service
+func Dial(pSocket string) (net.Conn, *HostClient, error) {
+
+	_control, _err := dial(pSocket, ROLE_CONTROL)
+	if _err != nil {
+		return nil, nil, errors.New("failed to connect to the framework. " + _err.Error())
+	}
+
+	_host, _err := dial(pSocket, ROLE_HOST)
+	if _err != nil {
+		_control.Close()
+		return nil, nil, errors.New("failed to connect to the framework. " + _err.Error())
+	}
+
+	return _control, &HostClient{client: rpc.NewClient(_host)}, nil
+}
+
+// Serve serves the unit on the control connection. Returns when the framework closes the connection
+func Serve(pControl net.Conn, pService *UnitService) error {
+
+	_server := rpc.NewServer()
+	if _err := _server.RegisterName(UNIT_SERVICE, pService); _err != nil {
+		return _err
+	}
+
+	_server.ServeConn(pControl)
+	return nil
+}
+
+// Accept accepts the control & host connections of a child on the listener within the timeout.
+// Host service is served on the host connection until the child process exits. Returns the client of the UnitService
+func Accept(pListener *net.UnixListener, pTimeout time.Duration, pHost *HostService) (*UnitClient, error) {
+
+	pListener.SetDeadline(time.Now().Add(pTimeout))
+
+	var _control, _host net.Conn
+
+	for _control == nil || _host == nil {
+		_conn, _err := pListener.Accept()
+		if _err != nil {
+			if _control != nil {
+				_control.Close()
+			}
+			if _host != nil {
+				_host.Close()
+			}
+			return nil, errors.New("unit process did not connect. " + _err.Error())
+		}
+
+		_role := make([]byte, 1)
+		_conn.SetReadDeadline(time.Now().Add(pTimeout))
+		if _, _err = _conn.Read(_role); _err != nil {
+			_conn.Close()
+			continue
+		}
+		_conn.SetReadDeadline(time.Time{})
+
+		switch {
+		case _role[0] == ROLE_CONTROL && _control == nil:
+			_control = _conn
+		case _role[0] == ROLE_HOST && _host == nil:
+			_host = _conn
+		default:
+			_conn.Close()
+		}
+	}
+
+	_server := rpc.NewServer()
+	if _err := _server.RegisterName(HOST_SERVICE, pHost); _err != nil {
+		_control.Close()
+		_host.Close()
+		return nil, _err
+	}
+	go _server.ServeConn(_host)
+
+	return &UnitClient{client: rpc.NewClient(_control)}, nil
+}
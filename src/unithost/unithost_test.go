@@ -0,0 +1,184 @@
+package unithost
+
+import (
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	iappfw "agnione/v1/src/appfm/iappfw"
+	apptypes "agnione/v1/src/appfm/types"
+	lib "agnione/v1/src/lib"
+)
+
+// test_unit records the calls of the framework
+type test_unit struct {
+	name    string
+	started bool
+}
+
+func (u *test_unit) New() any { return &test_unit{} }
+
+func (u *test_unit) Initialize(pApp iappfw.IAgniApp, pIndex int, pName string, pPath string, pConfig string) (bool, error) {
+	u.name = pName
+	return true, nil
+}
+
+func (u *test_unit) Deinitialize()        {}
+func (u *test_unit) Start() (bool, error) { u.started = true; return true, nil }
+func (u *test_unit) Stop()                { u.started = false }
+func (u *test_unit) IsStarted() bool      { return u.started }
+func (u *test_unit) Info() *lib.BuildInfo { return &lib.BuildInfo{Version: "1.0.0"} }
+
+func (u *test_unit) Status() *apptypes.AppUnitInfo {
+	return &apptypes.AppUnitInfo{Req_Handled: 5}
+}
+
+// test_host counts the framework calls of the unit process
+type test_host struct {
+	lock     sync.Mutex
+	entries  []string
+	handled  int
+	failed   int
+	messages int
+}
+
+func (h *test_host) Write2Log(pEntry string, pLog_Level apptypes.LogLevel) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.entries = append(h.entries, pEntry)
+}
+
+func (h *test_host) Add_Request_HandleCount() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.handled++
+}
+
+func (h *test_host) Add_Request_Failed_Count() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.failed++
+}
+
+func (h *test_host) Send_Monitor_Message(pMessage []byte) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.messages++
+}
+
+func (h *test_host) counts() (int, int, int, int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.entries), h.handled, h.failed, h.messages
+}
+
+func TestUnit_Round_Trip(t *testing.T) {
+
+	_socket := filepath.Join(t.TempDir(), "unit.sock")
+	_listener, _err := net.ListenUnix("unix", &net.UnixAddr{Name: _socket, Net: "unix"})
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	defer _listener.Close()
+
+	_unit := &test_unit{}
+	_service := NewUnitService(nil, _unit)
+
+	/// child side
+	go func() {
+		_control, _host_client, _err := Dial(_socket)
+		if _err != nil {
+			return
+		}
+		defer _host_client.Close()
+
+		_host_client.Log("started", apptypes.LogLevel(0))
+		_host_client.Request_Handled()
+		_host_client.Request_Handled()
+		_host_client.Request_Failed()
+		_host_client.Monitor_Message([]byte("{}"))
+
+		Serve(_control, _service)
+	}()
+
+	_host := &test_host{}
+	_client, _err := Accept(_listener, 5*time.Second, NewHostService(_host))
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	defer _client.Close()
+
+	var _ok bool
+	if _err = _client.Call("Initialize", InitArgs{Index: 1, Name: "api"}, &_ok, time.Second); _err != nil || !_ok || _unit.name != "api" {
+		t.Fatalf("unexpected initialize %v %v %q", _ok, _err, _unit.name)
+	}
+	if _err = _client.Call("Start", Empty{}, &_ok, time.Second); _err != nil || !_ok {
+		t.Fatalf("unexpected start %v %v", _ok, _err)
+	}
+	if _err = _client.Call("IsStarted", Empty{}, &_ok, 0); _err != nil || !_ok {
+		t.Fatalf("expected the unit started, got %v %v", _ok, _err)
+	}
+
+	var _info lib.BuildInfo
+	if _err = _client.Call("Info", Empty{}, &_info, time.Second); _err != nil || _info.Version != "1.0.0" {
+		t.Fatalf("unexpected info %+v %v", _info, _err)
+	}
+
+	var _status apptypes.AppUnitInfo
+	if _err = _client.Call("Status", Empty{}, &_status, time.Second); _err != nil || _status.Req_Handled != 5 {
+		t.Fatalf("unexpected status %+v %v", _status, _err)
+	}
+
+	if _err = _client.Call("Deinitialize", Empty{}, &Empty{}, time.Second); _err != nil {
+		t.Fatal(_err)
+	}
+	select {
+	case <-_service.Done():
+	case <-time.After(time.Second):
+		t.Fatal("service is not done after deinitialize")
+	}
+
+	/// host calls are sent without waiting for the reply
+	_deadline := time.Now().Add(5 * time.Second)
+	for {
+		_entries, _handled, _failed, _messages := _host.counts()
+		if _entries == 1 && _handled == 2 && _failed == 1 && _messages == 1 {
+			break
+		}
+		if time.Now().After(_deadline) {
+			t.Fatalf("unexpected host calls %d %d %d %d", _entries, _handled, _failed, _messages)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAccept_Timeout(t *testing.T) {
+
+	_socket := filepath.Join(t.TempDir(), "unit.sock")
+	_listener, _err := net.ListenUnix("unix", &net.UnixAddr{Name: _socket, Net: "unix"})
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	defer _listener.Close()
+
+	/// only the control connection is made
+	_conn, _err := dial(_socket, ROLE_CONTROL)
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	defer _conn.Close()
+
+	if _, _err = Accept(_listener, 200*time.Millisecond, NewHostService(&test_host{})); _err == nil {
+		t.Fatal("expected error when the host connection is not made")
+	}
+}
+
+func TestDial_No_Framework(t *testing.T) {
+
+	_socket := filepath.Join(t.TempDir(), "missing.sock")
+	if _, _, _err := Dial(_socket); _err == nil {
+		t.Fatal("expected error when the framework socket does not exist")
+	}
+}
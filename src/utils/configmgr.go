@@ -3,6 +3,9 @@
 // Configuration functions:
 //			- LoadMainConfiguration
 //			- LoadAppConfiguration
+//			- LoadCoreConfigurationExt
+//			- LoadAppConfigurationExt
+//			- Read_Config_Content
 //			- Stop
 // File management functions:
 //			- IsFileExist
@@ -16,12 +19,8 @@
 //			- Execute_Command
 // Plugin functions:
 //			- load_plugin
-//			- Get_MailerPlugIn
-//			- Get_MQClientPlugIn
-//			- Get_MQClusterClientPlugIn
-//			- Get_HTTPClientPlugIn
-//			- Get_WSClientPlugIn
-//			- Get_LoggerPlugIn
+//			- Get_PlugIn
+//			- Get_AppUnit
 /*
 ########################################################################################
 
@@ -54,6 +53,8 @@ Author        :   D. Ajith Nilantha de Silva  | 02/01/2024
 
 	Ajith de Silva		03/01/2024	Updated 	Defined functions with parameters & return values
 
+	Ajith de Silva		19/10/2026	Added 		Added LoadAppConfigurationExt for the framework only unit settings
+
 ########################################################################################
 */
 package utils
@@ -62,28 +63,24 @@ import (
 	apptypes "agnione/v1/src/appfm/types"
 	"encoding/json"
 	"errors"
+
+	fmtypes "agnione.appfm/src/fmtypes"
 )
 
 // / LoadCoreConfiguration laods the core configuration
 func LoadCoreConfiguration(filename *string) (*apptypes.FMConfig, error) {
 
-	_file, _err := GetFilePtr(filename)
+	_data, _err := Read_Config_Content(filename)
 
 	if _err != nil {
 		return nil, _err
 	}
 
-	defer func ()  {
-		_file.Close()
-		_file = nil
-	}()
-	
 	_config := &apptypes.FMConfig{}
-	_decoder := json.NewDecoder(_file)
-	_err = _decoder.Decode(_config)
+	_err = json.Unmarshal(_data, _config)
 
 	defer func ()  {
-		_decoder = nil
+		_data = nil
 		_config = nil
 		_err=nil
 	}()
@@ -98,24 +95,18 @@ func LoadCoreConfiguration(filename *string) (*apptypes.FMConfig, error) {
 // / loadAppConfiguration laods the application configuration
 func LoadAppConfiguration(filename *string) (*apptypes.AppConfig, error) {
 
-	_file, _err := GetFilePtr(filename)
+	_data, _err := Read_Config_Content(filename)
 
 	if _err != nil {
 		return nil, _err
 	}
 	
-	defer func ()  {
-		_file.Close()
-		_file = nil
-	}()
-	
 	_appConfig := &apptypes.AppConfig{}
-	_decoder := json.NewDecoder(_file)
-	_err = _decoder.Decode(_appConfig)
+	_err = json.Unmarshal(_data, _appConfig)
 	
 	defer func ()  {
 		_appConfig = nil
-		_decoder = nil
+		_data = nil
 		_err=nil
 	}()
 	
@@ -124,3 +115,69 @@ func LoadAppConfiguration(filename *string) (*apptypes.AppConfig, error) {
 	}
 	return _appConfig, nil /// all good.
 }
+
+// / LoadCoreConfigurationExt laods the framework only settings of the core configuration
+func LoadCoreConfigurationExt(filename *string) (*fmtypes.FMConfigExt, error) {
+
+	_data, _err := Read_Config_Content(filename)
+
+	if _err != nil {
+		return nil, _err
+	}
+
+	_config := &fmtypes.FMConfigExt{}
+	_err = json.Unmarshal(_data, _config)
+
+	defer func ()  {
+		_data = nil
+		_err=nil
+	}()
+	
+	if _err != nil {
+		return nil,errors.New("Error decoding JSON data: " +  _err.Error())
+	}
+
+	return _config, nil /// all good.
+}
+
+// / LoadAppConfigurationExt laods the framework only settings of the application configuration
+func LoadAppConfigurationExt(filename *string) (*fmtypes.AppConfigExt, error) {
+
+	_data, _err := Read_Config_Content(filename)
+
+	if _err != nil {
+		return nil, _err
+	}
+
+	_config := &fmtypes.AppConfigExt{}
+	_err = json.Unmarshal(_data, _config)
+
+	defer func ()  {
+		_data = nil
+		_err=nil
+	}()
+	
+	if _err != nil {
+		return nil,errors.New("Error decoding JSON data: " +  _err.Error())
+	}
+
+	return _config, nil /// all good.
+}
+
+// / Read_Config_Content reads the given JSON configuration file and resolves the secret references in it.
+// / File on disk is not changed, resolved values are kept only in memory.
+func Read_Config_Content(filename *string) ([]byte, error) {
+
+	_data, _err := Get_File_Content(filename)
+
+	if _err != nil {
+		return nil, _err
+	}
+
+	_resolved, _err := Resolve_Secrets(*_data)
+	if _err != nil {
+		return nil, errors.New(*filename + " - " + _err.Error())
+	}
+
+	return _resolved, nil
+}
@@ -21,6 +21,7 @@
 #------------------------------------------------------------------------------------------------------
 # Ajith de Silva				02/01/2024	Created 	Created the initial version
 # Ajith de Silva				03/01/2024	Updated 	Defined functions with parameters & return values
+# Ajith de Silva				19/10/2026	Added 		Added WriteFileAtomic to replace a file with a temp file & rename
 #######################################################################################################
 ******************************************************************************************************
 */
@@ -31,6 +32,7 @@ import (
 	atypes "agnione/v1/src/appfm/types"
 	"bufio"
 	"os"
+	"path/filepath"
 )
 
 // IsFileExist checks if the given file exists.
@@ -98,6 +100,38 @@ func WriteFileContent(pFileName *string, pData *[]byte) (bool, error) {
 	}
 }
 
+// WriteFileAtomic writes the given content []byte to a temp file in the folder of the given filename and renames
+// it to the filename, so that the readers see the old or the new content, never a partial one.
+// Returns true and nil if the file is replaced. Unless returns false and error
+func WriteFileAtomic(pFileName *string, pData *[]byte) (bool, error) {
+
+	_temp, _err := os.CreateTemp(filepath.Dir(*pFileName), "."+filepath.Base(*pFileName)+".*.tmp")
+	if _err != nil {
+		return false, _err
+	}
+	_temp_name := _temp.Name()
+
+	_, _err = _temp.Write(*pData)
+	if _err == nil {
+		_err = _temp.Sync()
+	}
+	if _close_err := _temp.Close(); _err == nil {
+		_err = _close_err
+	}
+	if _err == nil {
+		_err = os.Chmod(_temp_name, 0644)
+	}
+	if _err == nil {
+		_err = os.Rename(_temp_name, *pFileName)
+	}
+
+	if _err != nil {
+		os.Remove(_temp_name)
+		return false, _err
+	}
+	return true, nil
+}
+
 // GetFilePtr returns the file pointer of the given file
 // Returns valid file pointer and nil if file exists.
 // Unless returns nil and error
@@ -0,0 +1,215 @@
+/*
+*****************************************************************************************************
+# Author        :   D. Ajith Nilantha de Silva  contact@agnione.net  | 19/10/2026
+
+# Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+# Class/module  :   file watcher
+
+# Objective     :   Watch set of files for changes.
+					Uses inotify where it is available. Unless polls the files for modification time & size.
+#######################################################################################################
+# Author                        Date        Action      Description
+#------------------------------------------------------------------------------------------------------
+# Ajith de Silva				19/10/2026	Created 	Created the initial version
+# Ajith de Silva				19/10/2026	Updated 	Guarded the notifier replaced by the polling fall back
+#######################################################################################################
+******************************************************************************************************
+*/
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// file_state holds the last seen state of a watched file
+type file_state struct {
+	mod_time time.Time
+	size     int64
+	exists   bool
+}
+
+// FileWatcher watches the given files and sends the path of the changed file to the Events channel
+type FileWatcher struct {
+	Events   chan string /// path of the changed files
+	lock     *sync.RWMutex
+	files    map[string]file_state
+	interval time.Duration
+	stopper  chan bool
+	notifier *inotifier /// nil if polling is used. guarded by lock
+}
+
+// NewFileWatcher creates a FileWatcher instance with given polling interval.
+// Polling interval is used only if inotify is not available.
+func NewFileWatcher(pInterval time.Duration) *FileWatcher {
+	if pInterval <= 0 {
+		pInterval = 2 * time.Second
+	}
+
+	return &FileWatcher{
+		Events:   make(chan string, 64),
+		lock:     &sync.RWMutex{},
+		files:    make(map[string]file_state),
+		interval: pInterval,
+	}
+}
+
+// stat_file returns the current state of the given file
+func stat_file(pFileName string) file_state {
+	if _info, _err := os.Stat(pFileName); _err == nil {
+		return file_state{mod_time: _info.ModTime(), size: _info.Size(), exists: true}
+	}
+	return file_state{}
+}
+
+// Set_Files replaces the set of watched files
+func (fw *FileWatcher) Set_Files(pFiles []string) {
+
+	_files := make(map[string]file_state)
+	for _, _file := range pFiles {
+		if _abs, _err := filepath.Abs(_file); _err == nil {
+			_files[_abs] = stat_file(_abs)
+		}
+	}
+
+	fw.lock.Lock()
+	fw.files = _files
+	_notifier := fw.notifier
+	fw.lock.Unlock()
+
+	if _notifier != nil {
+		for _file := range _files {
+			_notifier.add_dir(filepath.Dir(_file))
+		}
+	}
+}
+
+// Files returns the watched files
+func (fw *FileWatcher) Files() []string {
+	fw.lock.RLock()
+	defer fw.lock.RUnlock()
+
+	_files := make([]string, 0, len(fw.files))
+	for _file := range fw.files {
+		_files = append(_files, _file)
+	}
+	return _files
+}
+
+// Start starts watching the files.
+// Returns true if inotify is used, false if the files are polled.
+func (fw *FileWatcher) Start() bool {
+
+	fw.stopper = make(chan bool)
+
+	if _notifier, _err := new_inotifier(); _err == nil {
+		fw.lock.Lock()
+		fw.notifier = _notifier
+		fw.lock.Unlock()
+
+		for _, _file := range fw.Files() {
+			_notifier.add_dir(filepath.Dir(_file))
+		}
+		go fw.notify_loop(_notifier)
+		return true
+	}
+
+	go fw.poll_loop()
+	return false
+}
+
+// Stop stops watching the files
+func (fw *FileWatcher) Stop() {
+	defer recover()
+
+	close(fw.stopper)
+
+	fw.lock.RLock()
+	_notifier := fw.notifier
+	fw.lock.RUnlock()
+
+	if _notifier != nil {
+		_notifier.close()
+	}
+}
+
+// changed checks the given file against the last seen state and sends it to Events if it is changed
+func (fw *FileWatcher) changed(pFileName string) {
+
+	fw.lock.Lock()
+	_last, _found := fw.files[pFileName]
+	if !_found {
+		fw.lock.Unlock()
+		return
+	}
+
+	_current := stat_file(pFileName)
+	if _current == _last {
+		fw.lock.Unlock()
+		return
+	}
+	fw.files[pFileName] = _current
+	fw.lock.Unlock()
+
+	select {
+	case fw.Events <- pFileName:
+	default: /// receiver is behind. it is debounced anyway.
+	}
+}
+
+// poll_loop checks the watched files on every interval
+func (fw *FileWatcher) poll_loop() {
+
+	_ticker := time.NewTicker(fw.interval)
+	defer _ticker.Stop()
+
+	for {
+		select {
+		case <-fw.stopper:
+			return
+		case <-_ticker.C:
+			for _, _file := range fw.Files() {
+				fw.changed(_file)
+			}
+		}
+	}
+}
+
+// notify_loop reads the inotify events of the watched folders from the given notifier
+func (fw *FileWatcher) notify_loop(pNotifier *inotifier) {
+
+	for {
+		_names, _err := pNotifier.read()
+		if _err != nil {
+			select {
+			case <-fw.stopper:
+				return
+			default:
+			}
+			/// inotify failed. fall back to polling
+			fw.lock.Lock()
+			fw.notifier = nil
+			fw.lock.Unlock()
+			pNotifier.close()
+			fw.poll_loop()
+			return
+		}
+
+		for _, _name := range _names {
+			fw.changed(_name)
+		}
+	}
+}
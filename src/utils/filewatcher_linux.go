@@ -0,0 +1,99 @@
+//go:build linux
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// inotifier reads the inotify events of the watched folders
+type inotifier struct {
+	file *os.File
+	fd   int
+	lock *sync.Mutex
+	dirs map[int32]string /// watch descriptor -> folder
+}
+
+const inotify_mask = syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO | syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_ATTRIB
+
+func new_inotifier() (*inotifier, error) {
+	_fd, _err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if _err != nil {
+		return nil, _err
+	}
+
+	/// non blocking fd is served by the runtime poller, so Close unblocks the pending Read
+	return &inotifier{
+		file: os.NewFile(uintptr(_fd), "inotify"),
+		fd:   _fd,
+		lock: &sync.Mutex{},
+		dirs: make(map[int32]string),
+	}, nil
+}
+
+// add_dir adds the given folder to the inotify instance. Folders are watched, so editors replacing the file are handled.
+func (in *inotifier) add_dir(pDir string) {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+
+	for _, _dir := range in.dirs {
+		if _dir == pDir {
+			return
+		}
+	}
+
+	if _wd, _err := syscall.InotifyAddWatch(in.fd, pDir, inotify_mask); _err == nil {
+		in.dirs[int32(_wd)] = pDir
+	}
+}
+
+// read blocks until events are received and returns the path of the files in the events
+func (in *inotifier) read() ([]string, error) {
+
+	var _buffer [syscall.SizeofInotifyEvent * 64]byte
+
+	_count, _err := in.file.Read(_buffer[:])
+	if _err != nil {
+		return nil, _err
+	}
+
+	_names := make([]string, 0)
+	var _offset int
+
+	for _offset+syscall.SizeofInotifyEvent <= _count {
+		_event := (*syscall.InotifyEvent)(unsafe.Pointer(&_buffer[_offset]))
+		_name_len := int(_event.Len)
+		_start := _offset + syscall.SizeofInotifyEvent
+
+		if _name_len > 0 && _start+_name_len <= _count {
+			_name := string(_buffer[_start : _start+_name_len])
+			/// name is null padded
+			for _index := 0; _index < len(_name); _index++ {
+				if _name[_index] == 0 {
+					_name = _name[:_index]
+					break
+				}
+			}
+
+			in.lock.Lock()
+			_dir, _found := in.dirs[_event.Wd]
+			in.lock.Unlock()
+
+			if _found {
+				_names = append(_names, filepath.Join(_dir, _name))
+			}
+		}
+
+		_offset = _start + _name_len
+	}
+
+	return _names, nil
+}
+
+func (in *inotifier) close() {
+	in.file.Close()
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package utils
+
+import "errors"
+
+// inotifier is not available on this platform. FileWatcher polls the files.
+type inotifier struct{}
+
+func new_inotifier() (*inotifier, error) {
+	return nil, errors.New("inotify is not supported")
+}
+
+func (in *inotifier) add_dir(pDir string) {}
+
+func (in *inotifier) read() ([]string, error) {
+	return nil, errors.New("inotify is not supported")
+}
+
+func (in *inotifier) close() {}
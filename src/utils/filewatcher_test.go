@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestFileWatcherChanges checks the changed files are sent to Events while the files are replaced concurrently
+func TestFileWatcherChanges(t *testing.T) {
+
+	_dir := t.TempDir()
+	_first := filepath.Join(_dir, "first.config")
+	_second := filepath.Join(_dir, "second.config")
+	for _, _file := range []string{_first, _second} {
+		if _err := os.WriteFile(_file, []byte("{}"), 0644); _err != nil {
+			t.Fatal(_err)
+		}
+	}
+
+	_watcher := NewFileWatcher(20 * time.Millisecond)
+	_watcher.Set_Files([]string{_first})
+	_watcher.Start()
+	defer _watcher.Stop()
+
+	_wait := &sync.WaitGroup{}
+	for _index := 0; _index < 4; _index++ {
+		_wait.Add(1)
+		go func() {
+			defer _wait.Done()
+			for _loop := 0; _loop < 25; _loop++ {
+				_watcher.Set_Files([]string{_first, _second})
+				_watcher.Files()
+			}
+		}()
+	}
+	_wait.Wait()
+
+	if _err := os.WriteFile(_second, []byte(`{"changed":true}`), 0644); _err != nil {
+		t.Fatal(_err)
+	}
+
+	_timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _file := <-_watcher.Events:
+			if _file == _second {
+				return
+			}
+		case <-_timeout:
+			t.Fatal("change of " + _second + " is not received")
+		}
+	}
+}
+
+// TestFileWatcherUnwatched checks the changes of the files not watched are not sent
+func TestFileWatcherUnwatched(t *testing.T) {
+
+	_dir := t.TempDir()
+	_watched := filepath.Join(_dir, "watched.config")
+	_other := filepath.Join(_dir, "other.config")
+
+	_watcher := NewFileWatcher(20 * time.Millisecond)
+	_watcher.Set_Files([]string{_watched})
+	_watcher.Start()
+	defer _watcher.Stop()
+
+	if _err := os.WriteFile(_other, []byte("{}"), 0644); _err != nil {
+		t.Fatal(_err)
+	}
+
+	select {
+	case _file := <-_watcher.Events:
+		t.Fatal("unexpected change of " + _file)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,264 @@
+/*
+*****************************************************************************************************
+# Author        :   D. Ajith Nilantha de Silva  contact@agnione.net  | 19/10/2026
+
+# Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+# Class/module  :   plugin ABI check
+
+# Objective     :   Compare the embedded build info of the plugin/unit .so files with the framework,
+					so that the exact mismatch is reported instead of the plugin.Open error
+					"plugin was built with a different version of package".
+					Packages of the GOROOT (eg: agnione/v1) are not modules, so their fingerprints, recorded by
+					the linker for the runtime check of plugin.Open, are compared as well.
+#######################################################################################################
+# Author                        Date        Action      Description
+#------------------------------------------------------------------------------------------------------
+# Ajith de Silva				19/10/2026	Created 	Created the initial version
+# Ajith de Silva				19/10/2026	Updated 	Compared the package fingerprints of the packages shared by the plugin
+#######################################################################################################
+******************************************************************************************************
+*/
+
+package utils
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"encoding/hex"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// abi_settings define the build settings that should be the same in the framework & the plugins
+var abi_settings = []string{"GOOS", "GOARCH", "CGO_ENABLED", "-compiler"}
+
+// PACKAGE_HASH_SYMBOL define the prefix of the symbols of the package fingerprints, written by the Go linker
+// in the binaries that can load plugins and in the plugins
+const PACKAGE_HASH_SYMBOL = "go:link.pkghashbytes."
+
+// abi_build holds the build info & the package fingerprints of a binary
+type abi_build struct {
+	go_version string
+	deps       []*debug.Module
+	modules    map[string]*debug.Module
+	settings   map[string]string
+	packages   map[string]string /// fingerprint by package path. nil if the symbols can not be read
+}
+
+var host_build struct {
+	once  sync.Once
+	build *abi_build
+}
+
+// new_abi_build creates the abi_build of the given build info & package fingerprints
+func new_abi_build(pInfo *debug.BuildInfo, pPackages map[string]string) *abi_build {
+
+	_build := &abi_build{go_version: pInfo.GoVersion, deps: pInfo.Deps, modules: make(map[string]*debug.Module),
+		settings: make(map[string]string), packages: pPackages}
+
+	for _, _dep := range pInfo.Deps {
+		_build.modules[_dep.Path] = _dep
+	}
+	for _, _setting := range pInfo.Settings {
+		_build.settings[_setting.Key] = _setting.Value
+	}
+	return _build
+}
+
+// read_abi_build reads the build info & the package fingerprints of the given binary
+func read_abi_build(pFileName string) (*abi_build, error) {
+
+	_info, _err := buildinfo.ReadFile(pFileName)
+	if _err != nil {
+		return nil, _err
+	}
+
+	_packages, _ := read_package_hashes(pFileName)
+	return new_abi_build(_info, _packages), nil
+}
+
+// load_host_build reads the build info of the framework once. Package fingerprints are read from its executable
+func load_host_build() *abi_build {
+	host_build.once.Do(func() {
+		_info, _ok := debug.ReadBuildInfo()
+		if !_ok {
+			return
+		}
+
+		var _packages map[string]string
+		if _executable, _err := os.Executable(); _err == nil {
+			_packages, _ = read_package_hashes(_executable)
+		}
+		host_build.build = new_abi_build(_info, _packages)
+	})
+	return host_build.build
+}
+
+// read_package_hashes reads the package fingerprints from the symbols of the given ELF binary.
+// Returns nil,error if the file is not ELF or its symbols are stripped
+func read_package_hashes(pFileName string) (map[string]string, error) {
+
+	_file, _err := elf.Open(pFileName)
+	if _err != nil {
+		return nil, _err
+	}
+	defer _file.Close()
+
+	_symbols, _err := _file.Symbols()
+	if _err != nil {
+		return nil, _err
+	}
+
+	_packages := make(map[string]string)
+	for _, _symbol := range _symbols {
+		if !strings.HasPrefix(_symbol.Name, PACKAGE_HASH_SYMBOL) || _symbol.Size == 0 ||
+			int(_symbol.Section) >= len(_file.Sections) {
+			continue
+		}
+
+		_section := _file.Sections[_symbol.Section]
+		if _section.Type == elf.SHT_NOBITS || _symbol.Value < _section.Addr {
+			continue
+		}
+
+		_hash := make([]byte, _symbol.Size)
+		if _, _err = _section.ReadAt(_hash, int64(_symbol.Value-_section.Addr)); _err != nil {
+			continue
+		}
+		_packages[strings.TrimPrefix(_symbol.Name, PACKAGE_HASH_SYMBOL)] = hex.EncodeToString(_hash)
+	}
+	return _packages, nil
+}
+
+// module_version returns the effective version of the module. Replaced modules are given as path@version
+func module_version(pModule *debug.Module) string {
+	if pModule.Replace != nil {
+		if pModule.Replace.Path != pModule.Path {
+			return pModule.Replace.Path + "@" + pModule.Replace.Version
+		}
+		return pModule.Replace.Version
+	}
+	return pModule.Version
+}
+
+// module_sum returns the checksum of the effective module
+func module_sum(pModule *debug.Module) string {
+	if pModule.Replace != nil {
+		return pModule.Replace.Sum
+	}
+	return pModule.Sum
+}
+
+// Check_Plugin_ABI compares the Go version, build settings, the versions of the modules and the fingerprints of
+// the packages shared by the given .so file and the framework.
+//
+// Report is not checked (Checked=false, Compatible=false) if the build info of the file or the framework can not be read.
+func Check_Plugin_ABI(pFileName *string) *fmtypes.PluginABIReport {
+
+	_host := load_host_build()
+	if _host == nil {
+		return &fmtypes.PluginABIReport{File: *pFileName, Mismatches: make([]fmtypes.PluginABIMismatch, 0),
+			Error: "build info of the framework is not available"}
+	}
+
+	return check_abi(pFileName, _host)
+}
+
+// check_abi compares the build of the given .so file with the given build of the framework
+func check_abi(pFileName *string, pHost *abi_build) *fmtypes.PluginABIReport {
+
+	_report := &fmtypes.PluginABIReport{File: *pFileName, Host_Go_Version: pHost.go_version,
+		Mismatches: make([]fmtypes.PluginABIMismatch, 0)}
+
+	_plugin, _err := read_abi_build(*pFileName)
+	if _err != nil {
+		_report.Error = "build info can not be read. " + _err.Error()
+		return _report
+	}
+
+	_report.Checked = true
+	_report.Go_Version = _plugin.go_version
+
+	if _plugin.go_version != _report.Host_Go_Version {
+		_report.Mismatches = append(_report.Mismatches, fmtypes.PluginABIMismatch{
+			Kind: "go", Name: "toolchain", Host: _report.Host_Go_Version, Plugin: _plugin.go_version})
+	}
+
+	for _, _name := range abi_settings {
+		_value, _found := _plugin.settings[_name]
+		if !_found {
+			continue
+		}
+		if _host, _found := pHost.settings[_name]; _found && _host != _value {
+			_report.Mismatches = append(_report.Mismatches, fmtypes.PluginABIMismatch{
+				Kind: "setting", Name: _name, Host: _host, Plugin: _value})
+		}
+	}
+
+	/// modules used by both. modules used only by the plugin are loaded from the plugin
+	for _, _dep := range _plugin.deps {
+		_host, _found := pHost.modules[_dep.Path]
+		if !_found {
+			continue
+		}
+
+		_host_version, _plugin_version := module_version(_host), module_version(_dep)
+		_host_sum, _plugin_sum := module_sum(_host), module_sum(_dep)
+
+		if _host_version != _plugin_version ||
+			(len(_host_sum) > 0 && len(_plugin_sum) > 0 && _host_sum != _plugin_sum) {
+			if _host_version == _plugin_version {
+				_host_version, _plugin_version = _host_version+" "+_host_sum, _plugin_version+" "+_plugin_sum
+			}
+			_report.Mismatches = append(_report.Mismatches, fmtypes.PluginABIMismatch{
+				Kind: "module", Name: _dep.Path, Host: _host_version, Plugin: _plugin_version})
+		}
+	}
+
+	/// packages used by both, including the GOROOT packages that are not modules.
+	/// all the packages differ with a different toolchain, which is reported already
+	if _plugin.go_version == _report.Host_Go_Version && _plugin.packages != nil && pHost.packages != nil {
+		_names := make([]string, 0)
+		for _name, _hash := range _plugin.packages {
+			if _host, _found := pHost.packages[_name]; _found && _host != _hash {
+				_names = append(_names, _name)
+			}
+		}
+		sort.Strings(_names)
+
+		for _, _name := range _names {
+			_report.Mismatches = append(_report.Mismatches, fmtypes.PluginABIMismatch{
+				Kind: "package", Name: _name, Host: pHost.packages[_name], Plugin: _plugin.packages[_name]})
+		}
+	}
+
+	_report.Compatible = len(_report.Mismatches) == 0
+	return _report
+}
+
+// ABI_Error returns the mismatches of the report as an error message
+func ABI_Error(pReport *fmtypes.PluginABIReport) string {
+
+	_mismatches := make([]string, 0, len(pReport.Mismatches))
+	for _, _mismatch := range pReport.Mismatches {
+		_mismatches = append(_mismatches, _mismatch.Kind+" "+_mismatch.Name+" "+_mismatch.Plugin+
+			" (framework "+_mismatch.Host+")")
+	}
+
+	return "plugin " + pReport.File + " is built with a different version of " + strings.Join(_mismatches, ", ")
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// abi_module is the module of the test framework & plugins. shared package is used by both
+var abi_module = map[string]string{
+	"go.mod":           "module abitest\n\ngo 1.22\n",
+	"shared/shared.go": "package shared\n\nfunc Version() string { return \"v1\" }\n",
+	"host/main.go": "package main\n\nimport (\n\t\"os\"\n\t\"plugin\"\n\n\t\"abitest/shared\"\n)\n\n" +
+		"func main() {\n\tprintln(shared.Version())\n\tplugin.Open(os.Args[0])\n}\n",
+	"plug/main.go": "package main\n\nimport \"abitest/shared\"\n\nfunc Version() string { return shared.Version() }\n",
+}
+
+// build_abi_module builds the framework & the plugin of abi_module in the given folder, with the shared package
+// changed for the plugin if pChanged. Returns the framework & the plugin files
+func build_abi_module(t *testing.T, pDir string, pChanged bool) (string, string) {
+
+	for _, _name := range []string{"go.mod", "shared/shared.go", "host/main.go", "plug/main.go"} {
+		_file := filepath.Join(pDir, _name)
+		os.MkdirAll(filepath.Dir(_file), 0755)
+		if _err := os.WriteFile(_file, []byte(abi_module[_name]), 0644); _err != nil {
+			t.Fatal(_err)
+		}
+	}
+
+	_host := filepath.Join(pDir, "host.bin")
+	go_build(t, pDir, "-o", _host, "./host")
+
+	if pChanged {
+		_shared := "package shared\n\nfunc Version() string { return \"v2\" }\n"
+		if _err := os.WriteFile(filepath.Join(pDir, "shared/shared.go"), []byte(_shared), 0644); _err != nil {
+			t.Fatal(_err)
+		}
+	}
+
+	_plugin := filepath.Join(pDir, "plug.so")
+	go_build(t, pDir, "-buildmode=plugin", "-o", _plugin, "./plug")
+
+	return _host, _plugin
+}
+
+// go_build runs go build in the given folder. Test is skipped if plugins can not be built
+func go_build(t *testing.T, pDir string, pArgs ...string) {
+
+	_command := exec.Command("go", append([]string{"build"}, pArgs...)...)
+	_command.Dir = pDir
+	_command.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=", "CGO_ENABLED=1", "GOTOOLCHAIN=local")
+
+	if _output, _err := _command.CombinedOutput(); _err != nil {
+		t.Skip("plugins can not be built. " + _err.Error() + "\n" + string(_output))
+	}
+}
+
+// TestCheckPluginABI checks the package fingerprints of a plugin built with a changed shared package are reported
+func TestCheckPluginABI(t *testing.T) {
+
+	if runtime.GOOS != "linux" {
+		t.Skip("package fingerprints are read from ELF binaries")
+	}
+	if _, _err := exec.LookPath("go"); _err != nil {
+		t.Skip("go toolchain is not found")
+	}
+	if testing.Short() {
+		t.Skip("plugins are not built in short mode")
+	}
+
+	_cases := []struct {
+		name       string
+		changed    bool
+		compatible bool
+		kind       string
+		mismatch   string
+	}{
+		{"same build", false, true, "", ""},
+		{"changed shared package", true, false, "package", "abitest/shared"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_host_file, _plugin_file := build_abi_module(t, t.TempDir(), _case.changed)
+
+			_host, _err := read_abi_build(_host_file)
+			if _err != nil {
+				t.Fatal(_err)
+			}
+			if len(_host.packages) == 0 {
+				t.Fatal("package fingerprints of the framework are not read")
+			}
+
+			_report := check_abi(&_plugin_file, _host)
+			if !_report.Checked {
+				t.Fatal("plugin is not checked. " + _report.Error)
+			}
+			if _report.Compatible != _case.compatible {
+				t.Fatalf("compatible = %v, want %v. mismatches %v", _report.Compatible, _case.compatible, _report.Mismatches)
+			}
+			if len(_case.mismatch) == 0 {
+				return
+			}
+
+			if len(_report.Mismatches) != 1 || _report.Mismatches[0].Kind != _case.kind ||
+				_report.Mismatches[0].Name != _case.mismatch {
+				t.Fatalf("mismatches = %v, want %s %s", _report.Mismatches, _case.kind, _case.mismatch)
+			}
+			if ABI_Error(_report) == "" {
+				t.Error("ABI_Error is empty")
+			}
+		})
+	}
+}
+
+// TestCheckPluginABIBuild checks the toolchain & build setting differences of the framework are reported
+func TestCheckPluginABIBuild(t *testing.T) {
+
+	if runtime.GOOS != "linux" {
+		t.Skip("package fingerprints are read from ELF binaries")
+	}
+	if _, _err := exec.LookPath("go"); _err != nil {
+		t.Skip("go toolchain is not found")
+	}
+	if testing.Short() {
+		t.Skip("plugins are not built in short mode")
+	}
+
+	_host_file, _plugin_file := build_abi_module(t, t.TempDir(), false)
+
+	_cases := []struct {
+		name   string
+		change func(pHost *abi_build)
+		kind   string
+		item   string
+	}{
+		{"toolchain", func(pHost *abi_build) { pHost.go_version = "go1.0" }, "go", "toolchain"},
+		{"cgo", func(pHost *abi_build) { pHost.settings["CGO_ENABLED"] = "0" }, "setting", "CGO_ENABLED"},
+		{"arch", func(pHost *abi_build) { pHost.settings["GOARCH"] = "other" }, "setting", "GOARCH"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_host, _err := read_abi_build(_host_file)
+			if _err != nil {
+				t.Fatal(_err)
+			}
+			_case.change(_host)
+
+			_report := check_abi(&_plugin_file, _host)
+			if _report.Compatible {
+				t.Fatal("plugin is reported as compatible")
+			}
+			if len(_report.Mismatches) != 1 || _report.Mismatches[0].Kind != _case.kind ||
+				_report.Mismatches[0].Name != _case.item {
+				t.Fatalf("mismatches = %v, want %s %s", _report.Mismatches, _case.kind, _case.item)
+			}
+		})
+	}
+}
+
+// TestCheckPluginABIUnreadable checks a file without build info is not checked
+func TestCheckPluginABIUnreadable(t *testing.T) {
+
+	_file := filepath.Join(t.TempDir(), "plain.so")
+	if _err := os.WriteFile(_file, []byte("not a plugin"), 0644); _err != nil {
+		t.Fatal(_err)
+	}
+
+	_report := Check_Plugin_ABI(&_file)
+	if _report.Checked || _report.Compatible || len(_report.Error) == 0 {
+		t.Fatalf("report = %+v, want not checked with error", _report)
+	}
+}
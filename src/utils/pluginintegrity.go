@@ -0,0 +1,229 @@
+/*
+*****************************************************************************************************
+# Author        :   D. Ajith Nilantha de Silva  contact@agnione.net  | 19/10/2026
+
+# Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+# Class/module  :   plugin integrity
+
+# Objective     :   Verify the sha256 pin and the ed25519 signature of the plugin/unit .so files
+					before they are opened, so that a replaced file is not loaded into the framework process.
+					Verified content is written to a folder that only the framework user can write and the
+					copy is loaded, so the file can not be replaced between the check and the load
+#######################################################################################################
+# Author                        Date        Action      Description
+#------------------------------------------------------------------------------------------------------
+# Ajith de Silva				19/10/2026	Created 	Created the initial version
+# Ajith de Silva				19/10/2026	Updated 	Required the signature with the trusted keys & loaded the verified copy
+#######################################################################################################
+******************************************************************************************************
+*/
+
+package utils
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// DEFAULT_TRUSTED_KEYS define the directory of the trusted public keys, if not set in core.config
+const DEFAULT_TRUSTED_KEYS string = "config/trusted_keys/"
+
+// SIGNATURE_EXT define the extension of the signature file next to the .so file
+const SIGNATURE_EXT string = ".sig"
+
+// VERIFIED_DIR define the folder of the verified copies in the temp folder. User id of the framework is appended
+const VERIFIED_DIR string = "agnione-verified-"
+
+// verified_lock serializes the writes of the verified copies
+var verified_lock = &sync.Mutex{}
+
+// Load_Trusted_Keys loads the ed25519 public keys (*.pub, *.pem) of the given directory.
+// Returns empty list if the directory does not exist. Returns error if a key file is invalid
+func Load_Trusted_Keys(pDirectory *string) ([]ed25519.PublicKey, error) {
+
+	_keys := make([]ed25519.PublicKey, 0)
+
+	_entries, _err := os.ReadDir(*pDirectory)
+	if _err != nil {
+		if os.IsNotExist(_err) {
+			return _keys, nil
+		}
+		return nil, _err
+	}
+
+	_files := make([]string, 0, len(_entries))
+	for _, _entry := range _entries {
+		_ext := strings.ToLower(filepath.Ext(_entry.Name()))
+		if !_entry.IsDir() && (_ext == ".pub" || _ext == ".pem") {
+			_files = append(_files, filepath.Join(*pDirectory, _entry.Name()))
+		}
+	}
+	sort.Strings(_files)
+
+	for _, _file := range _files {
+		_key, _err := Load_PublicKey(&_file)
+		if _err != nil {
+			return nil, _err
+		}
+		_keys = append(_keys, _key)
+	}
+
+	return _keys, nil
+}
+
+// Read_Signature reads the ed25519 signature file. Signature can be raw 64 bytes or base64/hex encoded
+func Read_Signature(pFileName *string) ([]byte, error) {
+
+	_data, _err := os.ReadFile(*pFileName)
+	if _err != nil {
+		return nil, _err
+	}
+
+	if len(_data) == ed25519.SignatureSize {
+		return _data, nil
+	}
+
+	_signature, _err := Decode_Binary(string(_data))
+	if _err != nil {
+		return nil, errors.New("invalid signature file " + *pFileName)
+	}
+	return _signature, nil
+}
+
+// Verify_Plugin_File checks the given .so file against the sha256 pin and the signature file.
+// Signature is required if trusted keys are given or pPolicy requires it, and checked against the trusted keys.
+// pPolicy defines whether the pin and the signature are mandatory.
+//
+// Checked content is written to a folder of the framework user and the copy is returned to be loaded,
+// so that the file can not be replaced after the check. File is returned as it is if nothing is checked.
+//
+// Returns the file to load,nil if the file can be loaded. Unless returns "",error
+func Verify_Plugin_File(pFileName *string, pSHA256 string, pSignature_File string, pKeys []ed25519.PublicKey,
+	pPolicy *fmtypes.PluginIntegrity) (string, error) {
+
+	_has_pin := len(strings.TrimSpace(pSHA256)) > 0
+	_has_signature := IsFileExist(&pSignature_File)
+
+	if !_has_pin && pPolicy.Require_Checksum == 1 {
+		return "", errors.New("sha256 checksum is not pinned")
+	}
+
+	if !_has_signature && (pPolicy.Require_Signature == 1 || len(pKeys) > 0) {
+		return "", errors.New("signature file " + pSignature_File + " is not found")
+	}
+
+	if !_has_pin && !_has_signature {
+		return *pFileName, nil
+	}
+
+	_data, _err := os.ReadFile(*pFileName)
+	if _err != nil {
+		return "", _err
+	}
+
+	if _has_pin {
+		if _err = Verify_Checksum(_data, pSHA256); _err != nil {
+			return "", _err
+		}
+	}
+
+	if _has_signature {
+		_signature, _err := Read_Signature(&pSignature_File)
+		if _err != nil {
+			return "", _err
+		}
+		if _err = Verify_Signature(_data, _signature, pKeys); _err != nil {
+			return "", errors.New(_err.Error() + " (" + pSignature_File + ")")
+		}
+	}
+
+	return write_verified_copy(*pFileName, _data)
+}
+
+// verified_dir returns the folder of the verified copies. Folder is created for the framework user only.
+// Returns error if the folder is owned by another user or can be written by the others
+func verified_dir() (string, error) {
+
+	_dir := filepath.Join(os.TempDir(), VERIFIED_DIR+strconv.Itoa(os.Getuid()))
+
+	if _err := os.Mkdir(_dir, 0700); _err != nil && !os.IsExist(_err) {
+		return "", _err
+	}
+
+	/// Lstat, so that a link placed by an other user is refused
+	_info, _err := os.Lstat(_dir)
+	if _err != nil {
+		return "", _err
+	}
+	if !_info.IsDir() || _info.Mode().Perm()&0077 != 0 {
+		return "", errors.New("folder of the verified copies " + _dir + " is not private to the framework user")
+	}
+	if _stat, _ok := _info.Sys().(*syscall.Stat_t); _ok && int(_stat.Uid) != os.Getuid() {
+		return "", errors.New("folder of the verified copies " + _dir + " is owned by another user")
+	}
+
+	return _dir, nil
+}
+
+// write_verified_copy writes the verified content of the file to the folder of the verified copies.
+// Copy is named by the checksum of the content, so the same content is loaded from the same copy.
+// Returns the copy,nil if successful. Unless "",error
+func write_verified_copy(pFileName string, pData []byte) (string, error) {
+
+	_dir, _err := verified_dir()
+	if _err != nil {
+		return "", _err
+	}
+
+	_copy := filepath.Join(_dir, SHA256_Hex(pData)+"-"+filepath.Base(pFileName))
+
+	verified_lock.Lock()
+	defer verified_lock.Unlock()
+
+	if _current, _err := os.ReadFile(_copy); _err == nil && bytes.Equal(_current, pData) {
+		return _copy, nil
+	}
+
+	_temp, _err := os.CreateTemp(_dir, ".copy-*")
+	if _err != nil {
+		return "", _err
+	}
+
+	_, _err = _temp.Write(pData)
+	if _close_err := _temp.Close(); _err == nil {
+		_err = _close_err
+	}
+	if _err == nil {
+		/// exec units are run from the copy
+		_err = os.Chmod(_temp.Name(), 0500)
+	}
+	if _err == nil {
+		_err = os.Rename(_temp.Name(), _copy)
+	}
+	if _err != nil {
+		os.Remove(_temp.Name())
+		return "", _err
+	}
+
+	return _copy, nil
+}
@@ -0,0 +1,158 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// write_signed_plugin writes a plugin file & its signature in the given folder. Returns the file & the signer key
+func write_signed_plugin(t *testing.T, pDir string, pData []byte) (string, ed25519.PublicKey) {
+
+	_public, _private, _err := ed25519.GenerateKey(rand.Reader)
+	if _err != nil {
+		t.Fatal(_err)
+	}
+
+	_file := filepath.Join(pDir, "plugin.so")
+	if _err = os.WriteFile(_file, pData, 0644); _err != nil {
+		t.Fatal(_err)
+	}
+	if _err = os.WriteFile(_file+SIGNATURE_EXT, ed25519.Sign(_private, pData), 0644); _err != nil {
+		t.Fatal(_err)
+	}
+	return _file, _public
+}
+
+func TestVerify_Plugin_File(t *testing.T) {
+
+	t.Setenv("TMPDIR", t.TempDir())
+
+	_data := []byte("plugin content")
+	_other, _, _ := ed25519.GenerateKey(rand.Reader)
+
+	_cases := []struct {
+		name      string
+		sha256    string
+		signature bool
+		keys      func(pSigner ed25519.PublicKey) []ed25519.PublicKey
+		policy    fmtypes.PluginIntegrity
+		copied    bool
+		fails     string
+	}{
+		{name: "nothing to check", keys: func(ed25519.PublicKey) []ed25519.PublicKey { return nil }},
+		{name: "pinned", sha256: SHA256_Hex(_data), keys: func(ed25519.PublicKey) []ed25519.PublicKey { return nil }, copied: true},
+		{name: "wrong pin", sha256: SHA256_Hex([]byte("other")), keys: func(ed25519.PublicKey) []ed25519.PublicKey { return nil }, fails: "checksum"},
+		{name: "pin required", keys: func(ed25519.PublicKey) []ed25519.PublicKey { return nil },
+			policy: fmtypes.PluginIntegrity{Require_Checksum: 1}, fails: "not pinned"},
+		{name: "signed", signature: true, keys: func(pSigner ed25519.PublicKey) []ed25519.PublicKey { return []ed25519.PublicKey{pSigner} }, copied: true},
+		{name: "signed by untrusted key", signature: true,
+			keys: func(ed25519.PublicKey) []ed25519.PublicKey { return []ed25519.PublicKey{_other} }, fails: "signature"},
+		{name: "signature missing with trusted keys",
+			keys: func(pSigner ed25519.PublicKey) []ed25519.PublicKey { return []ed25519.PublicKey{pSigner} }, fails: "not found"},
+		{name: "signature required", keys: func(ed25519.PublicKey) []ed25519.PublicKey { return nil },
+			policy: fmtypes.PluginIntegrity{Require_Signature: 1}, fails: "not found"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+
+			_file, _signer := write_signed_plugin(t, t.TempDir(), _data)
+			if !_case.signature {
+				os.Remove(_file + SIGNATURE_EXT)
+			}
+
+			_loaded, _err := Verify_Plugin_File(&_file, _case.sha256, _file+SIGNATURE_EXT, _case.keys(_signer), &_case.policy)
+
+			if len(_case.fails) > 0 {
+				if _err == nil || !strings.Contains(_err.Error(), _case.fails) {
+					t.Fatalf("expected error with %q, got %v", _case.fails, _err)
+				}
+				return
+			}
+			if _err != nil {
+				t.Fatal(_err)
+			}
+			if _case.copied == (_loaded == _file) {
+				t.Fatalf("unexpected file to load %s", _loaded)
+			}
+		})
+	}
+}
+
+func TestVerify_Plugin_File_Copy(t *testing.T) {
+
+	t.Setenv("TMPDIR", t.TempDir())
+
+	_data := []byte("plugin content")
+	_file, _signer := write_signed_plugin(t, t.TempDir(), _data)
+	_keys := []ed25519.PublicKey{_signer}
+	_policy := fmtypes.PluginIntegrity{}
+
+	_loaded, _err := Verify_Plugin_File(&_file, "", _file+SIGNATURE_EXT, _keys, &_policy)
+	if _err != nil {
+		t.Fatal(_err)
+	}
+
+	/// the file is replaced after the check. the verified copy is not changed
+	if _err = os.WriteFile(_file, []byte("replaced content"), 0644); _err != nil {
+		t.Fatal(_err)
+	}
+	_copy, _err := os.ReadFile(_loaded)
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	if string(_copy) != string(_data) {
+		t.Fatalf("verified copy is changed: %q", _copy)
+	}
+
+	_info, _err := os.Stat(filepath.Dir(_loaded))
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	if _info.Mode().Perm()&0077 != 0 {
+		t.Fatalf("folder of the verified copies is open to the others: %v", _info.Mode())
+	}
+
+	/// same content is loaded from the same copy
+	if _err = os.WriteFile(_file, _data, 0644); _err != nil {
+		t.Fatal(_err)
+	}
+	_again, _err := Verify_Plugin_File(&_file, "", _file+SIGNATURE_EXT, _keys, &_policy)
+	if _err != nil {
+		t.Fatal(_err)
+	}
+	if _again != _loaded {
+		t.Fatalf("same content is copied to %s and %s", _loaded, _again)
+	}
+
+	/// replaced file is refused
+	if _err = os.WriteFile(_file, []byte("replaced content"), 0644); _err != nil {
+		t.Fatal(_err)
+	}
+	if _, _err = Verify_Plugin_File(&_file, "", _file+SIGNATURE_EXT, _keys, &_policy); _err == nil {
+		t.Fatal("replaced file is verified")
+	}
+}
+
+func TestVerified_Dir_Refuses_Open_Folder(t *testing.T) {
+
+	_temp := t.TempDir()
+	t.Setenv("TMPDIR", _temp)
+
+	_dir := filepath.Join(_temp, VERIFIED_DIR+strconv.Itoa(os.Getuid()))
+	if _err := os.Mkdir(_dir, 0777); _err != nil {
+		t.Fatal(_err)
+	}
+	os.Chmod(_dir, 0777)
+
+	if _, _err := verified_dir(); _err == nil {
+		t.Fatal("folder writable by the others is used")
+	}
+}
@@ -21,6 +21,11 @@
 #------------------------------------------------------------------------------------------------------
 # Ajith de Silva				02/01/2024	Created 	Created the initial version
 # Ajith de Silva				03/01/2024	Updated 	Defined functions with parameters & return values
+# Ajith de Silva				19/10/2026	Updated 	Replaced the per type getters with Get_PlugIn
+# Ajith de Silva				19/10/2026	Updated 	Added the build compatibility preflight to load_plugin
+# Ajith de Silva				19/10/2026	Updated 	Added the integrity verification to load_plugin
+# Ajith de Silva				19/10/2026	Updated 	Opened the verified copy returned by the integrity check
+# Ajith de Silva				19/10/2026	Updated 	Added the load counts of the plugin files
 #######################################################################################################
 ******************************************************************************************************
 */
@@ -33,20 +38,37 @@ import (
 	"plugin"
 
 	aau "agnione/v1/src/aau/iappunit"                  /// import the unit interface
-	ihttp "agnione/v1/src/afplugins/http/iahttpclient" /// import the http client interface
-
-	iws "agnione/v1/src/afplugins/websocket/iawsclient" /// import the wsclient interface
 )
 
 // load_plugin loads the plugin of given plugin name and given interface name
+// pVerify checks the integrity of the file before it is opened and returns the verified file to open. nil skips the check
 // Returns plugin.Symbol and nil if success. Unless nil and error
-func load_plugin(interface_name string, plugin_name *string) (plugin.Symbol, error) {
+func load_plugin(interface_name string, plugin_name *string, pVerify func(*string) (string, error)) (_symbol plugin.Symbol, _err error) {
+
+	/// load counts & the last error are shown in the plugin inventory
+	defer func() {
+		Record_Plugin_Load(*plugin_name, _err)
+	}()
 
 	// can handle symbolic link, but will no follow the link
 
+	/// integrity. the file is refused before any of its code is run. the verified copy is opened
+	_file := *plugin_name
+	if pVerify != nil {
+		if _file, _err = pVerify(plugin_name); _err != nil {
+			return nil, errors.New("plugin " + *plugin_name + " failed the integrity check. " + _err.Error())
+		}
+	}
+
+	/// preflight. plugin.Open fails with an opaque error if the plugin is built with a different toolchain/module
+	if _report := Check_Plugin_ABI(&_file); _report.Checked && !_report.Compatible {
+		_report.File = *plugin_name
+		return nil, errors.New(ABI_Error(_report))
+	}
+
 	/// load module
 	/// 1. open the so file to load the symbols
-	_plugin, _err := plugin.Open(*plugin_name)
+	_plugin, _err := plugin.Open(_file)
 	
 	defer func(){
 		_plugin=nil
@@ -66,99 +88,35 @@ func load_plugin(interface_name string, plugin_name *string) (plugin.Symbol, err
 }
 
 
-// Get_HTTPPlugIn loads the http client library plugin from given file name and interface name
-// Returns IAHTTPClient and nil if success. Unless nil and error
-func Get_HTTPClientPlugIn(interface_name *string, plugin_filename *string) (ihttp.IAHTTPClient, error) {
+// Get_PlugIn loads the plugin from given file name and interface name.
+// Loaded symbol is asserted to the plugin interface and the instance is created via the given hook
+// Returns the plugin instance and nil if success. Unless nil and error
+func Get_PlugIn(interface_name *string, plugin_filename *string, pHook func(any) (any, error), pVerify func(*string) (string, error)) (any, error) {
 
-	_symClient, _err := load_plugin(*interface_name, plugin_filename)
+	_symClient, _err := load_plugin(*interface_name, plugin_filename, pVerify)
 	
 	defer func(){
 		_symClient=nil
-
 	}()
 	
 	if _err != nil {
 		return nil, _err
 	}
 
-	/// 2. look up a symbol (an exported function or variable)
-	/// in this case, variable Greeter
-
-	/// 3. Assert that loaded symbol is of a desired type
-	/// in this case interface type Greeter (defined above)
-	var _iHTTPClientLib ihttp.IAHTTPClient
-	defer func(){
-		_iHTTPClientLib=nil
-	}()
-	
-	_iHTTPClientLib, ok := _symClient.(ihttp.IAHTTPClient)
-	if !ok {
-		return nil, fmt.Errorf("unexpected type from module symbols %v", _iHTTPClientLib)
-	}
-
-	_ihttpClient := _iHTTPClientLib.New() /// creates the instance
-	if _ihttpClient == nil {
-		return nil, errors.New("failed to creates a instance of " +  *interface_name)
-	}
-
-	_httpClient := _ihttpClient.(ihttp.IAHTTPClient)
-
-	if _httpClient == nil {
-		return nil, errors.New("failed to creates a instance from interface of " +  *interface_name)
-	}
-
-	return _httpClient, nil
-
-}
-
-// Get_WSClientTPlugIn loads the http client library plugin from given file name and interface name
-// Returns IZWSClient and nil if success. Unless nil and error
-func Get_WSClientPlugIn(interface_name *string, plugin_filename *string) (iws.IAWSClient, error) {
-
-	_symClient, _err := load_plugin(*interface_name, plugin_filename)
-	
-	defer func(){
-		_symClient=nil
-	}()
-	
+	/// assert the loaded symbol to the plugin interface and create the instance
+	_instance, _err := pHook(_symClient)
 	if _err != nil {
-		return nil, _err
-	}
-
-	/// 2. look up a symbol (an exported function or variable)
-	/// in this case, variable Greeter
-
-	/// 3. Assert that loaded symbol is of a desired type
-	/// in this case interface type Greeter (defined above)
-	var _iWSClient iws.IAWSClient
-	defer func(){
-		_iWSClient=nil
-	}()
-	
-	_iWSClient, ok := _symClient.(iws.IAWSClient)
-	if !ok {
-		return nil, fmt.Errorf("unexpected type from module symbols %v", _iWSClient)
-	}
-
-	_iwsc := _iWSClient.New() /// creates the instance
-	if _iwsc == nil {
-		return nil, errors.New("failed to creates a instance of " +  *interface_name)
-	}
-
-	_wsClient := _iwsc.(iws.IAWSClient)
-
-	if _wsClient == nil {
-		return nil, errors.New("failed to creates a instance from interface of " +  *interface_name)
+		return nil, errors.New(*interface_name + " - " + _err.Error())
 	}
 
-	return _wsClient, nil
+	return _instance, nil
 }
 
 // Get_WSClientTPlugIn loads the http client library plugin from given file name and interface name
 // Returns IAHTTPClient and nil if success. Unless nil and error
-func Get_AppUnit(plugin_filename *string) (aau.IAppUnit, error) {
+func Get_AppUnit(plugin_filename *string, pVerify func(*string) (string, error)) (aau.IAppUnit, error) {
 
-	_symClient, _err := load_plugin("IAppUnit", plugin_filename)
+	_symClient, _err := load_plugin("IAppUnit", plugin_filename, pVerify)
 	
 	defer func(){
 		_symClient=nil
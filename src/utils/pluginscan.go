@@ -0,0 +1,176 @@
+/*
+*****************************************************************************************************
+# Author        :   D. Ajith Nilantha de Silva  contact@agnione.net  | 19/10/2026
+
+# Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+# Class/module  :   plugin scanner
+
+# Objective     :   Scan the plugins tree for .so files, read their build metadata & exported symbols
+					and keep the load counts of the plugin/unit files
+#######################################################################################################
+# Author                        Date        Action      Description
+#------------------------------------------------------------------------------------------------------
+# Ajith de Silva				19/10/2026	Created 	Created the initial version
+#######################################################################################################
+******************************************************************************************************
+*/
+
+package utils
+
+import (
+	"debug/buildinfo"
+	"debug/elf"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	fmtypes "agnione.appfm/src/fmtypes"
+)
+
+// PLUGIN_EXT define the extension of the plugin files
+const PLUGIN_EXT string = ".so"
+
+var load_stats = struct {
+	lock  sync.Mutex
+	files map[string]*fmtypes.PluginLoadStats
+}{files: make(map[string]*fmtypes.PluginLoadStats)}
+
+// plugin_key returns the absolute path of the file to match the same file given with different paths.
+// Paths with a scheme (eg: static://) are returned as they are
+func plugin_key(pFileName string) string {
+	if strings.Contains(pFileName, "://") {
+		return pFileName
+	}
+	if _abs, _err := filepath.Abs(pFileName); _err == nil {
+		return _abs
+	}
+	return pFileName
+}
+
+// Record_Plugin_Load updates the load counts of the file with the result of a load
+func Record_Plugin_Load(pFileName string, pErr error) {
+
+	load_stats.lock.Lock()
+	defer load_stats.lock.Unlock()
+
+	_key := plugin_key(pFileName)
+	_stats, _found := load_stats.files[_key]
+	if !_found {
+		_stats = &fmtypes.PluginLoadStats{}
+		load_stats.files[_key] = _stats
+	}
+
+	if pErr != nil {
+		_stats.Failed_Count++
+		_stats.Last_Error = pErr.Error()
+		return
+	}
+
+	_stats.Load_Count++
+	_stats.Last_Loaded = time.Now().Format(time.RFC3339)
+	_stats.Last_Error = ""
+}
+
+// Plugin_Load_Stats returns the load counts of the given plugin/unit file
+func Plugin_Load_Stats(pFileName *string) fmtypes.PluginLoadStats {
+
+	load_stats.lock.Lock()
+	defer load_stats.lock.Unlock()
+
+	if _stats, _found := load_stats.files[plugin_key(*pFileName)]; _found {
+		return *_stats
+	}
+	return fmtypes.PluginLoadStats{}
+}
+
+// Scan_Plugins returns the .so files of the given directory tree sorted by path.
+// Returns empty list if the directory does not exist
+func Scan_Plugins(pDirectory *string) ([]string, error) {
+
+	_files := make([]string, 0)
+
+	if !IsFileExist(pDirectory) {
+		return _files, nil
+	}
+
+	_err := filepath.WalkDir(*pDirectory, func(pPath string, pEntry fs.DirEntry, pErr error) error {
+		if pErr != nil {
+			return pErr
+		}
+		if !pEntry.IsDir() && strings.EqualFold(filepath.Ext(pPath), PLUGIN_EXT) {
+			_files = append(_files, pPath)
+		}
+		return nil
+	})
+
+	sort.Strings(_files)
+	return _files, _err
+}
+
+// Read_Plugin_File reads the build metadata & the exported symbols of the main package of the given .so file.
+// File is not opened as a plugin, so none of its code is run
+func Read_Plugin_File(pFileName *string) *fmtypes.PluginFileInfo {
+
+	_info := &fmtypes.PluginFileInfo{Path: *pFileName, Exports: make([]string, 0)}
+
+	if _stat, _err := os.Stat(*pFileName); _err == nil {
+		_info.Size = _stat.Size()
+		_info.Modified = _stat.ModTime().Format(time.RFC3339)
+	}
+
+	_build, _err := buildinfo.ReadFile(*pFileName)
+	if _err != nil {
+		_info.Error = "build info can not be read. " + _err.Error()
+		return _info
+	}
+	_info.Module, _info.Go_Version = _build.Path, _build.GoVersion
+
+	_elf, _err := elf.Open(*pFileName)
+	if _err != nil {
+		_info.Error = "symbols can not be read. " + _err.Error()
+		return _info
+	}
+	defer _elf.Close()
+
+	_symbols, _err := _elf.DynamicSymbols()
+	if _err != nil {
+		_info.Error = "symbols can not be read. " + _err.Error()
+		return _info
+	}
+
+	/// main package of a plugin is named by the plugin path. module path or plugin/unnamed-<hash>
+	for _, _symbol := range _symbols {
+		var _name string
+		switch {
+		case strings.HasPrefix(_symbol.Name, _build.Path+"."):
+			_name = strings.TrimPrefix(_symbol.Name, _build.Path+".")
+		case strings.HasPrefix(_symbol.Name, "plugin/unnamed-"):
+			_, _name, _ = strings.Cut(_symbol.Name, ".")
+		default:
+			continue
+		}
+
+		/// methods & package internals are not exported symbols
+		if _first := []rune(_name); len(_first) > 0 && unicode.IsUpper(_first[0]) && !strings.Contains(_name, ".") {
+			_info.Exports = append(_info.Exports, _name)
+		}
+	}
+
+	sort.Strings(_info.Exports)
+	return _info
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestScan_Plugins(t *testing.T) {
+
+	_dir := t.TempDir()
+	for _, _file := range []string{"mq/memory.so", "http/default.so", "http/readme.txt", "units/api/API.SO", "units/api.so.bak", "root.so"} {
+		_path := filepath.Join(_dir, _file)
+		os.MkdirAll(filepath.Dir(_path), 0755)
+		os.WriteFile(_path, []byte("plugin"), 0644)
+	}
+	os.MkdirAll(filepath.Join(_dir, "empty.so"), 0755) /// folders are not plugins
+
+	_files, _err := Scan_Plugins(&_dir)
+	if _err != nil {
+		t.Fatal(_err)
+	}
+
+	_want := []string{"http/default.so", "mq/memory.so", "root.so", "units/api/API.SO"}
+	for _index := range _want {
+		_want[_index] = filepath.Join(_dir, _want[_index])
+	}
+	if !slices.Equal(_files, _want) {
+		t.Fatalf("expected %v, got %v", _want, _files)
+	}
+
+	_missing := filepath.Join(_dir, "missing")
+	if _files, _err = Scan_Plugins(&_missing); _err != nil || len(_files) != 0 {
+		t.Fatalf("expected no files for the missing folder, got %v %v", _files, _err)
+	}
+}
+
+func TestPlugin_Load_Stats(t *testing.T) {
+
+	_dir := t.TempDir()
+	_file := filepath.Join(_dir, "plugin.so")
+
+	Record_Plugin_Load(_file, nil)
+	Record_Plugin_Load(_file, errors.New("plugin was built with a different version"))
+
+	/// same file given with a relative path
+	_wd, _ := os.Getwd()
+	_relative, _ := filepath.Rel(_wd, _file)
+
+	_stats := Plugin_Load_Stats(&_relative)
+	if _stats.Load_Count != 1 || _stats.Failed_Count != 1 || _stats.Last_Error != "plugin was built with a different version" ||
+		len(_stats.Last_Loaded) == 0 {
+		t.Fatalf("unexpected stats %+v", _stats)
+	}
+
+	Record_Plugin_Load(_file, nil)
+	if _stats = Plugin_Load_Stats(&_file); _stats.Load_Count != 2 || len(_stats.Last_Error) > 0 {
+		t.Fatalf("expected the error cleared by the load, got %+v", _stats)
+	}
+
+	/// static:// paths are kept as they are
+	_static := "static://demo"
+	Record_Plugin_Load(_static, nil)
+	if _stats = Plugin_Load_Stats(&_static); _stats.Load_Count != 1 {
+		t.Fatalf("unexpected stats of the static path %+v", _stats)
+	}
+
+	_unknown := filepath.Join(_dir, "unknown.so")
+	if _stats = Plugin_Load_Stats(&_unknown); _stats.Load_Count != 0 || _stats.Failed_Count != 0 {
+		t.Fatalf("unexpected stats of the unknown file %+v", _stats)
+	}
+}
+
+func TestRead_Plugin_File(t *testing.T) {
+
+	_file := filepath.Join(t.TempDir(), "plugin.so")
+	os.WriteFile(_file, []byte("not an elf file"), 0644)
+
+	_info := Read_Plugin_File(&_file)
+	if _info.Size != 15 || len(_info.Modified) == 0 || !strings.HasPrefix(_info.Error, "build info can not be read.") {
+		t.Fatalf("unexpected info %+v", _info)
+	}
+}
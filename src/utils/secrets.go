@@ -0,0 +1,356 @@
+/*
+*****************************************************************************************************
+# Author        :   D. Ajith Nilantha de Silva  contact@agnione.net  | 19/10/2026
+
+# Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+# Class/module  :   secrets
+
+# Objective     :   Resolve the secret references in the configuration content.
+
+					env://<NAME>              - value of the environment variable
+					secret://file/<path>      - content of the file. eg: secret://file/run/secrets/db
+					secret://local/<name>     - value from the AES-GCM encrypted local secrets file
+
+					Resolved values are kept to mask them in the logs & API responses.
+					Every occurrence of a resolved value is masked, whatever its length.
+#######################################################################################################
+# Author                        Date        Action      Description
+#------------------------------------------------------------------------------------------------------
+# Ajith de Silva				19/10/2026	Created 	Created the initial version
+# Ajith de Silva				19/10/2026	Updated 	Masked every occurrence of the resolved values, whatever the length
+#######################################################################################################
+******************************************************************************************************
+*/
+
+package utils
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	SECRET_ENV   = "env://"
+	SECRET_FILE  = "secret://file"
+	SECRET_LOCAL = "secret://local/"
+
+	SECRET_MASK = "******"
+)
+
+// DEFAULT_SECRETS_FILE & DEFAULT_SECRETS_KEY_FILE define the local secrets file & its key file, relative to base path
+const (
+	DEFAULT_SECRETS_FILE     = "config/secrets.enc"
+	DEFAULT_SECRETS_KEY_FILE = "config/secrets.key"
+)
+
+// secret_store holds the local secrets and the resolved values
+type secret_store struct {
+	lock      *sync.RWMutex
+	base_path string
+	local     map[string]string /// decrypted local secrets. nil until first secret://local reference
+	values    map[string]bool   /// resolved values to be masked
+	masked    []string          /// resolved values to be masked, longest first
+}
+
+var secrets = &secret_store{lock: &sync.RWMutex{}, values: make(map[string]bool)}
+
+// Init_Secrets sets the base path of the local secrets file and its key file.
+// Key file location can be overridden by AGNI_SECRETS_KEY_FILE environment variable.
+func Init_Secrets(pBase_Path *string) {
+	secrets.lock.Lock()
+	defer secrets.lock.Unlock()
+
+	secrets.base_path = *pBase_Path
+	secrets.local = nil
+}
+
+// Is_Secret_Ref returns true if the given value is a secret reference
+func Is_Secret_Ref(pValue string) bool {
+	return strings.HasPrefix(pValue, SECRET_ENV) || strings.HasPrefix(pValue, SECRET_FILE+"/") ||
+		strings.HasPrefix(pValue, SECRET_LOCAL)
+}
+
+// Resolve_Secret returns the value of the given secret reference.
+// Returns the given value as it is, if it is not a secret reference.
+func Resolve_Secret(pRef string) (string, error) {
+
+	var _value string
+
+	switch {
+	case strings.HasPrefix(pRef, SECRET_ENV):
+		_name := strings.TrimPrefix(pRef, SECRET_ENV)
+		_env, _found := os.LookupEnv(_name)
+		if !_found {
+			return "", errors.New("secret " + pRef + " is not set")
+		}
+		_value = _env
+
+	case strings.HasPrefix(pRef, SECRET_FILE+"/"):
+		_data, _err := os.ReadFile(strings.TrimPrefix(pRef, SECRET_FILE))
+		if _err != nil {
+			return "", errors.New("secret " + pRef + " can not be read")
+		}
+		_value = strings.TrimRight(string(_data), "\r\n")
+
+	case strings.HasPrefix(pRef, SECRET_LOCAL):
+		_local, _err := secrets.local_secrets()
+		if _err != nil {
+			return "", errors.New("secret " + pRef + " can not be read. " + _err.Error())
+		}
+		_local_value, _found := _local[strings.TrimPrefix(pRef, SECRET_LOCAL)]
+		if !_found {
+			return "", errors.New("secret " + pRef + " is not found in the local secrets")
+		}
+		_value = _local_value
+
+	default:
+		return pRef, nil
+	}
+
+	secrets.add_value(_value)
+	return _value, nil
+}
+
+// Resolve_Secrets resolves all the secret references in the given JSON content.
+// Returns the content as it is, if there is no secret reference.
+func Resolve_Secrets(pData []byte) ([]byte, error) {
+
+	if !bytes.Contains(pData, []byte(SECRET_ENV)) && !bytes.Contains(pData, []byte("secret://")) {
+		return pData, nil
+	}
+
+	var _content any
+	_decoder := json.NewDecoder(bytes.NewReader(pData))
+	_decoder.UseNumber()
+
+	if _err := _decoder.Decode(&_content); _err != nil {
+		return nil, errors.New("Error decoding JSON data: " + _err.Error())
+	}
+
+	_resolved, _err := resolve_value(_content)
+	if _err != nil {
+		return nil, _err
+	}
+
+	return json.Marshal(_resolved)
+}
+
+// resolve_value resolves the secret references in the given decoded JSON value
+func resolve_value(pValue any) (any, error) {
+
+	var _err error
+
+	switch _value := pValue.(type) {
+	case string:
+		if Is_Secret_Ref(_value) {
+			return Resolve_Secret(_value)
+		}
+
+	case map[string]any:
+		for _key, _item := range _value {
+			if _value[_key], _err = resolve_value(_item); _err != nil {
+				return nil, _err
+			}
+		}
+
+	case []any:
+		for _index, _item := range _value {
+			if _value[_index], _err = resolve_value(_item); _err != nil {
+				return nil, _err
+			}
+		}
+	}
+
+	return pValue, nil
+}
+
+// Mask_Secrets replaces every occurrence of the resolved secret values in the given text,
+// also inside the longer words. eg: secret "s3cr3t" is masked in "user=admin:s3cr3t@host" as "user=admin:******@host"
+func Mask_Secrets(pText string) string {
+	secrets.lock.RLock()
+	defer secrets.lock.RUnlock()
+
+	for _, _value := range secrets.masked {
+		pText = strings.ReplaceAll(pText, _value, SECRET_MASK)
+	}
+	return pText
+}
+
+// add_value adds the resolved value to be masked. Empty values are not masked.
+func (ss *secret_store) add_value(pValue string) {
+	if len(pValue) == 0 {
+		return
+	}
+
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	if ss.values[pValue] {
+		return
+	}
+	ss.values[pValue] = true
+
+	/// longer values are masked first, so that a value containing another value is masked as a whole
+	ss.masked = append(ss.masked, pValue)
+	sort.Slice(ss.masked, func(pI int, pJ int) bool { return len(ss.masked[pI]) > len(ss.masked[pJ]) })
+}
+
+// files returns the local secrets file & its key file
+func (ss *secret_store) files() (string, string) {
+	_key_file := os.Getenv("AGNI_SECRETS_KEY_FILE")
+	if len(_key_file) == 0 {
+		_key_file = filepath.Join(ss.base_path, DEFAULT_SECRETS_KEY_FILE)
+	}
+	return filepath.Join(ss.base_path, DEFAULT_SECRETS_FILE), _key_file
+}
+
+// local_secrets returns the decrypted local secrets. Secrets file is decrypted on first use.
+func (ss *secret_store) local_secrets() (map[string]string, error) {
+
+	ss.lock.RLock()
+	_local := ss.local
+	ss.lock.RUnlock()
+
+	if _local != nil {
+		return _local, nil
+	}
+
+	_secrets_file, _key_file := ss.files()
+
+	_local, _err := Decrypt_Secrets(_secrets_file, _key_file)
+	if _err != nil {
+		return nil, _err
+	}
+
+	ss.lock.Lock()
+	ss.local = _local
+	ss.lock.Unlock()
+
+	return _local, nil
+}
+
+// Secrets_Files returns the local secrets file & its key file in use
+func Secrets_Files() (string, string) {
+	secrets.lock.RLock()
+	defer secrets.lock.RUnlock()
+	return secrets.files()
+}
+
+// read_secrets_key reads the AES-256 key from the key file. Key can be raw 32 bytes or hex/base64 encoded.
+func read_secrets_key(pKey_File string) ([]byte, error) {
+
+	_data, _err := os.ReadFile(pKey_File)
+	if _err != nil {
+		return nil, _err
+	}
+
+	if len(_data) == 32 {
+		return _data, nil
+	}
+
+	_key, _err := Decode_Binary(string(_data))
+	if _err != nil || len(_key) != 32 {
+		return nil, errors.New("invalid secrets key " + pKey_File + ". 32 bytes key is required")
+	}
+
+	return _key, nil
+}
+
+// new_gcm creates the AES-GCM cipher with the key of the given key file
+func new_gcm(pKey_File string) (cipher.AEAD, error) {
+
+	_key, _err := read_secrets_key(pKey_File)
+	if _err != nil {
+		return nil, _err
+	}
+
+	_block, _err := aes.NewCipher(_key)
+	if _err != nil {
+		return nil, _err
+	}
+
+	return cipher.NewGCM(_block)
+}
+
+// Decrypt_Secrets decrypts the local secrets file with the key file.
+// Secrets file is nonce + AES-GCM encrypted JSON object of name:value pairs
+func Decrypt_Secrets(pSecrets_File string, pKey_File string) (map[string]string, error) {
+
+	_gcm, _err := new_gcm(pKey_File)
+	if _err != nil {
+		return nil, _err
+	}
+
+	_data, _err := os.ReadFile(pSecrets_File)
+	if _err != nil {
+		return nil, _err
+	}
+
+	if len(_data) < _gcm.NonceSize() {
+		return nil, errors.New("invalid secrets file " + pSecrets_File)
+	}
+
+	_plain, _err := _gcm.Open(nil, _data[:_gcm.NonceSize()], _data[_gcm.NonceSize():], nil)
+	if _err != nil {
+		return nil, errors.New("failed to decrypt " + pSecrets_File + ". wrong key or corrupted file")
+	}
+
+	_secrets := make(map[string]string)
+	if _err = json.Unmarshal(_plain, &_secrets); _err != nil {
+		return nil, errors.New("invalid secrets content in " + pSecrets_File)
+	}
+
+	return _secrets, nil
+}
+
+// Encrypt_Secrets encrypts the given name:value pairs into the local secrets file with the key file.
+// Key file is created with a new random key, if not exists.
+func Encrypt_Secrets(pSecrets map[string]string, pSecrets_File string, pKey_File string) error {
+
+	if !IsFileExist(&pKey_File) {
+		_key := make([]byte, 32)
+		if _, _err := io.ReadFull(rand.Reader, _key); _err != nil {
+			return _err
+		}
+		if _err := os.WriteFile(pKey_File, _key, 0600); _err != nil {
+			return _err
+		}
+	}
+
+	_gcm, _err := new_gcm(pKey_File)
+	if _err != nil {
+		return _err
+	}
+
+	_plain, _err := json.Marshal(pSecrets)
+	if _err != nil {
+		return _err
+	}
+
+	_nonce := make([]byte, _gcm.NonceSize())
+	if _, _err = io.ReadFull(rand.Reader, _nonce); _err != nil {
+		return _err
+	}
+
+	return os.WriteFile(pSecrets_File, _gcm.Seal(_nonce, _nonce, _plain, nil), 0600)
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestMaskSecrets checks every occurrence of the resolved values is masked
+func TestMaskSecrets(t *testing.T) {
+
+	_saved := secrets
+	defer func() { secrets = _saved }()
+
+	secrets = &secret_store{lock: &sync.RWMutex{}, values: make(map[string]bool)}
+	for _, _value := range []string{"s3cr3t", "pass", "s3cr3t-long", "#tok#", "abc"} {
+		secrets.add_value(_value)
+	}
+
+	_cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"whole token", "pwd=s3cr3t;", "pwd=******;"},
+		{"start & end of text", "s3cr3t", "******"},
+		{"inside a word", "mys3cr3ts", "my******s"},
+		{"next to word characters", "user=admin:s3cr3t@host", "user=admin:******@host"},
+		{"every occurrence", "pass pass", "****** ******"},
+		{"longest value first", "key=s3cr3t-long", "key=******"},
+		{"non word edges", "x#tok#y", "x******y"},
+		{"quoted in JSON", `{"pwd":"s3cr3t"}`, `{"pwd":"******"}`},
+		{"short value", "abc,abc", "******,******"},
+		{"no secret", "nothing here", "nothing here"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			if _got := Mask_Secrets(_case.text); _got != _case.want {
+				t.Errorf("Mask_Secrets(%q) = %q, want %q", _case.text, _got, _case.want)
+			}
+		})
+	}
+}
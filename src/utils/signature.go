@@ -0,0 +1,136 @@
+/*
+*****************************************************************************************************
+# Author        :   D. Ajith Nilantha de Silva  contact@agnione.net  | 19/10/2026
+
+# Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+# Class/module  :   signature
+
+# Objective     :   Functions to verify the SHA-256 checksum and the ed25519 signature of the content
+#######################################################################################################
+# Author                        Date        Action      Description
+#------------------------------------------------------------------------------------------------------
+# Ajith de Silva				19/10/2026	Created 	Created the initial version
+#######################################################################################################
+******************************************************************************************************
+*/
+
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"os"
+	"strings"
+)
+
+// SHA256_Hex returns the SHA-256 checksum of the given content as lower case hex string
+func SHA256_Hex(pData []byte) string {
+	_sum := sha256.Sum256(pData)
+	return hex.EncodeToString(_sum[:])
+}
+
+// Verify_Checksum checks the SHA-256 checksum of the given content.
+// Checksum can be given as "<hex>" or "sha256:<hex>".
+// Returns nil if matched. Unless returns the error
+func Verify_Checksum(pData []byte, pChecksum string) error {
+
+	_expected := strings.ToLower(strings.TrimSpace(pChecksum))
+	_expected = strings.TrimPrefix(_expected, "sha256:")
+
+	/// sha256sum output format "<hex>  <file name>"
+	if _fields := strings.Fields(_expected); len(_fields) > 0 {
+		_expected = _fields[0]
+	}
+
+	if len(_expected) != sha256.Size*2 {
+		return errors.New("invalid sha256 checksum " + pChecksum)
+	}
+
+	if _actual := SHA256_Hex(pData); _actual != _expected {
+		return errors.New("sha256 checksum mismatch. expected " + _expected + ", found " + _actual)
+	}
+
+	return nil
+}
+
+// Load_PublicKey loads the ed25519 public key from given file.
+// Key file can be PEM encoded (PUBLIC KEY) or base64/hex encoded raw 32 bytes key.
+// Returns the public key and nil if successful. Unless nil and error
+func Load_PublicKey(pFileName *string) (ed25519.PublicKey, error) {
+
+	_data, _err := os.ReadFile(*pFileName)
+	if _err != nil {
+		return nil, _err
+	}
+
+	if _block, _ := pem.Decode(_data); _block != nil {
+		_key, _err := x509.ParsePKIXPublicKey(_block.Bytes)
+		if _err != nil {
+			return nil, errors.New("invalid public key " + *pFileName + ". " + _err.Error())
+		}
+
+		if _edkey, _ok := _key.(ed25519.PublicKey); _ok {
+			return _edkey, nil
+		}
+		return nil, errors.New("public key " + *pFileName + " is not an ed25519 key")
+	}
+
+	_raw, _err := Decode_Binary(string(_data))
+	if _err != nil || len(_raw) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key " + *pFileName)
+	}
+
+	return ed25519.PublicKey(_raw), nil
+}
+
+// Decode_Binary decodes the given base64 or hex encoded value
+func Decode_Binary(pValue string) ([]byte, error) {
+
+	_value := strings.TrimSpace(pValue)
+
+	if _data, _err := hex.DecodeString(_value); _err == nil {
+		return _data, nil
+	}
+
+	if _data, _err := base64.StdEncoding.DecodeString(_value); _err == nil {
+		return _data, nil
+	}
+
+	return base64.RawURLEncoding.DecodeString(_value)
+}
+
+// Verify_Signature checks the ed25519 signature of the given content against the given public keys.
+// Returns nil if any of the keys verifies the signature. Unless returns the error
+func Verify_Signature(pData []byte, pSignature []byte, pKeys []ed25519.PublicKey) error {
+
+	if len(pKeys) == 0 {
+		return errors.New("no trusted public key")
+	}
+
+	if len(pSignature) != ed25519.SignatureSize {
+		return errors.New("invalid ed25519 signature")
+	}
+
+	for _, _key := range pKeys {
+		if ed25519.Verify(_key, pData, pSignature) {
+			return nil
+		}
+	}
+
+	return errors.New("ed25519 signature verification failed")
+}
@@ -0,0 +1,302 @@
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   wasmunit - host
+
+	Objective     :   Define the host functions of the "agni" module imported by the WebAssembly units
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+#########################################################################################
+*/
+package wasmunit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	apptypes "agnione/v1/src/appfm/types"
+	lib "agnione/v1/src/lib"
+
+	zutls "agnione.appfm/src/utils"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+const HTTP_TIMEOUT = 30 * time.Second     /// time for the HTTP requests of the units
+const MAX_RESPONSE_SIZE = 4 * 1024 * 1024 /// max body size of the HTTP responses passed to the units
+
+// HTTPRequest holds the request of agni.http_request
+type HTTPRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// HTTPResponse holds the result of agni.http_request
+type HTTPResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// ModuleInfo holds the build info of agni.set_info
+type ModuleInfo struct {
+	Version string `json:"version"`
+	Time    string `json:"time"`
+	User    string `json:"user"`
+}
+
+// unit_key is the context key of the unit calling the host functions
+type unit_key struct{}
+
+var http_client = &http.Client{Timeout: HTTP_TIMEOUT}
+
+// instantiate_host instantiates the "agni" module in the runtime
+func instantiate_host(pCtx context.Context, pRuntime wazero.Runtime) error {
+
+	_, _err := pRuntime.NewHostModuleBuilder(HOST_MODULE).
+		NewFunctionBuilder().WithFunc(host_log).Export("log").
+		NewFunctionBuilder().WithFunc(host_request_handled).Export("request_handled").
+		NewFunctionBuilder().WithFunc(host_request_failed).Export("request_failed").
+		NewFunctionBuilder().WithFunc(host_monitor).Export("monitor").
+		NewFunctionBuilder().WithFunc(host_init_args).Export("init_args").
+		NewFunctionBuilder().WithFunc(host_config).Export("config").
+		NewFunctionBuilder().WithFunc(host_http_request).Export("http_request").
+		NewFunctionBuilder().WithFunc(host_result).Export("result").
+		NewFunctionBuilder().WithFunc(host_set_error).Export("set_error").
+		NewFunctionBuilder().WithFunc(host_set_info).Export("set_info").
+		Instantiate(pCtx)
+
+	return _err
+}
+
+// calling_unit returns the unit of the call. Host functions are called with the lock of the unit
+func calling_unit(pCtx context.Context) *Unit {
+	_unit, _ := pCtx.Value(unit_key{}).(*Unit)
+	return _unit
+}
+
+// read returns a copy of the module memory. nil if out of range
+func read(pModule api.Module, pPtr uint32, pLen uint32) []byte {
+	_data, _ok := pModule.Memory().Read(pPtr, pLen)
+	if !_ok {
+		return nil
+	}
+	return bytes.Clone(_data)
+}
+
+// set_result sets the result of the host call and returns its length
+func (u *Unit) set_result(pData []byte) int32 {
+	u.result = pData
+	return int32(len(pData))
+}
+
+// set_failed sets the error as the result of the host call and returns -1
+func (u *Unit) set_failed(pErr error) int32 {
+	u.result = []byte(pErr.Error())
+	return -1
+}
+
+func host_log(pCtx context.Context, pModule api.Module, pLevel uint32, pPtr uint32, pLen uint32) {
+
+	_unit := calling_unit(pCtx)
+	if _unit == nil {
+		return
+	}
+
+	_level := apptypes.LOG_INFO
+	switch pLevel {
+	case 0:
+		_level = apptypes.LOG_DEBUG
+	case 2:
+		_level = apptypes.LOG_WARN
+	case 3:
+		_level = apptypes.LOG_ERROR
+	}
+	_unit.app.Write2Log(string(read(pModule, pPtr, pLen)), _level)
+}
+
+func host_request_handled(pCtx context.Context) {
+
+	if _unit := calling_unit(pCtx); _unit != nil {
+		_unit.handled.Add(1)
+		if _counters, _ok := _unit.app.(ICounters); _ok {
+			_counters.Add_Request_HandleCount()
+		}
+	}
+}
+
+func host_request_failed(pCtx context.Context) {
+
+	if _unit := calling_unit(pCtx); _unit != nil {
+		_unit.failed.Add(1)
+		if _counters, _ok := _unit.app.(ICounters); _ok {
+			_counters.Add_Request_Failed_Count()
+		}
+	}
+}
+
+func host_monitor(pCtx context.Context, pModule api.Module, pPtr uint32, pLen uint32) {
+
+	if _unit := calling_unit(pCtx); _unit != nil {
+		if _message := read(pModule, pPtr, pLen); len(_message) > 0 {
+			_unit.app.Send_Monitor_Message(_message)
+		}
+	}
+}
+
+func host_init_args(pCtx context.Context) int32 {
+
+	_unit := calling_unit(pCtx)
+	if _unit == nil {
+		return -1
+	}
+	return _unit.set_result(_unit.init_args)
+}
+
+func host_config(pCtx context.Context) int32 {
+
+	_unit := calling_unit(pCtx)
+	if _unit == nil {
+		return -1
+	}
+
+	_args := struct {
+		Config_File string `json:"config_file"`
+	}{}
+	json.Unmarshal(_unit.init_args, &_args)
+
+	_content, _err := zutls.Read_Config_Content(&_args.Config_File)
+	if _err != nil {
+		return _unit.set_failed(_err)
+	}
+	return _unit.set_result(_content)
+}
+
+func host_http_request(pCtx context.Context, pModule api.Module, pPtr uint32, pLen uint32) int32 {
+
+	_unit := calling_unit(pCtx)
+	if _unit == nil {
+		return -1
+	}
+
+	_request := HTTPRequest{}
+	if _err := json.Unmarshal(read(pModule, pPtr, pLen), &_request); _err != nil {
+		return _unit.set_failed(errors.New("invalid request. " + _err.Error()))
+	}
+
+	if !_unit.allowed(_request.URL) {
+		return _unit.set_failed(errors.New(_request.URL + " is not allowed for the unit"))
+	}
+
+	if len(_request.Method) == 0 {
+		_request.Method = http.MethodGet
+	}
+
+	_http, _err := http.NewRequestWithContext(pCtx, _request.Method, _request.URL, strings.NewReader(_request.Body))
+	if _err != nil {
+		return _unit.set_failed(_err)
+	}
+	for _key, _value := range _request.Headers {
+		_http.Header.Set(_key, _value)
+	}
+
+	_resp, _err := http_client.Do(_http)
+	if _err != nil {
+		return _unit.set_failed(_err)
+	}
+	defer _resp.Body.Close()
+
+	_body, _err := io.ReadAll(io.LimitReader(_resp.Body, MAX_RESPONSE_SIZE))
+	if _err != nil {
+		return _unit.set_failed(_err)
+	}
+
+	_response := HTTPResponse{Status: _resp.StatusCode, Headers: make(map[string]string), Body: string(_body)}
+	for _key := range _resp.Header {
+		_response.Headers[_key] = _resp.Header.Get(_key)
+	}
+
+	return _unit.set_result(to_json(_response))
+}
+
+func host_result(pCtx context.Context, pModule api.Module, pPtr uint32, pCap uint32) int32 {
+
+	_unit := calling_unit(pCtx)
+	if _unit == nil {
+		return -1
+	}
+
+	_data := _unit.result
+	if uint32(len(_data)) > pCap {
+		_data = _data[:pCap]
+	}
+	if !pModule.Memory().Write(pPtr, _data) {
+		return -1
+	}
+	return int32(len(_data))
+}
+
+func host_set_error(pCtx context.Context, pModule api.Module, pPtr uint32, pLen uint32) {
+
+	if _unit := calling_unit(pCtx); _unit != nil {
+		_unit.last_error = string(read(pModule, pPtr, pLen))
+	}
+}
+
+func host_set_info(pCtx context.Context, pModule api.Module, pPtr uint32, pLen uint32) {
+
+	_unit := calling_unit(pCtx)
+	if _unit == nil {
+		return
+	}
+
+	_info := ModuleInfo{}
+	if _err := json.Unmarshal(read(pModule, pPtr, pLen), &_info); _err == nil {
+		_unit.info = lib.BuildInfo{Version: _info.Version, Time: _info.Time, User: _info.User, BuildGoVersion: UNIT_TYPE}
+	}
+}
+
+// allowed checks whether the URL starts with an allowed prefix of the unit
+func (u *Unit) allowed(pURL string) bool {
+
+	for _, _prefix := range u.settings.Allow_HTTP {
+		if len(_prefix) > 0 && strings.HasPrefix(pURL, _prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// to_json returns the value as JSON
+func to_json(pValue any) []byte {
+	_data, _ := json.Marshal(pValue)
+	return _data
+}
@@ -0,0 +1,433 @@
+// wasmunit package runs the units compiled to WebAssembly modules in an embedded pure-Go runtime (wazero).
+//
+// Modules are sandboxed: no file system, environment or network other than the host functions of the "agni" module.
+// Modules compiled once are shared by the pool instances of the unit and unloaded with the last instance.
+//
+// Module exports, functions without params returning i32 0 if successful. Unless the error set by agni.set_error:
+//   - memory
+//   - agni_initialize  required. init args are read by agni.init_args
+//   - agni_start       required. should return, the module runs only in the calls of the framework
+//   - agni_stop        optional
+//   - agni_tick        optional. called every tick_interval milliseconds while the unit is started
+//   - agni_deinitialize optional. no return value
+//
+// Host functions of the "agni" module. Data is passed as (ptr, len) of the module memory.
+// Functions returning data set the result & return its length, or -1 with the error as the result.
+// Result is copied to the module memory by agni.result(ptr, cap)
+//   - log(level, ptr, len)            level 0 debug, 1 info, 2 warn, 3 error
+//   - request_handled(), request_failed()
+//   - monitor(ptr, len)               message broadcast via web socket monitoring
+//   - init_args() -> len              {"index":0,"name":"","path":"","config_file":""}
+//   - config() -> len                 content of the unit config file with the secret references resolved
+//   - http_request(ptr, len) -> len   request {"method","url","headers","body"}, result {"status","headers","body"}
+//   - result(ptr, cap) -> len         copies the result, returns the copied length
+//   - set_error(ptr, len)             error of the failed export
+//   - set_info(ptr, len)              build info {"version","time","user"} of the module
+/*
+#########################################################################################
+
+	Author        :   D. Ajith Nilantha de Silva contact@agnione.net | 19/10/2026
+
+	Copyright     :   © 2024 D. Ajith Nilantha de Silva contact@agnione.net
+						Licensed under the Apache License, Version 2.0 (the "License");
+						you may not use this file except in compliance with the License.
+						You may obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+
+						Unless required by applicable law or agreed to in writing, software
+						distributed under the License is distributed on an "AS IS" BASIS,
+						WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+						See the License for the specific language governing permissions and
+						limitations under the License.
+
+	Class/module  :   wasmunit
+
+	Objective     :   Map the IAppUnit lifecycle of the framework onto a WebAssembly module
+
+#########################################################################################
+
+	Author                 	Date        	Action      	Description
+
+-----------------------------------------------------------------------------------------------------------------
+
+	Ajith de Silva		19/10/2026	Created 	Created the initial version
+
+	Ajith de Silva		19/10/2026	Updated 	Read the verified copy of the module file
+
+#########################################################################################
+*/
+package wasmunit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	iappfw "agnione/v1/src/appfm/iappfw"
+	apptypes "agnione/v1/src/appfm/types"
+	lib "agnione/v1/src/lib"
+
+	zutls "agnione.appfm/src/utils"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// UNIT_TYPE define the unit type of the WebAssembly units in app.config
+const UNIT_TYPE = "wasm"
+
+// HOST_MODULE define the module name of the host functions imported by the units
+const HOST_MODULE = "agni"
+
+const CALL_TIMEOUT = 30 * time.Second /// time for an export call. module is closed if the call does not return in time
+const MIN_TICK_INTERVAL = 10          /// milliseconds
+
+// Settings holds the app.config settings of a WebAssembly unit
+type Settings struct {
+	Allow_HTTP    []string /// URL prefixes the module can request. HTTP is disabled if empty
+	Tick_Interval int      /// milliseconds between agni_tick calls. no ticks if 0
+}
+
+// ICounters defines the request counters of the framework updated by the units
+type ICounters interface {
+	Add_Request_HandleCount()
+	Add_Request_Failed_Count()
+}
+
+// compiled holds a compiled module shared by the instances of the unit
+type compiled struct {
+	module wazero.CompiledModule
+	sum    string
+	refs   int
+}
+
+var runtime wazero.Runtime
+var compiled_modules = make(map[string]*compiled)
+var runtime_lock = &sync.Mutex{}
+
+// host_runtime returns the runtime of the units. Created with the host functions on first use
+func host_runtime() (wazero.Runtime, error) {
+
+	if runtime != nil {
+		return runtime, nil
+	}
+
+	_ctx := context.Background()
+	_runtime := wazero.NewRuntimeWithConfig(_ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+
+	/// WASI is given for the language runtimes. without file system, args & environment
+	if _, _err := wasi_snapshot_preview1.Instantiate(_ctx, _runtime); _err != nil {
+		_runtime.Close(_ctx)
+		return nil, _err
+	}
+
+	if _err := instantiate_host(_ctx, _runtime); _err != nil {
+		_runtime.Close(_ctx)
+		return nil, _err
+	}
+
+	runtime = _runtime
+	return runtime, nil
+}
+
+// acquire_module returns the compiled module of the file. File is compiled again if its content is changed
+func acquire_module(pFileName string, pData []byte) (*compiled, error) {
+
+	runtime_lock.Lock()
+	defer runtime_lock.Unlock()
+
+	_runtime, _err := host_runtime()
+	if _err != nil {
+		return nil, _err
+	}
+
+	_hash := sha256.Sum256(pData)
+	_sum := hex.EncodeToString(_hash[:])
+
+	if _module, _found := compiled_modules[pFileName]; _found && _module.sum == _sum {
+		_module.refs++
+		return _module, nil
+	}
+
+	_compiled, _err := _runtime.CompileModule(context.Background(), pData)
+	if _err != nil {
+		return nil, errors.New("failed to compile " + pFileName + ". " + _err.Error())
+	}
+
+	/// instances of the previous content keep their module until released
+	_module := &compiled{module: _compiled, sum: _sum, refs: 1}
+	compiled_modules[pFileName] = _module
+	return _module, nil
+}
+
+// release_module releases the compiled module. Module is unloaded with its last instance
+func release_module(pFileName string, pModule *compiled) {
+
+	runtime_lock.Lock()
+	defer runtime_lock.Unlock()
+
+	pModule.refs--
+	if pModule.refs > 0 {
+		return
+	}
+
+	pModule.module.Close(context.Background())
+	if compiled_modules[pFileName] == pModule {
+		delete(compiled_modules, pFileName)
+	}
+}
+
+// Unit runs a WebAssembly module. It is kept in the unit pool of the framework as the unit instance
+type Unit struct {
+	path     string
+	settings Settings
+	verify   func(*string) (string, error)
+
+	app      iappfw.IAgniApp
+	lock     *sync.Mutex /// serializes the calls into the module
+	compiled *compiled
+	module   api.Module
+
+	init_args  []byte
+	result     []byte /// data of the last host call. accessed in the calls into the module only
+	last_error string
+
+	name      string
+	started   bool
+	stop_tick chan struct{}
+	handled   atomic.Uint64
+	failed    atomic.Uint64
+	info      lib.BuildInfo
+}
+
+// New creates the unit of the given module file.
+// pVerify is called with the module file before it is read and returns the verified file to read. nil to skip
+func New(pPath string, pSettings Settings, pVerify func(*string) (string, error)) *Unit {
+	return &Unit{path: pPath, settings: pSettings, verify: pVerify, lock: &sync.Mutex{},
+		info: lib.BuildInfo{BuildGoVersion: UNIT_TYPE}}
+}
+
+// New returns a new unit of the same module
+func (u *Unit) New() any {
+	return New(u.path, u.settings, u.verify)
+}
+
+// Initialize instantiates the module and calls agni_initialize
+func (u *Unit) Initialize(pApp iappfw.IAgniApp, pIndex int, pName string, pPath string, pConfig_File string) (_ok bool, _err error) {
+
+	u.app = pApp
+	u.name = pName
+	u.init_args = to_json(map[string]any{"index": pIndex, "name": pName, "path": pPath, "config_file": pConfig_File})
+
+	defer func() {
+		zutls.Record_Plugin_Load(u.path, _err)
+	}()
+
+	_file := u.path
+	if u.verify != nil {
+		if _file, _err = u.verify(&u.path); _err != nil {
+			return false, _err
+		}
+	}
+
+	_data, _err := os.ReadFile(_file)
+	if _err != nil {
+		return false, _err
+	}
+
+	if u.compiled, _err = acquire_module(u.path, _data); _err != nil {
+		return false, _err
+	}
+
+	_config := wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize").
+		WithStdout(&log_writer{unit: u, level: apptypes.LOG_INFO}).WithStderr(&log_writer{unit: u, level: apptypes.LOG_ERROR})
+
+	u.module, _err = runtime.InstantiateModule(context.WithValue(context.Background(), unit_key{}, u), u.compiled.module, _config)
+	if _err != nil {
+		release_module(u.path, u.compiled)
+		u.compiled = nil
+		return false, errors.New("failed to instantiate " + u.path + ". " + _err.Error())
+	}
+
+	if _err = u.call("agni_initialize", true); _err != nil {
+		u.close()
+		return false, _err
+	}
+
+	return true, nil
+}
+
+// Start calls agni_start and starts the ticks, if agni_tick is exported
+func (u *Unit) Start() (bool, error) {
+
+	if _err := u.call("agni_start", true); _err != nil {
+		return false, _err
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	u.started = true
+
+	if u.settings.Tick_Interval > 0 && u.module.ExportedFunction("agni_tick") != nil {
+		u.stop_tick = make(chan struct{})
+		go u.tick(time.Duration(max(u.settings.Tick_Interval, MIN_TICK_INTERVAL))*time.Millisecond, u.stop_tick)
+	}
+
+	return true, nil
+}
+
+// Stop stops the ticks and calls agni_stop
+func (u *Unit) Stop() {
+
+	u.lock.Lock()
+	u.started = false
+	if u.stop_tick != nil {
+		close(u.stop_tick)
+		u.stop_tick = nil
+	}
+	u.lock.Unlock()
+
+	if _err := u.call("agni_stop", false); _err != nil {
+		u.app.Write2Log("Wasm unit "+u.name+" "+_err.Error(), apptypes.LOG_WARN)
+	}
+}
+
+// IsStarted checks whether the unit is started and the module is not closed
+func (u *Unit) IsStarted() bool {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	return u.started && u.module != nil && !u.module.IsClosed()
+}
+
+// Info returns the build info set by the module
+func (u *Unit) Info() *lib.BuildInfo {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	_info := u.info
+	return &_info
+}
+
+// Status returns the status of the unit with the request counts of the module
+func (u *Unit) Status() *apptypes.AppUnitInfo {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	_status := &apptypes.AppUnitInfo{Req_Handled: u.handled.Load(), Req_Failed: u.failed.Load()}
+	_status.Info.Name = u.name
+	_status.Info.BuildGoVersion = u.info.BuildGoVersion
+	if u.stop_tick != nil {
+		_status.Routines = 1
+	}
+	return _status
+}
+
+// Deinitialize calls agni_deinitialize and unloads the module instance
+func (u *Unit) Deinitialize() {
+
+	u.lock.Lock()
+	if u.stop_tick != nil {
+		close(u.stop_tick)
+		u.stop_tick = nil
+	}
+	u.started = false
+	u.lock.Unlock()
+
+	if _err := u.call("agni_deinitialize", false); _err != nil {
+		u.app.Write2Log("Wasm unit "+u.name+" "+_err.Error(), apptypes.LOG_WARN)
+	}
+
+	u.close()
+}
+
+// close closes the module instance and releases the compiled module
+func (u *Unit) close() {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if u.module != nil {
+		u.module.Close(context.Background())
+		u.module = nil
+	}
+	if u.compiled != nil {
+		release_module(u.path, u.compiled)
+		u.compiled = nil
+	}
+}
+
+// call calls the export of the module. Missing optional exports are skipped.
+// Returns the error set by the module if the export returns non zero
+func (u *Unit) call(pExport string, pRequired bool) error {
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if u.module == nil || u.module.IsClosed() {
+		return errors.New("module of " + u.name + " is not loaded")
+	}
+
+	_function := u.module.ExportedFunction(pExport)
+	if _function == nil {
+		if pRequired {
+			return errors.New("module " + u.path + " does not export " + pExport)
+		}
+		return nil
+	}
+
+	_ctx, _cancel := context.WithTimeout(context.WithValue(context.Background(), unit_key{}, u), CALL_TIMEOUT)
+	defer _cancel()
+
+	u.last_error = ""
+	_results, _err := _function.Call(_ctx)
+	if _err != nil {
+		return errors.New(pExport + " failed. " + _err.Error())
+	}
+
+	if len(_results) > 0 && api.DecodeI32(_results[0]) != 0 {
+		_message := u.last_error
+		if len(_message) == 0 {
+			_message = "returned " + strconv.Itoa(int(api.DecodeI32(_results[0])))
+		}
+		return errors.New(pExport + " failed. " + _message)
+	}
+	return nil
+}
+
+// tick calls agni_tick at the interval until the unit is stopped
+func (u *Unit) tick(pInterval time.Duration, pStop chan struct{}) {
+
+	_ticker := time.NewTicker(pInterval)
+	defer _ticker.Stop()
+
+	for {
+		select {
+		case <-pStop:
+			return
+		case <-_ticker.C:
+			if _err := u.call("agni_tick", false); _err != nil {
+				u.app.Write2Log("Wasm unit "+u.name+" "+_err.Error(), apptypes.LOG_ERROR)
+			}
+		}
+	}
+}
+
+// log_writer writes the stdout & stderr of the module to the framework log
+type log_writer struct {
+	unit  *Unit
+	level apptypes.LogLevel
+}
+
+func (w *log_writer) Write(pData []byte) (int, error) {
+	if _entry := strings.TrimRight(string(pData), "\r\n"); len(_entry) > 0 {
+		w.unit.app.Write2Log("Wasm unit "+w.unit.name+" "+_entry, w.level)
+	}
+	return len(pData), nil
+}
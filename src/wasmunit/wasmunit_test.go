@@ -0,0 +1,91 @@
+package wasmunit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// empty_module is a valid WebAssembly module without exports
+var empty_module = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+func TestAllowed(t *testing.T) {
+
+	_unit := New("unit.wasm", Settings{Allow_HTTP: []string{"", "https://api.local/v1/", "http://127.0.0.1:8080/"}}, nil)
+
+	_cases := []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://api.local/v1/orders", true},
+		{"http://127.0.0.1:8080/health", true},
+		{"https://api.local/v2/orders", false},
+		{"https://api.local.evil/v1/", false},
+		{"", false},
+	}
+
+	for _, _case := range _cases {
+		if _got := _unit.allowed(_case.url); _got != _case.allowed {
+			t.Errorf("allowed(%q) = %v, want %v", _case.url, _got, _case.allowed)
+		}
+	}
+
+	if New("unit.wasm", Settings{}, nil).allowed("https://api.local/v1/") {
+		t.Fatal("HTTP is allowed for the unit without prefixes")
+	}
+}
+
+func TestTo_JSON(t *testing.T) {
+
+	if _got := string(to_json(map[string]any{"index": 1, "name": "api"})); _got != `{"index":1,"name":"api"}` {
+		t.Fatalf("unexpected JSON %s", _got)
+	}
+	if _got := to_json(func() {}); _got != nil {
+		t.Fatalf("expected nil for the value that can not be marshalled, got %s", _got)
+	}
+}
+
+func TestInitialize_Errors(t *testing.T) {
+
+	_dir := t.TempDir()
+
+	_invalid := filepath.Join(_dir, "invalid.wasm")
+	os.WriteFile(_invalid, []byte("not a module"), 0644)
+
+	_empty := filepath.Join(_dir, "empty.wasm")
+	os.WriteFile(_empty, empty_module, 0644)
+
+	_cases := []struct {
+		name   string
+		path   string
+		verify func(*string) (string, error)
+		error  string
+	}{
+		{name: "not verified", path: _empty, verify: func(*string) (string, error) { return "", errors.New("checksum mismatch") },
+			error: "checksum mismatch"},
+		{name: "missing", path: filepath.Join(_dir, "missing.wasm"),
+			error: "open " + filepath.Join(_dir, "missing.wasm") + ": no such file or directory"},
+		{name: "no initialize", path: _empty, error: "module " + _empty + " does not export agni_initialize"},
+	}
+
+	for _, _case := range _cases {
+		t.Run(_case.name, func(t *testing.T) {
+			_ok, _err := New(_case.path, Settings{}, _case.verify).Initialize(nil, 0, "api", _dir, "")
+			if _ok || _err == nil || _err.Error() != _case.error {
+				t.Fatalf("expected error %q, got %v", _case.error, _err)
+			}
+		})
+	}
+
+	if _, _err := New(_invalid, Settings{}, nil).Initialize(nil, 0, "api", _dir, ""); _err == nil {
+		t.Fatal("expected the compile error of the invalid module")
+	}
+
+	/// compiled module is released with the failed instance
+	runtime_lock.Lock()
+	defer runtime_lock.Unlock()
+	if _, _found := compiled_modules[_empty]; _found {
+		t.Fatal("compiled module is not released")
+	}
+}